@@ -226,7 +226,7 @@ func (a *App) GetDriveInfo() []DriveInfo {
 	a.drivesOnce.Do(func() {
 		a.drives = NewDriveManager(a.platform)
 	})
-	return a.drives.GetDriveInfo()
+	return a.drives.GetDriveInfo(a.ctx)
 }
 
 // Terminal Operations
@@ -251,7 +251,7 @@ func (a *App) GetQuickAccessPaths() []DriveInfo {
 	a.drivesOnce.Do(func() {
 		a.drives = NewDriveManager(a.platform)
 	})
-	return a.drives.GetQuickAccessPaths()
+	return a.drives.GetQuickAccessPaths(a.ctx)
 }
 
 // OpenTerminalHere opens the system's default terminal in the specified directory
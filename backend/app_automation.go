@@ -0,0 +1,27 @@
+package backend
+
+// StartAutomationAPI starts the localhost automation API and remembers the choice
+func (a *App) StartAutomationAPI() (AutomationAPIInfo, error) {
+	info, err := a.automation.Start(a.GetSettings().AutomationAPIPort)
+	if err != nil {
+		return AutomationAPIInfo{}, err
+	}
+	if err := a.updateSettings(func(s *Settings) { s.AutomationAPIEnabled = true }); err != nil {
+		logPrintf("⚠️ Failed to persist automation setting: %v", err)
+	}
+	return info, nil
+}
+
+// StopAutomationAPI stops the automation API and keeps it off on next start
+func (a *App) StopAutomationAPI() bool {
+	stopped := a.automation.Stop()
+	if err := a.updateSettings(func(s *Settings) { s.AutomationAPIEnabled = false }); err != nil {
+		logPrintf("⚠️ Failed to persist automation setting: %v", err)
+	}
+	return stopped
+}
+
+// GetAutomationAPIInfo returns the automation endpoint and token
+func (a *App) GetAutomationAPIInfo() AutomationAPIInfo {
+	return a.automation.Info()
+}
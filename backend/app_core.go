@@ -17,12 +17,22 @@ const fallbackDrivePollInterval = 30 * time.Second
 // NewApp creates a new App application struct - simplified
 func NewApp() *App {
 	platform := NewPlatformManager()
-	return &App{
+	jobs := NewJobManager()
+	fileOps := NewFileOperationsManager(platform)
+	app := &App{
 		filesystem: NewFileSystemManager(platform),
-		fileOps:    NewFileOperationsManager(platform),
+		fileOps:    fileOps,
 		platform:   platform,
+		policy:     fileOps.policy,
+		renamer:    NewBatchRenamer(fileOps.policy),
+		jobs:       jobs,
+		s3:         NewS3Manager(jobs),
+		shares:     NewShareManager(fileOps.policy),
+		network:    NewNetworkManager(),
 		// drives & terminal are expensive; initialize on first use
 	}
+	app.automation = NewAutomationServer(app)
+	return app
 }
 
 // Startup is called when the app starts
@@ -33,13 +43,24 @@ func (a *App) Startup(ctx context.Context) {
 	if fsManager, ok := a.filesystem.(*FileSystemManager); ok {
 		fsManager.SetContext(ctx)
 	}
+	a.jobs.SetContext(ctx)
+	a.s3.SetContext(ctx)
+	a.shares.SetContext(ctx)
 
 	// Start background drive monitoring
 	go a.monitorDrives()
+	go a.network.Run(ctx, a.networkLocations)
+	go a.watchSettings()
 
 	// Begin warm preloading in background
 	go a.warmPreload()
 
+	if settings := a.GetSettings(); settings.AutomationAPIEnabled {
+		if _, err := a.automation.Start(settings.AutomationAPIPort); err != nil {
+			logPrintf("⚠️ Automation API failed to start: %v", err)
+		}
+	}
+
 	// TODO: Add system tray (Windows 11) in future version when Wails v3 stable.
 
 	logPrintln("🚀 Lightning Explorer backend started")
@@ -67,17 +88,9 @@ func (a *App) monitorDrives() {
 
 	updates, err := a.platform.WatchDriveChanges(a.ctx)
 	if err != nil {
-		ticker := time.NewTicker(fallbackDrivePollInterval)
-		defer ticker.Stop()
 		sendUpdate()
-		for {
-			select {
-			case <-a.ctx.Done():
-				return
-			case <-ticker.C:
-				sendUpdate()
-			}
-		}
+		a.pollDrives(sendUpdate)
+		return
 	}
 
 	sendUpdate()
@@ -88,10 +101,37 @@ func (a *App) monitorDrives() {
 			return
 		case _, ok := <-updates:
 			if !ok {
+				if a.ctx.Err() != nil {
+					return
+				}
+				// The watcher stopped; keep the drive list fresh by polling
+				logPrintln("⚠️ Drive watcher stopped; polling for drive changes instead")
+				a.driveMgr().InvalidateCaches()
+				sendUpdate()
+				a.pollDrives(sendUpdate)
 				return
 			}
 			a.driveMgr().InvalidateCaches()
 			sendUpdate()
+		case <-a.network.Changes():
+			sendUpdate()
+		}
+	}
+}
+
+// pollDrives calls sendUpdate every fallbackDrivePollInterval and on network
+// changes until the app shuts down, for when drive changes cannot be watched
+func (a *App) pollDrives(sendUpdate func()) {
+	ticker := time.NewTicker(fallbackDrivePollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			sendUpdate()
+		case <-a.network.Changes():
+			sendUpdate()
 		}
 	}
 }
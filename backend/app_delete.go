@@ -0,0 +1,85 @@
+package backend
+
+import "time"
+
+// permanentDeleteTTL bounds how long a ConfirmPermanentDelete request stays valid
+const permanentDeleteTTL = 5 * time.Minute
+
+// RecycleFiles moves files to the recycle bin/trash and reports exactly what happened.
+// Paths that could not be trashed are never deleted; instead a ConfirmPermanentDelete
+// event is emitted and the returned ConfirmRequestID can be confirmed or cancelled.
+func (a *App) RecycleFiles(filePaths []string) RecycleResult {
+	if !a.checkPolicy(OpRecycle, filePaths...) {
+		return RecycleResult{Trashed: []string{}, Failed: filePaths, Message: "Blocked by the protected-path policy"}
+	}
+	result := a.fileOps.RecycleFiles(filePaths)
+	if result.TrashUnavailable && len(result.Failed) > 0 {
+		if req, ok := a.requestPermanentDelete(result.Failed, result.Message); ok {
+			result.ConfirmRequestID = req.RequestID
+		}
+	}
+	return result
+}
+
+// ConfirmPermanentDelete permanently deletes the paths of a pending request
+// created when trashing failed. Each request can be used once.
+func (a *App) ConfirmPermanentDelete(requestID string) bool {
+	req, ok := a.takePermanentDelete(requestID)
+	if !ok {
+		logPrintf("Unknown or expired permanent delete request: %s", requestID)
+		return false
+	}
+	logPrintf("Permanent delete confirmed for %d items", len(req.Paths))
+	return a.DeleteFiles(req.Paths)
+}
+
+// CancelPermanentDelete discards a pending permanent delete request
+func (a *App) CancelPermanentDelete(requestID string) bool {
+	_, ok := a.takePermanentDelete(requestID)
+	return ok
+}
+
+func (a *App) requestPermanentDelete(paths []string, reason string) (PermanentDeleteRequest, bool) {
+	id, err := randomHex(12)
+	if err != nil {
+		logPrintf("Failed to create permanent delete request: %v", err)
+		return PermanentDeleteRequest{}, false
+	}
+	req := PermanentDeleteRequest{
+		RequestID: id,
+		Paths:     append([]string(nil), paths...),
+		Reason:    reason,
+		ExpiresAt: time.Now().Add(permanentDeleteTTL).Unix(),
+	}
+
+	a.pendingDeletesMu.Lock()
+	if a.pendingDeletes == nil {
+		a.pendingDeletes = make(map[string]PermanentDeleteRequest)
+	}
+	now := time.Now().Unix()
+	for key, pending := range a.pendingDeletes {
+		if pending.ExpiresAt < now {
+			delete(a.pendingDeletes, key)
+		}
+	}
+	a.pendingDeletes[id] = req
+	a.pendingDeletesMu.Unlock()
+
+	NewEventEmitter(a.ctx).EmitConfirmPermanentDelete(req)
+	return req, true
+}
+
+func (a *App) takePermanentDelete(requestID string) (PermanentDeleteRequest, bool) {
+	a.pendingDeletesMu.Lock()
+	defer a.pendingDeletesMu.Unlock()
+
+	req, ok := a.pendingDeletes[requestID]
+	if !ok {
+		return PermanentDeleteRequest{}, false
+	}
+	delete(a.pendingDeletes, requestID)
+	if req.ExpiresAt < time.Now().Unix() {
+		return PermanentDeleteRequest{}, false
+	}
+	return req, true
+}
@@ -5,14 +5,22 @@ import (
 	"runtime"
 )
 
-// GetDriveInfo returns information about system drives
+// GetDriveInfo returns information about system drives, followed by the saved
+// network locations with their connection status
 func (a *App) GetDriveInfo() []DriveInfo {
-	return a.driveMgr().GetDriveInfo()
+	return a.network.mergeDrives(a.driveMgr().GetDriveInfo(a.ctx), a.networkLocations())
 }
 
-// GetQuickAccessPaths returns commonly accessed directories
+// GetQuickAccessPaths returns commonly accessed directories with the pinned
+// folders ahead of the system paths, followed by the saved network locations
 func (a *App) GetQuickAccessPaths() []DriveInfo {
-	return a.driveMgr().GetQuickAccessPaths()
+	paths := mergePinned(a.driveMgr().GetQuickAccessPaths(a.ctx), pinnedEntries(a.ctx, a.pinnedFolders()))
+	return append(paths, a.network.driveEntries(a.networkLocations())...)
+}
+
+// GetVolumeStats returns capacity, free space and type of the filesystem holding path
+func (a *App) GetVolumeStats(path string) (VolumeStats, error) {
+	return GetVolumeStats(a.ctx, path)
 }
 
 // EjectDrive safely ejects a drive using OS-specific methods
@@ -29,14 +37,69 @@ func (a *App) EjectDrive(drivePath string) bool {
 	switch runtime.GOOS {
 	case "windows":
 		return a.platform.EjectDriveWindows(drivePath)
+	case "linux":
+		result := a.EjectVolume(drivePath, true)
+		if !result.Success {
+			log.Printf("❌ EjectDrive: %s", result.Message)
+		}
+		return result.Success
 	default:
 		log.Printf("❌ EjectDrive: unsupported platform %s", runtime.GOOS)
 		return false
 	}
 }
 
+// EjectVolume unmounts the volume mounted at path and, with powerOff, powers
+// off its drive. When the volume is busy the result lists the processes holding it.
+func (a *App) EjectVolume(path string, powerOff bool) EjectResult {
+	var result EjectResult
+	if runtime.GOOS == "windows" {
+		result = EjectResult{Path: path, Method: "windows", Success: a.platform.EjectDriveWindows(path)}
+		if !result.Success {
+			result.Reason = EjectReasonFailed
+		}
+		result.PoweredOff = result.Success
+	} else {
+		result = ejectVolume(path, powerOff)
+	}
+	if result.Success {
+		a.driveMgr().InvalidateCaches()
+	}
+	return result
+}
+
 // ShowDriveProperties shows drive properties using OS-specific methods
 func (a *App) ShowDriveProperties(drivePath string) bool {
 	log.Printf("🔄 ShowDriveProperties called for: %s", drivePath)
 	return a.platform.OpenInSystemExplorer(drivePath)
 }
+
+// MountVolume mounts an unmounted block device, or attaches a .iso or .img disk
+// image and mounts its filesystems. The mount watcher then reports the new
+// volumes through driveListUpdated.
+func (a *App) MountVolume(source string) MountResult {
+	result := mountVolume(source)
+	if result.Success {
+		a.driveMgr().InvalidateCaches()
+	}
+	return result
+}
+
+// ListUnmountedVolumes returns block devices with a filesystem that can be mounted
+func (a *App) ListUnmountedVolumes() ([]BlockVolume, error) {
+	return listUnmountedVolumes()
+}
+
+// GetDriveProperties returns the data for a drive properties dialog
+func (a *App) GetDriveProperties(path string) (DriveProperties, error) {
+	return GetDriveProperties(a.ctx, path)
+}
+
+// SetVolumeLabel renames the volume holding path where the OS and filesystem allow
+func (a *App) SetVolumeLabel(path, label string) error {
+	if err := SetVolumeLabel(a.ctx, path, label); err != nil {
+		return err
+	}
+	a.driveMgr().InvalidateCaches()
+	return nil
+}
@@ -1,5 +1,7 @@
 package backend
 
+import "path/filepath"
+
 // GetFileDetails gets detailed information about a file
 func (a *App) GetFileDetails(filePath string) FileInfo {
 	fileInfo, err := a.filesystem.GetFileInfo(filePath)
@@ -22,37 +24,71 @@ func (a *App) OpenInSystemExplorer(path string) bool {
 
 // CopyFiles copies files to destination directory
 func (a *App) CopyFiles(sourcePaths []string, destDir string) bool {
+	if !a.checkPolicy(OpCopy, transferTargets(sourcePaths, destDir)...) {
+		return false
+	}
 	return a.fileOps.CopyFiles(sourcePaths, destDir)
 }
 
 // MoveFiles moves files to destination directory
 func (a *App) MoveFiles(sourcePaths []string, destDir string) bool {
+	if !a.checkPolicy(OpMove, append(append([]string{}, sourcePaths...), transferTargets(sourcePaths, destDir)...)...) {
+		return false
+	}
 	return a.fileOps.MoveFiles(sourcePaths, destDir)
 }
 
 // DeleteFiles permanently deletes files
 func (a *App) DeleteFiles(filePaths []string) bool {
+	if !a.checkPolicy(OpDelete, filePaths...) {
+		return false
+	}
 	return a.fileOps.DeleteFiles(filePaths)
 }
 
-// MoveFilesToRecycleBin moves files to the system recycle bin/trash
+// MoveFilesToRecycleBin moves files to the system recycle bin/trash.
+// Items that cannot be trashed are left alone and a ConfirmPermanentDelete event is emitted.
 func (a *App) MoveFilesToRecycleBin(filePaths []string) bool {
-	return a.fileOps.MoveFilesToRecycleBin(filePaths)
+	return a.RecycleFiles(filePaths).Success
 }
 
 // RenameFile renames a file or directory
 func (a *App) RenameFile(oldPath, newName string) bool {
+	if !a.checkPolicy(OpRename, oldPath, filepath.Join(filepath.Dir(oldPath), newName)) {
+		return false
+	}
 	return a.fileOps.RenameFile(oldPath, newName)
 }
 
 // HideFiles sets the hidden attribute on the specified files
 func (a *App) HideFiles(filePaths []string) bool {
+	if !a.checkPolicy(OpHide, filePaths...) {
+		return false
+	}
 	return a.fileOps.HideFiles(filePaths)
 }
 
+// UnhideFiles reverses HideFiles: it clears the hidden attribute on Windows and
+// removes the leading dot elsewhere
+func (a *App) UnhideFiles(filePaths []string) bool {
+	if !a.checkPolicy(OpHide, filePaths...) {
+		return false
+	}
+	return a.fileOps.UnhideFiles(filePaths)
+}
+
+// SetAttributes changes mode bits, owner/group, timestamps or Windows attribute
+// flags of paths, descending into directories when recursive is set
+func (a *App) SetAttributes(paths []string, changes AttributeChanges, recursive bool) AttributeResult {
+	if !a.checkPolicy(OpAttrs, a.policy.attributeTargets(paths, recursive)...) {
+		return AttributeResult{Failed: []AttributeFailure{}, Message: "changing attributes of these paths is blocked by the protected-path policy"}
+	}
+	return a.fileOps.SetAttributes(paths, changes, recursive)
+}
+
 // DeletePath deletes a file or directory (alias for compatibility)
 func (a *App) DeletePath(path string) NavigationResponse {
-	success := a.fileOps.DeleteFiles([]string{path})
+	success := a.DeleteFiles([]string{path})
 	if success {
 		return NavigationResponse{
 			Success: true,
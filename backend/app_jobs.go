@@ -0,0 +1,117 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// GetJobs returns all recent background jobs, newest first
+func (a *App) GetJobs() []JobInfo {
+	return a.jobs.List()
+}
+
+// GetJob returns the state of a single background job
+func (a *App) GetJob(id string) JobInfo {
+	info, _ := a.jobs.Get(id)
+	return info
+}
+
+// CancelJob requests cancellation of a running background job
+func (a *App) CancelJob(id string) bool {
+	return a.jobs.Cancel(id)
+}
+
+// StartCopyJob copies files in the background and returns the job ID, or an
+// empty ID when the protected-path policy blocks the operation
+func (a *App) StartCopyJob(sourcePaths []string, destDir string) string {
+	return a.StartCopyJobWithOptions(sourcePaths, destDir, CopyOptions{})
+}
+
+// StartCopyJobWithOptions is StartCopyJob with control over preserved metadata
+func (a *App) StartCopyJobWithOptions(sourcePaths []string, destDir string, opts CopyOptions) string {
+	if !a.checkPolicy(OpCopy, transferTargets(sourcePaths, destDir)...) {
+		return ""
+	}
+	return a.startFileOpJob("copy", "Copying", sourcePaths, destDir, opts, a.fileOps.CopyFilesWithOptions)
+}
+
+// StartMoveJob moves files in the background and returns the job ID, or an
+// empty ID when the protected-path policy blocks the operation
+func (a *App) StartMoveJob(sourcePaths []string, destDir string) string {
+	return a.StartMoveJobWithOptions(sourcePaths, destDir, CopyOptions{})
+}
+
+// StartMoveJobWithOptions is StartMoveJob with control over the metadata kept
+// when a move crosses filesystems
+func (a *App) StartMoveJobWithOptions(sourcePaths []string, destDir string, opts CopyOptions) string {
+	if !a.checkPolicy(OpMove, append(append([]string{}, sourcePaths...), transferTargets(sourcePaths, destDir)...)...) {
+		return ""
+	}
+	return a.startFileOpJob("move", "Moving", sourcePaths, destDir, opts, a.fileOps.MoveFilesWithOptions)
+}
+
+// startFileOpJob runs a copy or move as a job; the result records which copy
+// strategy moved the data ("strategy") and the files per strategy ("strategies").
+// Unless opts.SkipSpaceCheck is set the job first fails when the destination
+// lacks room, recording "requiredBytes" and "availableBytes".
+func (a *App) startFileOpJob(kind, verb string, sourcePaths []string, destDir string, opts CopyOptions, op func(context.Context, []string, string, CopyOptions) bool) string {
+	return a.jobs.Start(kind, func(ctx context.Context, job *Job) error {
+		if !opts.SkipSpaceCheck {
+			job.SetMessage("Checking free space on %s", destDir)
+			if err := checkDestinationSpace(ctx, sourcePaths, destDir, kind == "move"); err != nil {
+				var short *errInsufficientSpace
+				if errors.As(err, &short) {
+					job.SetResult("requiredBytes", short.required)
+					job.SetResult("availableBytes", short.available)
+				}
+				return err
+			}
+		}
+
+		job.SetMessage("Measuring %d items", len(sourcePaths))
+		finish, err := trackTransfer(ctx, job, sourcePaths, &opts)
+		if err != nil {
+			return err
+		}
+		job.SetMessage("%s %d items to %s", verb, len(sourcePaths), destDir)
+		opts.Stats = NewCopyStats()
+		ok := op(ctx, sourcePaths, destDir, opts)
+		opts.Stats.recordIn(job)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%s failed", kind)
+		}
+		finish()
+		return nil
+	})
+}
+
+// trackTransfer sets the job total to the bytes under sourcePaths and has opts
+// report each copied file to the job. finish counts what was moved without
+// copying, such as renamed items, once the operation has succeeded.
+func trackTransfer(ctx context.Context, job *Job, sourcePaths []string, opts *CopyOptions) (finish func(), err error) {
+	var total int64
+	for _, src := range sourcePaths {
+		size, err := transferSize(ctx, src)
+		if err != nil {
+			return nil, err
+		}
+		total += size
+	}
+	job.SetTotal(total)
+
+	var copied atomic.Int64
+	opts.Progress = func(n int64) {
+		copied.Add(n)
+		job.Add(n)
+	}
+	return func() {
+		if rest := total - copied.Load(); rest > 0 {
+			job.Add(rest)
+		}
+	}, nil
+}
@@ -0,0 +1,90 @@
+package backend
+
+import (
+	"os"
+	"os/exec"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// SetLaunchArgs records the command line of the first instance so the frontend
+// can pick it up with GetLaunchRequest once it has loaded
+func (a *App) SetLaunchArgs(args []string, workingDir string) {
+	req, ok := ParseLaunchArgs(args, workingDir)
+	if !ok || req.Path == "" {
+		return
+	}
+	a.launchMu.Lock()
+	a.launchRequest = &req
+	a.launchMu.Unlock()
+}
+
+// GetLaunchRequest returns the navigation requested on the command line, once.
+// An empty Path means the app was started without arguments.
+func (a *App) GetLaunchRequest() NavigateRequest {
+	a.launchMu.Lock()
+	defer a.launchMu.Unlock()
+
+	if a.launchRequest == nil {
+		return NavigateRequest{}
+	}
+	req := *a.launchRequest
+	a.launchRequest = nil
+	return req
+}
+
+// HandleSecondInstance applies the arguments of a second launch to this instance:
+// it brings the window forward and navigates, or spawns a separate window
+func (a *App) HandleSecondInstance(args []string, workingDir string) {
+	req, ok := ParseLaunchArgs(args, workingDir)
+
+	if ok && req.NewWindow {
+		if err := a.spawnWindow(req); err != nil {
+			logPrintf("⚠️ Failed to open new window: %v", err)
+		}
+		return
+	}
+
+	if a.ctx != nil {
+		runtime.WindowUnminimise(a.ctx)
+		runtime.WindowShow(a.ctx)
+	}
+	if ok {
+		a.requestNavigation(req)
+	}
+}
+
+// OpenNewWindow opens path (optionally selecting a file) in a separate window
+func (a *App) OpenNewWindow(path, selectPath string) bool {
+	if err := a.spawnWindow(NavigateRequest{Path: path, Select: selectPath}); err != nil {
+		logPrintf("⚠️ Failed to open new window: %v", err)
+		return false
+	}
+	return true
+}
+
+// spawnWindow starts another process of this executable outside the single-instance lock
+func (a *App) spawnWindow(req NavigateRequest) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	var args []string
+	if req.Select != "" {
+		args = append(args, "--select", req.Select)
+	}
+	if req.Path != "" {
+		args = append(args, req.Path)
+	}
+
+	cmd := exec.Command(exe, args...)
+	cmd.Env = append(os.Environ(), standaloneEnv+"=1")
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+
+	logPrintf("🪟 Opened new window for: %s", req.Path)
+	return nil
+}
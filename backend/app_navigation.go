@@ -1,5 +1,7 @@
 package backend
 
+import "path/filepath"
+
 // GetWarmState returns cached warm-start information to the frontend.
 func (a *App) GetWarmState() WarmState {
 	// Ensure warm preload has started
@@ -29,11 +31,17 @@ func (a *App) GetSystemRoots() []string {
 
 // NavigateToPath navigates to a specified path
 func (a *App) NavigateToPath(path string) NavigationResponse {
+	if err := a.checkNetworkPath(path); err != nil {
+		return NavigationResponse{Success: false, Message: err.Error()}
+	}
 	return a.filesystem.NavigateToPath(path)
 }
 
 // ListDirectory lists contents of a directory
 func (a *App) ListDirectory(path string) NavigationResponse {
+	if err := a.checkNetworkPath(path); err != nil {
+		return NavigationResponse{Success: false, Message: err.Error()}
+	}
 	return a.filesystem.ListDirectory(path)
 }
 
@@ -50,6 +58,22 @@ func (a *App) FileExists(path string) bool {
 
 // StreamDirectory begins directory enumeration in a separate goroutine
 func (a *App) StreamDirectory(dir string) {
+	if isS3Path(dir) {
+		go a.streamS3Directory(dir)
+		return
+	}
+	if isTrashPath(dir) {
+		go a.streamTrash(dir)
+		return
+	}
+	if err := a.checkNetworkPath(dir); err != nil {
+		if a.ctx != nil {
+			emitter := NewEventEmitter(a.ctx)
+			emitter.EmitDirectoryStart(dir)
+			emitter.EmitDirectoryError(err.Error())
+		}
+		return
+	}
 	if fsManager, ok := a.filesystem.(*FileSystemManager); ok {
 		// Launch the potentially-expensive enumeration in its own goroutine
 		go fsManager.StreamDirectory(dir)
@@ -58,5 +82,26 @@ func (a *App) StreamDirectory(dir string) {
 
 // CreateDirectory creates a new directory
 func (a *App) CreateDirectory(path, name string) NavigationResponse {
+	if !a.checkPolicy(OpCreate, filepath.Join(path, name)) {
+		return NavigationResponse{Success: false, Message: "Creating folders here is blocked by the protected-path policy"}
+	}
 	return a.filesystem.CreateDirectory(path, name)
 }
+
+// SearchFiles recursively searches root for entries whose name matches query
+func (a *App) SearchFiles(root, query string, limit int) []FileInfo {
+	results, err := a.filesystem.SearchFiles(root, query, limit)
+	if err != nil {
+		logPrintf("Search failed: %v", err)
+		return []FileInfo{}
+	}
+	return results
+}
+
+// requestNavigation asks the frontend to show a path
+func (a *App) requestNavigation(req NavigateRequest) {
+	if a.ctx == nil {
+		return
+	}
+	NewEventEmitter(a.ctx).EmitNavigateRequested(req)
+}
@@ -0,0 +1,101 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GetNetworkLocations returns the saved network locations with their status
+func (a *App) GetNetworkLocations() []NetworkLocationStatus {
+	locations := a.networkLocations()
+	statuses := make([]NetworkLocationStatus, 0, len(locations))
+	for _, loc := range locations {
+		statuses = append(statuses, a.network.Status(loc))
+	}
+	return statuses
+}
+
+// SaveNetworkLocation adds a location or updates the one with the same ID
+func (a *App) SaveNetworkLocation(loc NetworkLocation) error {
+	loc.ID = strings.TrimSpace(loc.ID)
+	loc.Protocol = strings.ToLower(strings.TrimSpace(loc.Protocol))
+	loc.Host = strings.TrimSpace(loc.Host)
+	if err := loc.validate(); err != nil {
+		return err
+	}
+
+	return a.updateSettings(func(s *Settings) {
+		for i := range s.NetworkLocations {
+			if s.NetworkLocations[i].ID == loc.ID {
+				s.NetworkLocations[i] = loc
+				return
+			}
+		}
+		s.NetworkLocations = append(s.NetworkLocations, loc)
+	})
+}
+
+// RemoveNetworkLocation deletes a saved location; a connected share stays mounted
+func (a *App) RemoveNetworkLocation(id string) error {
+	return a.updateSettings(func(s *Settings) {
+		kept := s.NetworkLocations[:0]
+		for _, loc := range s.NetworkLocations {
+			if loc.ID != id {
+				kept = append(kept, loc)
+			}
+		}
+		s.NetworkLocations = kept
+	})
+}
+
+// ConnectNetworkLocation mounts a saved location. password is used for this
+// connection only and may be empty when the OS has stored credentials.
+func (a *App) ConnectNetworkLocation(id, password string) (NetworkLocationStatus, error) {
+	loc, err := a.networkLocation(id)
+	if err != nil {
+		return NetworkLocationStatus{}, err
+	}
+	status, err := a.network.Connect(loc, password)
+	if err == nil {
+		a.driveMgr().InvalidateCaches()
+	}
+	return status, err
+}
+
+// DisconnectNetworkLocation unmounts a saved location
+func (a *App) DisconnectNetworkLocation(id string) error {
+	loc, err := a.networkLocation(id)
+	if err != nil {
+		return err
+	}
+	if err := a.network.Disconnect(loc); err != nil {
+		return err
+	}
+	a.driveMgr().InvalidateCaches()
+	return nil
+}
+
+func (a *App) networkLocations() []NetworkLocation {
+	a.GetSettings()
+	a.settingsMu.Lock()
+	defer a.settingsMu.Unlock()
+	return append([]NetworkLocation{}, a.settings.NetworkLocations...)
+}
+
+func (a *App) networkLocation(id string) (NetworkLocation, error) {
+	for _, loc := range a.networkLocations() {
+		if loc.ID == id {
+			return loc, nil
+		}
+	}
+	return NetworkLocation{}, fmt.Errorf("unknown network location: %s", id)
+}
+
+// checkNetworkPath fails fast for paths on an offline network location, whose
+// dead mount would otherwise block the listing
+func (a *App) checkNetworkPath(path string) error {
+	if status, ok := a.network.offlineLocation(a.networkLocations(), path); ok {
+		return fmt.Errorf("network location %s is offline", status.Name)
+	}
+	return nil
+}
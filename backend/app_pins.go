@@ -0,0 +1,192 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxPinTextLength = 128
+
+var (
+	pinIconPattern  = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+	pinColorPattern = regexp.MustCompile(`^(#[0-9A-Fa-f]{3}|#[0-9A-Fa-f]{6}|[A-Za-z]{1,32})$`)
+)
+
+// GetPinnedFolders returns the pinned folders in sidebar order, flagging those
+// whose path has gone missing
+func (a *App) GetPinnedFolders() []PinnedFolder {
+	pins := a.pinnedFolders()
+	for i := range pins {
+		path := pins[i].Path
+		info, err := withPathDeadline(a.ctx, path, func() (os.FileInfo, error) { return os.Stat(path) })
+		// A path that timed out is unreachable, not missing
+		pins[i].Missing = errors.Is(err, fs.ErrNotExist) || (err == nil && !info.IsDir())
+	}
+	return pins
+}
+
+// PinFolder pins a folder at the end of the list, or updates the details of a
+// folder that is already pinned; empty details leave those of an existing pin
+// as they are. New pins must point to an existing folder.
+func (a *App) PinFolder(path string, details PinDetails) error {
+	path, err := cleanPinPath(path)
+	if err != nil {
+		return err
+	}
+	if err := details.validate(); err != nil {
+		return err
+	}
+
+	if pinIndex(a.GetSettings().PinnedFolders, path) < 0 {
+		info, err := withPathDeadline(a.ctx, path, func() (os.FileInfo, error) { return os.Stat(path) })
+		if err != nil {
+			return fmt.Errorf("cannot pin %s: %w", path, err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("cannot pin %s: not a folder", path)
+		}
+	}
+
+	err = a.updateSettings(func(s *Settings) {
+		if pinIndex(s.PinnedFolders, path) < 0 {
+			s.PinnedFolders = append(s.PinnedFolders, path)
+		} else if details == (PinDetails{}) {
+			return
+		}
+		setPinDetails(s, path, details)
+	})
+	if err != nil {
+		return err
+	}
+	a.emitPinsUpdated()
+	return nil
+}
+
+// UnpinFolder removes a folder and its details from the pinned folders
+func (a *App) UnpinFolder(path string) error {
+	path = filepath.Clean(path)
+	err := a.updateSettings(func(s *Settings) {
+		if i := pinIndex(s.PinnedFolders, path); i >= 0 {
+			s.PinnedFolders = slices.Delete(slices.Clone(s.PinnedFolders), i, i+1)
+		}
+		setPinDetails(s, path, PinDetails{})
+	})
+	if err != nil {
+		return err
+	}
+	a.emitPinsUpdated()
+	return nil
+}
+
+// ReorderPins sets the order of the pinned folders. paths must list every
+// pinned folder exactly once.
+func (a *App) ReorderPins(paths []string) error {
+	var orderErr error
+	err := a.updateSettings(func(s *Settings) {
+		if len(paths) != len(s.PinnedFolders) {
+			orderErr = fmt.Errorf("expected %d pinned folders, got %d", len(s.PinnedFolders), len(paths))
+			return
+		}
+		ordered := make([]string, 0, len(paths))
+		used := make([]bool, len(s.PinnedFolders))
+		for _, path := range paths {
+			i := pinIndex(s.PinnedFolders, filepath.Clean(path))
+			if i < 0 || used[i] {
+				orderErr = fmt.Errorf("%s is not pinned or is listed twice", path)
+				return
+			}
+			used[i] = true
+			ordered = append(ordered, s.PinnedFolders[i])
+		}
+		s.PinnedFolders = ordered
+	})
+	if orderErr != nil {
+		return orderErr
+	}
+	if err != nil {
+		return err
+	}
+	a.emitPinsUpdated()
+	return nil
+}
+
+// emitPinsUpdated tells every window the new pins so their sidebars stay in sync
+func (a *App) emitPinsUpdated() {
+	NewEventEmitter(a.ctx).EmitPinsUpdated(a.GetPinnedFolders())
+}
+
+// pinnedFolders returns the pins with their details, without checking their
+// paths, as a copy safe to use without the settings lock
+func (a *App) pinnedFolders() []PinnedFolder {
+	a.GetSettings()
+	a.settingsMu.Lock()
+	defer a.settingsMu.Unlock()
+	pins := make([]PinnedFolder, 0, len(a.settings.PinnedFolders))
+	for _, path := range a.settings.PinnedFolders {
+		pins = append(pins, PinnedFolder{Path: path, PinDetails: a.settings.PinDetails[path]})
+	}
+	return pins
+}
+
+func cleanPinPath(path string) (string, error) {
+	path = strings.TrimSpace(path)
+	if path == "" || !filepath.IsAbs(path) {
+		return "", fmt.Errorf("pinned folders need an absolute path: %q", path)
+	}
+	return filepath.Clean(path), nil
+}
+
+// pinIndex finds path in pins, which may hold uncleaned paths saved by older versions
+func pinIndex(pins []string, path string) int {
+	for i, pin := range pins {
+		if filepath.Clean(pin) == path {
+			return i
+		}
+	}
+	return -1
+}
+
+// setPinDetails stores details under the pin's saved path, dropping empty
+// details and those of paths that are no longer pinned
+func setPinDetails(s *Settings, path string, details PinDetails) {
+	if i := pinIndex(s.PinnedFolders, path); i >= 0 {
+		path = s.PinnedFolders[i]
+		if details == (PinDetails{}) {
+			delete(s.PinDetails, path)
+		} else {
+			if s.PinDetails == nil {
+				s.PinDetails = make(map[string]PinDetails)
+			}
+			s.PinDetails[path] = details
+		}
+	}
+	for saved := range s.PinDetails {
+		if pinIndex(s.PinnedFolders, filepath.Clean(saved)) < 0 {
+			delete(s.PinDetails, saved)
+		}
+	}
+}
+
+func (d *PinDetails) validate() error {
+	d.Name = strings.TrimSpace(d.Name)
+	d.Group = strings.TrimSpace(d.Group)
+	for _, text := range []string{d.Name, d.Group} {
+		if utf8.RuneCountInString(text) > maxPinTextLength || strings.ContainsAny(text, "\r\n\t") {
+			return fmt.Errorf("invalid pin name or group: %q", text)
+		}
+	}
+	if d.Icon != "" && !pinIconPattern.MatchString(d.Icon) {
+		return fmt.Errorf("invalid pin icon: %q", d.Icon)
+	}
+	if d.Color != "" && !pinColorPattern.MatchString(d.Color) {
+		return fmt.Errorf("invalid pin color: %q", d.Color)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package backend
+
+// GetProtectedPathRules returns the built-in and user-configured protected path rules
+func (a *App) GetProtectedPathRules() []ProtectedPathRule {
+	return a.policy.Rules()
+}
+
+// SaveProtectedPaths replaces the user-configured protected path rules
+func (a *App) SaveProtectedPaths(rules []ProtectedPathRule) error {
+	return a.updateSettings(func(s *Settings) {
+		s.ProtectedPaths = rules
+	})
+}
+
+// CheckPathPolicy reports what the policy would decide for op ("delete", "move", ...) on paths
+func (a *App) CheckPathPolicy(op string, paths []string) PolicyDecision {
+	return a.policy.Evaluate(op, paths...)
+}
+
+// ApproveProtectedPaths approves a pending ConfirmProtectedPath request. The
+// operation is then allowed on those paths for a short while and should be retried.
+func (a *App) ApproveProtectedPaths(requestID string) bool {
+	return a.policy.Approve(requestID)
+}
+
+// checkPolicy consults the protected-path policy before a mutating operation,
+// emitting ConfirmProtectedPath or ProtectedPathDenied when it is not allowed
+func (a *App) checkPolicy(op string, paths ...string) bool {
+	if a.policy == nil {
+		return true
+	}
+
+	decision := a.policy.Evaluate(op, paths...)
+	switch decision.Action {
+	case PolicyAllow:
+		return true
+	case PolicyConfirm:
+		id, err := a.policy.RequestApproval(decision)
+		if err != nil {
+			logPrintf("Failed to request approval: %v", err)
+			return false
+		}
+		NewEventEmitter(a.ctx).EmitConfirmProtectedPath(PolicyConfirmRequest{RequestID: id, Decision: decision})
+	default:
+		NewEventEmitter(a.ctx).EmitProtectedPathDenied(decision)
+	}
+	logPrintf("⛔ %s", decision.Reason)
+	return false
+}
@@ -0,0 +1,30 @@
+package backend
+
+import "path/filepath"
+
+// PreviewBatchRename shows what ApplyBatchRename would do, flagging collisions
+// and invalid names, without touching the filesystem
+func (a *App) PreviewBatchRename(paths []string, rules []RenameRule) BatchRenamePreview {
+	return a.renamer.Preview(paths, rules)
+}
+
+// ApplyBatchRename renames paths according to rules. Nothing is renamed unless
+// the whole preview is valid; the returned UndoID reverts the batch.
+func (a *App) ApplyBatchRename(paths []string, rules []RenameRule) BatchRenameResult {
+	preview := a.renamer.Preview(paths, rules)
+	touched := make([]string, 0, len(preview.Items)*2)
+	for _, item := range preview.Items {
+		if item.Changed {
+			touched = append(touched, item.Path, filepath.Join(filepath.Dir(item.Path), item.NewName))
+		}
+	}
+	if !a.checkPolicy(OpRename, touched...) {
+		return BatchRenameResult{Items: preview.Items, Message: "renaming these paths is blocked by the protected-path policy"}
+	}
+	return a.renamer.Apply(paths, rules)
+}
+
+// UndoBatchRename reverts a batch rename; an empty undoID reverts the most recent one
+func (a *App) UndoBatchRename(undoID string) BatchRenameResult {
+	return a.renamer.Undo(undoID)
+}
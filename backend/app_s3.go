@@ -0,0 +1,100 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GetS3Connections returns the configured S3-compatible connections
+func (a *App) GetS3Connections() []S3Connection {
+	return a.GetSettings().S3Connections
+}
+
+// SaveS3Connection adds a connection or updates the one with the same ID
+func (a *App) SaveS3Connection(conn S3Connection) error {
+	conn.ID = strings.TrimSpace(conn.ID)
+	if conn.ID == "" || strings.Contains(conn.ID, "/") {
+		return fmt.Errorf("invalid connection id: %q", conn.ID)
+	}
+	if _, err := newS3Client(conn); err != nil {
+		return err
+	}
+
+	return a.updateSettings(func(s *Settings) {
+		for i := range s.S3Connections {
+			if s.S3Connections[i].ID == conn.ID {
+				s.S3Connections[i] = conn
+				return
+			}
+		}
+		s.S3Connections = append(s.S3Connections, conn)
+	})
+}
+
+// RemoveS3Connection deletes a configured connection
+func (a *App) RemoveS3Connection(id string) error {
+	return a.updateSettings(func(s *Settings) {
+		kept := s.S3Connections[:0]
+		for _, c := range s.S3Connections {
+			if c.ID != id {
+				kept = append(kept, c)
+			}
+		}
+		s.S3Connections = kept
+	})
+}
+
+// UploadToS3 copies local files into an s3:// folder and returns the job ID
+func (a *App) UploadToS3(sourcePaths []string, destDir string) (string, error) {
+	conn, err := a.s3ConnectionFor(destDir)
+	if err != nil {
+		return "", err
+	}
+	return a.s3.Upload(conn, sourcePaths, destDir)
+}
+
+// DownloadFromS3 copies objects or prefixes into a local folder and returns the job ID
+func (a *App) DownloadFromS3(sourcePaths []string, destDir string) (string, error) {
+	if len(sourcePaths) == 0 {
+		return "", fmt.Errorf("no source paths provided")
+	}
+	conn, err := a.s3ConnectionFor(sourcePaths[0])
+	if err != nil {
+		return "", err
+	}
+	for _, src := range sourcePaths[1:] {
+		if id, _, _, err := parseS3Path(src); err != nil || id != conn.ID {
+			return "", fmt.Errorf("all sources must belong to connection %s", conn.ID)
+		}
+	}
+	if !a.checkPolicy(OpCopy, transferTargets(sourcePaths, destDir)...) {
+		return "", fmt.Errorf("download to %s was not allowed by the protected path policy", destDir)
+	}
+	return a.s3.Download(conn, sourcePaths, destDir)
+}
+
+func (a *App) streamS3Directory(dir string) {
+	conn, err := a.s3ConnectionFor(dir)
+	if err != nil {
+		if a.s3.eventEmitter != nil {
+			a.s3.eventEmitter.EmitDirectoryStart(dir)
+			a.s3.eventEmitter.EmitDirectoryError(err.Error())
+		}
+		return
+	}
+	a.s3.StreamDirectory(conn, dir)
+}
+
+// s3ConnectionFor resolves the connection named by an s3:// path
+func (a *App) s3ConnectionFor(p string) (S3Connection, error) {
+	id, _, _, err := parseS3Path(p)
+	if err != nil {
+		return S3Connection{}, err
+	}
+	for _, c := range a.GetSettings().S3Connections {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return S3Connection{}, fmt.Errorf("unknown s3 connection: %s", id)
+}
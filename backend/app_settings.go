@@ -3,69 +3,227 @@ package backend
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"time"
 )
 
+// Other windows may save the settings; check for their changes this often
+const settingsPollInterval = 2 * time.Second
+
+// settingsStamp identifies one version of the settings file
+type settingsStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func (s settingsStamp) matches(info os.FileInfo) bool {
+	return s.modTime.Equal(info.ModTime()) && s.size == info.Size()
+}
+
+// GetSettings returns a copy of the settings; bindings may run concurrently,
+// so callers never share slices or maps with the live settings
 func (a *App) GetSettings() Settings {
 	a.settingsOnce.Do(func() {
 		a.loadSettings()
 	})
-	return a.settings
+	a.settingsMu.Lock()
+	defer a.settingsMu.Unlock()
+	return a.settings.clone()
 }
 
+// SaveSettings replaces the general settings. Pins, S3 connections, network
+// locations, protected paths and the automation API have their own bindings
+// and are kept as they are, so saving a stale copy of the settings cannot undo
+// their changes.
 func (a *App) SaveSettings(newSettings Settings) error {
-	a.settings = newSettings
-	if fs, ok := a.filesystem.(*FileSystemManager); ok {
-		fs.SetShowHidden(newSettings.ShowHiddenFiles)
+	newSettings = newSettings.clone()
+	return a.updateSettings(func(s *Settings) {
+		saved := *s
+		*s = newSettings
+		s.PinnedFolders = saved.PinnedFolders
+		s.PinDetails = saved.PinDetails
+		s.S3Connections = saved.S3Connections
+		s.NetworkLocations = saved.NetworkLocations
+		s.ProtectedPaths = saved.ProtectedPaths
+		s.AutomationAPIEnabled = saved.AutomationAPIEnabled
+		s.AutomationAPIPort = saved.AutomationAPIPort
+	})
+}
+
+// clone copies s so that its slices and maps can be changed independently
+func (s Settings) clone() Settings {
+	s.PinnedFolders = slices.Clone(s.PinnedFolders)
+	s.PinDetails = maps.Clone(s.PinDetails)
+	s.S3Connections = slices.Clone(s.S3Connections)
+	s.NetworkLocations = slices.Clone(s.NetworkLocations)
+	s.ProtectedPaths = slices.Clone(s.ProtectedPaths)
+	return s
+}
+
+// updateSettings applies fn to the saved settings and persists the result.
+// Other windows write the same file, so the file is re-read under a lock
+// shared between processes; the change is kept only once it has been written.
+func (a *App) updateSettings(fn func(s *Settings)) error {
+	a.GetSettings()
+	a.settingsMu.Lock()
+	defer a.settingsMu.Unlock()
+
+	settingsPath := a.getSettingsPath()
+	if err := os.MkdirAll(filepath.Dir(settingsPath), 0755); err != nil {
+		return fmt.Errorf("failed to create settings directory: %w", err)
 	}
-	return a.saveSettingsToFile()
+	unlock, err := lockSettingsFile(settingsPath)
+	if err != nil {
+		return fmt.Errorf("failed to lock settings file: %w", err)
+	}
+	defer unlock()
+
+	updated := a.settings.clone()
+	if saved, err := readSettingsFile(settingsPath); err == nil {
+		updated = saved
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		logPrintln("⚠️ Failed to re-read settings file, saving over it:", err)
+	}
+	fn(&updated)
+
+	if err := writeSettingsFile(settingsPath, updated); err != nil {
+		return err
+	}
+	info, _ := os.Stat(settingsPath)
+	a.adoptSettings(updated, info)
+	logPrintln("💾 Settings saved to:", settingsPath)
+	return nil
 }
 
 func (a *App) loadSettings() {
-	a.settings = Settings{
-		BackgroundStartup: true,
-		Theme:             "system",
-		ShowHiddenFiles:   false,
+	settingsPath := a.getSettingsPath()
+	info, _ := os.Stat(settingsPath)
+	settings, err := readSettingsFile(settingsPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		logPrintln("⚠️ Failed to parse settings file, using defaults:", err)
 	}
+	a.adoptSettings(settings, info)
+}
 
-	settingsPath := a.getSettingsPath()
-	if data, err := os.ReadFile(settingsPath); err == nil {
-		if err := json.Unmarshal(data, &a.settings); err != nil {
-			logPrintln("⚠️ Failed to parse settings file, using defaults:", err)
-		}
-		if a.settings.PinnedFolders == nil {
-			a.settings.PinnedFolders = []string{}
-		}
+// adoptSettings makes settings the live settings; info describes the file they
+// were read from or written to. The caller holds settingsMu, except on first load.
+func (a *App) adoptSettings(settings Settings, info os.FileInfo) {
+	a.settings = settings
+	a.settingsStamp = settingsStamp{}
+	if info != nil {
+		a.settingsStamp = settingsStamp{modTime: info.ModTime(), size: info.Size()}
 	}
 
 	if fs, ok := a.filesystem.(*FileSystemManager); ok {
-		fs.SetShowHidden(a.settings.ShowHiddenFiles)
+		fs.SetShowHidden(settings.ShowHiddenFiles)
+	}
+	if a.policy != nil {
+		a.policy.SetUserRules(settings.ProtectedPaths)
+	}
+}
+
+// watchSettings reloads the settings when another window saves them and passes
+// changed pins on to this window's sidebar
+func (a *App) watchSettings() {
+	ticker := time.NewTicker(settingsPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			if a.reloadChangedSettings() {
+				a.emitPinsUpdated()
+			}
+		}
 	}
 }
 
-func (a *App) saveSettingsToFile() error {
+// reloadChangedSettings re-reads the settings file if it changed since it was
+// last read or written, reporting whether the pins changed
+func (a *App) reloadChangedSettings() (pinsChanged bool) {
+	a.GetSettings()
+	a.settingsMu.Lock()
+	defer a.settingsMu.Unlock()
+
 	settingsPath := a.getSettingsPath()
+	info, err := os.Stat(settingsPath)
+	if err != nil || a.settingsStamp.matches(info) {
+		return false
+	}
+	settings, err := readSettingsFile(settingsPath)
+	if err != nil {
+		return false
+	}
+	pinsChanged = !slices.Equal(settings.PinnedFolders, a.settings.PinnedFolders) ||
+		!maps.Equal(settings.PinDetails, a.settings.PinDetails)
+	a.adoptSettings(settings, info)
+	return pinsChanged
+}
 
-	if err := os.MkdirAll(filepath.Dir(settingsPath), 0755); err != nil {
-		return fmt.Errorf("failed to create settings directory: %w", err)
+// readSettingsFile reads the settings saved at path over the defaults. On an
+// error the defaults are returned; a missing file reports fs.ErrNotExist.
+func readSettingsFile(path string) (Settings, error) {
+	settings := Settings{
+		BackgroundStartup: true,
+		Theme:             "system",
+		ShowHiddenFiles:   false,
 	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return settings, err
+	}
+	loaded := settings
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return settings, fmt.Errorf("%s: %w", path, err)
+	}
+	if loaded.PinnedFolders == nil {
+		loaded.PinnedFolders = []string{}
+	}
+	return loaded, nil
+}
 
-	data, err := json.MarshalIndent(a.settings, "", "  ")
+// writeSettingsFile replaces the settings at path in one step, so that other
+// windows reading it never see a partly written file
+func writeSettingsFile(path string, settings Settings) error {
+	data, err := json.MarshalIndent(settings, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal settings: %w", err)
 	}
 
-	if err := os.WriteFile(settingsPath, data, 0644); err != nil {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".settings-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write settings file: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
 		return fmt.Errorf("failed to write settings file: %w", err)
 	}
-
-	logPrintln("💾 Settings saved to:", settingsPath)
 	return nil
 }
 
 func (a *App) getSettingsPath() string {
+	return settingsFilePath()
+}
+
+// settingsFilePath is where the app keeps its settings, shared with the CLI
+func settingsFilePath() string {
 	configDir, err := os.UserConfigDir()
 	if err != nil {
 		homeDir, _ := os.UserHomeDir()
@@ -0,0 +1,104 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func useTestSettingsDir(t *testing.T) {
+	t.Helper()
+	config := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", config)
+	t.Setenv("HOME", config)
+}
+
+func TestSettingsFromOtherWindowsAreKept(t *testing.T) {
+	useTestSettingsDir(t)
+	first, second := &App{}, &App{}
+	// Both windows load the settings before either saves
+	first.GetSettings()
+	second.GetSettings()
+
+	dirs := []string{t.TempDir(), t.TempDir()}
+	if err := first.PinFolder(dirs[0], PinDetails{Name: "First"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.PinFolder(dirs[1], PinDetails{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.SaveSettings(Settings{Theme: "dark"}); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := readSettingsFile(settingsFilePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(saved.PinnedFolders, dirs) {
+		t.Errorf("saved pins = %q, want %q", saved.PinnedFolders, dirs)
+	}
+	if saved.PinDetails[dirs[0]].Name != "First" || saved.Theme != "dark" {
+		t.Errorf("saved settings lost a change: %+v", saved)
+	}
+
+	if !first.reloadChangedSettings() {
+		t.Error("the first window did not notice the second window's pin")
+	}
+	if got := first.GetSettings(); !slices.Equal(got.PinnedFolders, dirs) || got.Theme != "dark" {
+		t.Errorf("first window settings = %+v after reload", got)
+	}
+	if first.reloadChangedSettings() {
+		t.Error("an unchanged settings file was reported as changed")
+	}
+}
+
+func TestPinFolderKeepsDetailsWithoutNewOnes(t *testing.T) {
+	useTestSettingsDir(t)
+	a := &App{}
+	dir := t.TempDir()
+	if err := a.PinFolder(dir, PinDetails{Name: "Work", Color: "red"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.PinFolder(dir, PinDetails{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := a.GetSettings().PinDetails[dir]; got.Name != "Work" || got.Color != "red" {
+		t.Errorf("details after re-pinning = %+v", got)
+	}
+}
+
+func TestFailedSettingsWriteKeepsSettings(t *testing.T) {
+	useTestSettingsDir(t)
+	a := &App{}
+	a.GetSettings()
+	// A directory in place of the settings file makes the write fail
+	if err := os.MkdirAll(filepath.Join(settingsFilePath(), "blocked"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.SaveSettings(Settings{Theme: "dark"}); err == nil {
+		t.Fatal("SaveSettings succeeded without a settings file")
+	}
+	if got := a.GetSettings().Theme; got == "dark" {
+		t.Error("settings changed although they were not saved")
+	}
+}
+
+func TestSaveSettingsKeepsAutomationAPI(t *testing.T) {
+	useTestSettingsDir(t)
+	a := &App{}
+	err := a.updateSettings(func(s *Settings) {
+		s.AutomationAPIEnabled = true
+		s.AutomationAPIPort = 50123
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.SaveSettings(Settings{Theme: "dark"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := a.GetSettings(); !got.AutomationAPIEnabled || got.AutomationAPIPort != 50123 {
+		t.Errorf("automation settings after SaveSettings = %v, %d", got.AutomationAPIEnabled, got.AutomationAPIPort)
+	}
+}
@@ -0,0 +1,17 @@
+package backend
+
+// StartShare serves a directory over HTTP. Only this machine can reach it
+// unless opts.ExposeOnLAN is set.
+func (a *App) StartShare(path string, opts ShareOptions) (ShareInfo, error) {
+	return a.shares.Start(path, opts)
+}
+
+// StopShare stops a running share
+func (a *App) StopShare(id string) bool {
+	return a.shares.Stop(id)
+}
+
+// ListShares returns all running shares
+func (a *App) ListShares() []ShareInfo {
+	return a.shares.List()
+}
@@ -0,0 +1,64 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+)
+
+// ShredFiles securely destroys files and folders as a cancellable job: contents
+// are overwritten for the given number of passes, synced, truncated, renamed and
+// unlinked. Paths inside top-level system directories are refused.
+//
+// Overwriting cannot guarantee erasure on SSDs or copy-on-write filesystems,
+// which may keep old blocks around.
+func (a *App) ShredFiles(paths []string, passes int) (string, error) {
+	if len(paths) == 0 {
+		return "", fmt.Errorf("no files to shred")
+	}
+	if passes <= 0 {
+		passes = 1
+	}
+	if passes > maxShredPasses {
+		return "", fmt.Errorf("at most %d passes are supported", maxShredPasses)
+	}
+
+	roots := a.platform.GetSystemRoots()
+	targets := make([]string, 0, len(paths))
+	for _, p := range paths {
+		target, err := validateShredPath(p, roots)
+		if err != nil {
+			return "", err
+		}
+		targets = append(targets, target)
+	}
+	if !a.checkPolicy(OpShred, targets...) {
+		return "", fmt.Errorf("shredding these paths is blocked by the protected-path policy")
+	}
+
+	return a.jobs.Start("shred", func(ctx context.Context, job *Job) error {
+		var total int64
+		for _, target := range targets {
+			size, err := shredSize(target)
+			if err != nil {
+				return err
+			}
+			total += size
+		}
+		job.SetTotal(total * int64(passes))
+
+		s, err := newShredder(ctx, job, passes)
+		if err != nil {
+			return err
+		}
+		for i, target := range targets {
+			job.SetMessage("Shredding %s (%d/%d)", filepath.Base(target), i+1, len(targets))
+			if err := s.shredPath(target); err != nil {
+				return fmt.Errorf("shred %s: %w", target, err)
+			}
+		}
+		job.SetResult("items", len(targets))
+		job.SetResult("passes", passes)
+		return nil
+	}), nil
+}
@@ -0,0 +1,165 @@
+package backend
+
+import (
+	"path/filepath"
+	"time"
+)
+
+// ListTrash returns the items currently in the trash, newest first
+func (a *App) ListTrash() []TrashItem {
+	items, err := a.fileOps.ListTrash()
+	if err != nil {
+		logPrintf("Failed to list trash: %v", err)
+		return []TrashItem{}
+	}
+	return items
+}
+
+// RestoreFromTrash moves the given trash items (IDs from ListTrash) back to where they were deleted from
+func (a *App) RestoreFromTrash(ids []string) bool {
+	if !a.checkPolicy(OpMove, a.trashRestoreTargets(ids, "")...) {
+		return false
+	}
+	if err := a.fileOps.RestoreFromTrash(ids); err != nil {
+		logPrintf("Restore from trash failed: %v", err)
+		return false
+	}
+	return true
+}
+
+// RestoreFromTrashTo moves the given trash items into destDir instead of their original location
+func (a *App) RestoreFromTrashTo(ids []string, destDir string) bool {
+	if !a.checkPolicy(OpMove, a.trashRestoreTargets(ids, destDir)...) {
+		return false
+	}
+	if err := a.fileOps.RestoreFromTrashTo(ids, destDir); err != nil {
+		logPrintf("Restore from trash failed: %v", err)
+		return false
+	}
+	return true
+}
+
+// DeleteFromTrash permanently deletes the given trash items. Policy applies to
+// where the items were deleted from, not to their place inside the trash.
+func (a *App) DeleteFromTrash(ids []string) bool {
+	if !a.checkPolicy(OpDelete, a.trashRestoreTargets(ids, "")...) {
+		return false
+	}
+	if err := a.fileOps.DeleteFromTrash(ids); err != nil {
+		logPrintf("Deleting from trash failed: %v", err)
+		return false
+	}
+	return true
+}
+
+// EmptyTrash permanently deletes everything in the trash
+func (a *App) EmptyTrash() bool {
+	items, err := a.fileOps.ListTrash()
+	if err != nil {
+		logPrintf("Emptying trash failed: %v", err)
+		return false
+	}
+	originals := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.OriginalPath != "" {
+			originals = append(originals, item.OriginalPath)
+		}
+	}
+	if !a.checkPolicy(OpDelete, originals...) {
+		return false
+	}
+	if err := a.fileOps.EmptyTrash(); err != nil {
+		logPrintf("Emptying trash failed: %v", err)
+		return false
+	}
+	return true
+}
+
+// trashRestoreTargets returns where restoring ids would put them: their
+// original paths, or destDir under their names. With no destDir these are the
+// paths policy protects the items by. IDs that are no longer in the
+// trash are left out; restoring them fails anyway.
+func (a *App) trashRestoreTargets(ids []string, destDir string) []string {
+	items, err := a.fileOps.ListTrash()
+	if err != nil {
+		return nil
+	}
+	byID := make(map[string]TrashItem, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+	targets := make([]string, 0, len(ids))
+	for _, id := range ids {
+		item, ok := byID[id]
+		switch {
+		case !ok:
+		case destDir != "":
+			targets = append(targets, filepath.Join(destDir, item.Name))
+		case item.OriginalPath != "":
+			targets = append(targets, item.OriginalPath)
+		}
+	}
+	return targets
+}
+
+// PurgeTrashOlderThan permanently deletes trash items older than the given number of days
+// and returns how many were removed
+func (a *App) PurgeTrashOlderThan(days int) int {
+	if days < 0 {
+		return 0
+	}
+	purged, err := a.fileOps.PurgeTrashOlderThan(time.Duration(days) * 24 * time.Hour)
+	if err != nil {
+		logPrintf("Purging trash failed: %v", err)
+	}
+	return purged
+}
+
+// streamTrash lists the trash as the virtual trash:// folder, using the same
+// events as a local directory. Entries carry the item ID, original path and
+// deletion time so the frontend can offer restore actions.
+func (a *App) streamTrash(dir string) {
+	emitter := NewEventEmitter(a.ctx)
+	emitter.EmitDirectoryStart(dir)
+
+	items, err := a.fileOps.ListTrash()
+	if err != nil {
+		emitter.EmitDirectoryError("Cannot read trash: " + err.Error())
+		return
+	}
+
+	totalFiles, totalDirs := 0, 0
+	batch := make([]WireEntry, 0, streamBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if mp, err := GetSerializationUtils().encodeMsgPackBinary(batch); err == nil {
+			emitter.EmitDirectoryBatchMP(mp, len(batch))
+		}
+		batch = batch[:0]
+	}
+
+	for _, item := range items {
+		batch = append(batch, WireEntry{
+			N: item.Name,
+			D: item.IsDir,
+			S: item.Size,
+			M: item.DeletedAt,
+			I: item.ID,
+			O: item.OriginalPath,
+			X: item.DeletedAt,
+		})
+		if item.IsDir {
+			totalDirs++
+		} else {
+			totalFiles++
+		}
+		if len(batch) == streamBatchSize {
+			flush()
+		}
+	}
+	flush()
+
+	emitter.EmitDirectoryComplete(dir, totalFiles, totalDirs)
+}
@@ -0,0 +1,39 @@
+package backend
+
+import "fmt"
+
+var errAttrsBlocked = fmt.Errorf("changing attributes of this path is blocked by the protected-path policy")
+
+// GetExtendedAttributes lists the extended attributes of path
+func (a *App) GetExtendedAttributes(path string) ([]ExtendedAttribute, error) {
+	return GetExtendedAttributes(path)
+}
+
+// SetExtendedAttribute creates or replaces an extended attribute on path
+func (a *App) SetExtendedAttribute(path string, attr ExtendedAttribute) error {
+	if !a.checkPolicy(OpAttrs, path) {
+		return errAttrsBlocked
+	}
+	return SetExtendedAttribute(path, attr)
+}
+
+// RemoveExtendedAttribute deletes an extended attribute from path
+func (a *App) RemoveExtendedAttribute(path, name string) error {
+	if !a.checkPolicy(OpAttrs, path) {
+		return errAttrsBlocked
+	}
+	return RemoveExtendedAttribute(path, name)
+}
+
+// GetACL returns the POSIX access and default ACLs of path
+func (a *App) GetACL(path string) (ACL, error) {
+	return GetACL(path)
+}
+
+// SetACL replaces the POSIX ACLs of path
+func (a *App) SetACL(path string, acl ACL) error {
+	if !a.checkPolicy(OpAttrs, path) {
+		return errAttrsBlocked
+	}
+	return SetACL(path, acl)
+}
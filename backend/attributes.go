@@ -0,0 +1,303 @@
+package backend
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SetAttributes applies mode, ownership, timestamp and attribute-flag changes to
+// paths, descending into directories when recursive is set. Symlinks are never
+// followed; only their ownership is changed. Every path is attempted and
+// failures are reported individually.
+func (fo *FileOperationsManager) SetAttributes(paths []string, changes AttributeChanges, recursive bool) AttributeResult {
+	result := AttributeResult{Failed: []AttributeFailure{}}
+	if len(paths) == 0 {
+		result.Message = "no paths given"
+		return result
+	}
+	if changes.isEmpty() {
+		result.Message = "no attribute changes given"
+		return result
+	}
+
+	var mode *modeChange
+	if changes.Mode != "" {
+		parsed, err := parseModeChange(changes.Mode)
+		if err != nil {
+			result.Message = err.Error()
+			return result
+		}
+		mode = parsed
+	}
+	if !fo.allowedByPolicy(OpAttrs, fo.policy.attributeTargets(paths, recursive)...) {
+		result.Message = "changing attributes of these paths is blocked by the protected-path policy"
+		return result
+	}
+
+	apply := func(path string, info fs.FileInfo) {
+		if err := applyAttributeChanges(path, info, changes, mode); err != nil {
+			result.Failed = append(result.Failed, AttributeFailure{Path: path, Error: err.Error()})
+			return
+		}
+		result.Updated++
+	}
+
+	for _, root := range paths {
+		root = filepath.Clean(root)
+		info, err := os.Lstat(root)
+		if err != nil {
+			result.Failed = append(result.Failed, AttributeFailure{Path: root, Error: err.Error()})
+			continue
+		}
+		if !recursive || !info.IsDir() {
+			apply(root, info)
+			continue
+		}
+
+		// Directories are updated after their contents so a mode that removes
+		// write or execute permission does not lock the walk out
+		var dirs []string
+		var dirInfos []fs.FileInfo
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				result.Failed = append(result.Failed, AttributeFailure{Path: path, Error: err.Error()})
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				result.Failed = append(result.Failed, AttributeFailure{Path: path, Error: err.Error()})
+				return nil
+			}
+			if d.IsDir() {
+				dirs = append(dirs, path)
+				dirInfos = append(dirInfos, info)
+				return nil
+			}
+			apply(path, info)
+			return nil
+		})
+		for i := len(dirs) - 1; i >= 0; i-- {
+			apply(dirs[i], dirInfos[i])
+		}
+	}
+
+	result.Success = len(result.Failed) == 0
+	logPrintf("Updated attributes of %d items (%d failed)", result.Updated, len(result.Failed))
+	return result
+}
+
+func (c AttributeChanges) isEmpty() bool {
+	return c.Mode == "" && c.Owner == "" && c.Group == "" && c.ModTime == 0 && c.AccessTime == 0 &&
+		!c.Touch && !c.hasFlags()
+}
+
+func (c AttributeChanges) hasFlags() bool {
+	return c.ReadOnly != nil || c.Hidden != nil || c.System != nil || c.Archive != nil
+}
+
+func applyAttributeChanges(path string, info fs.FileInfo, changes AttributeChanges, mode *modeChange) error {
+	isLink := info.Mode()&os.ModeSymlink != 0
+
+	if mode != nil && !isLink {
+		if err := applyMode(path, mode.apply(info.Mode(), info.IsDir())); err != nil {
+			return err
+		}
+	}
+	if changes.Owner != "" || changes.Group != "" {
+		if err := applyOwnership(path, changes.Owner, changes.Group); err != nil {
+			return err
+		}
+	}
+	if !isLink && (changes.Touch || changes.ModTime != 0 || changes.AccessTime != 0) {
+		// Zero times are left unchanged by os.Chtimes
+		var atime, mtime time.Time
+		if changes.Touch {
+			atime, mtime = time.Now(), time.Now()
+		}
+		if changes.AccessTime != 0 {
+			atime = time.Unix(changes.AccessTime, 0)
+		}
+		if changes.ModTime != 0 {
+			mtime = time.Unix(changes.ModTime, 0)
+		}
+		if err := os.Chtimes(path, atime, mtime); err != nil {
+			return err
+		}
+	}
+	if changes.hasFlags() {
+		if err := applyFileFlags(path, changes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookupUserID resolves a user name or numeric uid
+func lookupUserID(name string) (int, error) {
+	if id, err := strconv.Atoi(name); err == nil && id >= 0 {
+		return id, nil
+	}
+	u, err := user.Lookup(name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(u.Uid)
+}
+
+// lookupGroupID resolves a group name or numeric gid
+func lookupGroupID(name string) (int, error) {
+	if id, err := strconv.Atoi(name); err == nil && id >= 0 {
+		return id, nil
+	}
+	g, err := user.LookupGroup(name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(g.Gid)
+}
+
+// modeChange is a parsed chmod argument: an absolute octal mode or a list of
+// symbolic clauses such as "u+x,go-w" or "a=rX"
+type modeChange struct {
+	absolute bool
+	value    uint32
+	clauses  []modeClause
+}
+
+type modeClause struct {
+	who   uint32 // bits the clause may touch
+	op    byte   // '+', '-' or '='
+	perms string // letters from rwxXst
+}
+
+const (
+	modeWhoUser  = 0o4700
+	modeWhoGroup = 0o2070
+	modeWhoOther = 0o1007
+	modeWhoAll   = 0o7777
+)
+
+func parseModeChange(spec string) (*modeChange, error) {
+	spec = strings.TrimSpace(spec)
+	if value, err := strconv.ParseUint(spec, 8, 32); err == nil {
+		if len(spec) > 4 || value > 0o7777 {
+			return nil, fmt.Errorf("invalid octal mode: %s", spec)
+		}
+		return &modeChange{absolute: true, value: uint32(value)}, nil
+	}
+
+	change := &modeChange{}
+	for _, part := range strings.Split(spec, ",") {
+		i := 0
+		var who uint32
+		for ; i < len(part) && strings.IndexByte("ugoa", part[i]) >= 0; i++ {
+			switch part[i] {
+			case 'u':
+				who |= modeWhoUser
+			case 'g':
+				who |= modeWhoGroup
+			case 'o':
+				who |= modeWhoOther
+			case 'a':
+				who |= modeWhoAll
+			}
+		}
+		if who == 0 {
+			who = modeWhoAll
+		}
+		if i == len(part) {
+			return nil, fmt.Errorf("invalid mode: %s", spec)
+		}
+
+		for i < len(part) {
+			op := part[i]
+			if op != '+' && op != '-' && op != '=' {
+				return nil, fmt.Errorf("invalid mode operator %q in %s", op, spec)
+			}
+			i++
+			start := i
+			for ; i < len(part) && strings.IndexByte("rwxXst", part[i]) >= 0; i++ {
+			}
+			if i < len(part) && part[i] != '+' && part[i] != '-' && part[i] != '=' {
+				return nil, fmt.Errorf("invalid permission %q in %s", part[i], spec)
+			}
+			change.clauses = append(change.clauses, modeClause{who: who, op: op, perms: part[start:i]})
+		}
+	}
+	return change, nil
+}
+
+// apply returns the mode produced by applying the change to current
+func (m *modeChange) apply(current fs.FileMode, isDir bool) fs.FileMode {
+	bits := unixModeBits(current)
+	if m.absolute {
+		bits = m.value
+	}
+
+	for _, c := range m.clauses {
+		var perms uint32
+		for i := 0; i < len(c.perms); i++ {
+			switch c.perms[i] {
+			case 'r':
+				perms |= 0o444
+			case 'w':
+				perms |= 0o222
+			case 'x':
+				perms |= 0o111
+			case 'X':
+				if isDir || bits&0o111 != 0 {
+					perms |= 0o111
+				}
+			case 's':
+				perms |= 0o6000
+			case 't':
+				perms |= 0o1000
+			}
+		}
+		perms &= c.who
+
+		switch c.op {
+		case '+':
+			bits |= perms
+		case '-':
+			bits &^= perms
+		case '=':
+			bits = bits&^c.who | perms
+		}
+	}
+	return fileModeFromUnix(bits)
+}
+
+func unixModeBits(mode fs.FileMode) uint32 {
+	bits := uint32(mode.Perm())
+	if mode&fs.ModeSetuid != 0 {
+		bits |= 0o4000
+	}
+	if mode&fs.ModeSetgid != 0 {
+		bits |= 0o2000
+	}
+	if mode&fs.ModeSticky != 0 {
+		bits |= 0o1000
+	}
+	return bits
+}
+
+func fileModeFromUnix(bits uint32) fs.FileMode {
+	mode := fs.FileMode(bits & 0o777)
+	if bits&0o4000 != 0 {
+		mode |= fs.ModeSetuid
+	}
+	if bits&0o2000 != 0 {
+		mode |= fs.ModeSetgid
+	}
+	if bits&0o1000 != 0 {
+		mode |= fs.ModeSticky
+	}
+	return mode
+}
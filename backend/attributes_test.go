@@ -0,0 +1,84 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestModeChange(t *testing.T) {
+	tests := []struct {
+		spec  string
+		from  uint32
+		isDir bool
+		want  uint32
+	}{
+		{"755", 0o600, false, 0o755},
+		{"0644", 0o777, false, 0o644},
+		{"4755", 0o644, false, 0o4755},
+		{"u+x", 0o644, false, 0o744},
+		{"u+x,g-w", 0o664, false, 0o744},
+		{"go-rwx", 0o755, false, 0o700},
+		{"a=r", 0o777, false, 0o444},
+		{"=rw", 0o777, false, 0o666},
+		{"a=rX", 0o644, false, 0o444},
+		{"a=rX", 0o744, false, 0o555},
+		{"a=rX", 0o600, true, 0o555},
+		{"a+X", 0o600, false, 0o600},
+		{"u=rwx,g=rx,o=", 0o777, false, 0o750},
+		{"u+s,g+s", 0o755, false, 0o6755},
+		{"+t", 0o777, true, 0o1777},
+		{"o-t", 0o1777, true, 0o777},
+		{"u-x+w", 0o544, false, 0o644},
+		{"u=rw", 0o4755, false, 0o0655},
+	}
+	for _, tt := range tests {
+		change, err := parseModeChange(tt.spec)
+		if err != nil {
+			t.Errorf("parseModeChange(%q): %v", tt.spec, err)
+			continue
+		}
+		got := unixModeBits(change.apply(fileModeFromUnix(tt.from), tt.isDir))
+		if got != tt.want {
+			t.Errorf("%q on %04o (dir %v) = %04o, want %04o", tt.spec, tt.from, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestParseModeChangeRejectsInvalid(t *testing.T) {
+	for _, spec := range []string{"", "u", "8", "77777", "u+q", "z+x", "u+x,", "u!x", "+x y"} {
+		if _, err := parseModeChange(spec); err == nil {
+			t.Errorf("parseModeChange(%q) succeeded", spec)
+		}
+	}
+}
+
+func TestRecursiveAttributesRespectProtectedDescendants(t *testing.T) {
+	root := t.TempDir()
+	protected := filepath.Join(root, "sub", "protected")
+	if err := os.MkdirAll(protected, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	fo := &FileOperationsManager{policy: newTestPolicy(ProtectedPathRule{Path: protected, Action: PolicyDeny})}
+	changes := AttributeChanges{ModTime: 1000000000}
+
+	if result := fo.SetAttributes([]string{root}, changes, true); result.Updated != 0 {
+		t.Errorf("recursive change above a protected folder updated %d items", result.Updated)
+	}
+	if info, _ := os.Stat(protected); info.ModTime().Unix() == 1000000000 {
+		t.Error("the protected folder was changed")
+	}
+
+	// Without recursion only the folder itself changes
+	if result := fo.SetAttributes([]string{root}, changes, false); !result.Success || result.Updated != 1 {
+		t.Errorf("non-recursive change = %+v", result)
+	}
+	// A sibling tree without protected paths is fine
+	other := filepath.Join(root, "other")
+	if err := os.Mkdir(other, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if result := fo.SetAttributes([]string{other}, changes, true); !result.Success {
+		t.Errorf("recursive change of an unprotected folder = %+v", result)
+	}
+}
@@ -0,0 +1,38 @@
+//go:build !windows
+
+package backend
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+func applyMode(path string, mode fs.FileMode) error {
+	return os.Chmod(path, mode)
+}
+
+// applyOwnership changes owner and group, accepting names or numeric IDs.
+// Unprivileged users can usually only change the group to one they belong to.
+func applyOwnership(path, owner, group string) error {
+	uid, gid := -1, -1
+	if owner != "" {
+		id, err := lookupUserID(owner)
+		if err != nil {
+			return err
+		}
+		uid = id
+	}
+	if group != "" {
+		id, err := lookupGroupID(group)
+		if err != nil {
+			return err
+		}
+		gid = id
+	}
+	return os.Lchown(path, uid, gid)
+}
+
+func applyFileFlags(path string, _ AttributeChanges) error {
+	return fmt.Errorf("readonly/hidden/system/archive flags are only supported on Windows")
+}
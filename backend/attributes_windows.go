@@ -0,0 +1,39 @@
+//go:build windows
+
+package backend
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+func applyMode(path string, _ fs.FileMode) error {
+	return fmt.Errorf("mode bits are not supported on Windows; use the readonly flag")
+}
+
+func applyOwnership(path, _, _ string) error {
+	return fmt.Errorf("changing owner or group is not supported on Windows")
+}
+
+// applyFileFlags sets or clears the readonly, hidden, system and archive attributes
+func applyFileFlags(path string, changes AttributeChanges) error {
+	var set, clear uint32
+	for _, flag := range []struct {
+		value *bool
+		bit   uint32
+	}{
+		{changes.ReadOnly, FILE_ATTRIBUTE_READONLY},
+		{changes.Hidden, FILE_ATTRIBUTE_HIDDEN},
+		{changes.System, FILE_ATTRIBUTE_SYSTEM},
+		{changes.Archive, FILE_ATTRIBUTE_ARCHIVE},
+	} {
+		switch {
+		case flag.value == nil:
+		case *flag.value:
+			set |= flag.bit
+		default:
+			clear |= flag.bit
+		}
+	}
+	return updateFileAttributesNative(path, set, clear)
+}
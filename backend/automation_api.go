@@ -0,0 +1,283 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultAutomationPort = 47321
+	automationMaxBody     = 1 << 20
+	mimeMsgPack           = "application/msgpack"
+)
+
+// AutomationAPIInfo describes the local automation endpoint. Scripts discover it
+// through automation.json in the settings directory (readable only by the user).
+type AutomationAPIInfo struct {
+	Running bool   `json:"running" msgpack:"running"`
+	URL     string `json:"url" msgpack:"url"`
+	Port    int    `json:"port" msgpack:"port"`
+	Token   string `json:"token,omitempty" msgpack:"token,omitempty"`
+}
+
+// AutomationServer exposes App methods over a localhost-only, token-authenticated
+// HTTP API: POST /v1/<method> with a JSON object of named parameters.
+type AutomationServer struct {
+	app *App
+
+	mu     sync.Mutex
+	server *http.Server
+	info   AutomationAPIInfo
+}
+
+// NewAutomationServer creates a new automation server bound to app
+func NewAutomationServer(app *App) *AutomationServer {
+	return &AutomationServer{app: app}
+}
+
+// Start listens on 127.0.0.1:port with a fresh random token
+func (s *AutomationServer) Start(port int) (AutomationAPIInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.server != nil {
+		return s.info, nil
+	}
+	if port <= 0 {
+		port = defaultAutomationPort
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		return AutomationAPIInfo{}, fmt.Errorf("failed to listen on port %d: %w", port, err)
+	}
+	token, err := randomHex(24)
+	if err != nil {
+		listener.Close()
+		return AutomationAPIInfo{}, err
+	}
+
+	port = listener.Addr().(*net.TCPAddr).Port
+	s.info = AutomationAPIInfo{
+		Running: true,
+		URL:     fmt.Sprintf("http://127.0.0.1:%d/v1/", port),
+		Port:    port,
+		Token:   token,
+	}
+	s.server = &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	if err := s.writeDiscoveryFile(); err != nil {
+		logPrintf("⚠️ Failed to write automation discovery file: %v", err)
+	}
+
+	server := s.server
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logPrintf("Automation API stopped: %v", err)
+		}
+	}()
+
+	logPrintln("🤖 Automation API listening on", s.info.URL)
+	return s.info, nil
+}
+
+// Stop shuts the server down and removes the discovery file
+func (s *AutomationServer) Stop() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.server == nil {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := s.server.Shutdown(ctx); err != nil {
+		s.server.Close()
+	}
+	s.server = nil
+	s.info = AutomationAPIInfo{}
+	os.Remove(automationDiscoveryPath())
+	return true
+}
+
+// Info returns the current endpoint details
+func (s *AutomationServer) Info() AutomationAPIInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.info
+}
+
+func automationDiscoveryPath() string {
+	return filepath.Join(filepath.Dir(settingsFilePath()), "automation.json")
+}
+
+func (s *AutomationServer) writeDiscoveryFile() error {
+	discoveryPath := automationDiscoveryPath()
+	if err := os.MkdirAll(filepath.Dir(discoveryPath), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s.info, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(discoveryPath, data, 0600)
+}
+
+// ServeHTTP authenticates and dispatches a request
+func (s *AutomationServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Browsers always send Origin on cross-site requests; scripts don't.
+	// Rejecting it keeps web pages from driving the explorer via DNS rebinding.
+	if r.Header.Get("Origin") != "" {
+		http.Error(w, "Cross-origin requests are not allowed", http.StatusForbidden)
+		return
+	}
+	host, _, _ := net.SplitHostPort(r.Host)
+	if host != "127.0.0.1" && host != "localhost" {
+		http.Error(w, "Invalid host", http.StatusForbidden)
+		return
+	}
+
+	bearer, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !tokensEqual(bearer, s.Info().Token) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	method, ok := strings.CutPrefix(r.URL.Path, "/v1/")
+	if !ok || method == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Use POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var params automationParams
+	body, err := io.ReadAll(io.LimitReader(r.Body, automationMaxBody))
+	if err != nil {
+		http.Error(w, "Cannot read request body", http.StatusBadRequest)
+		return
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &params); err != nil {
+			http.Error(w, "Invalid JSON parameters: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	result, err := s.call(method, params)
+	if err != nil {
+		status := http.StatusBadRequest
+		// Protected paths cannot be confirmed from here, so both deny and confirm refuse
+		var policyErr *PolicyError
+		if errors.As(err, &policyErr) {
+			status = http.StatusForbidden
+		}
+		s.respond(w, r, status, map[string]interface{}{"error": err.Error()})
+		return
+	}
+	s.respond(w, r, http.StatusOK, result)
+}
+
+type automationParams struct {
+	Path      string   `json:"path"`
+	Paths     []string `json:"paths"`
+	Dest      string   `json:"dest"`
+	NewName   string   `json:"newName"`
+	Permanent bool     `json:"permanent"`
+	Root      string   `json:"root"`
+	Query     string   `json:"query"`
+	Limit     int      `json:"limit"`
+	ID        string   `json:"id"`
+}
+
+// call mirrors the App bindings
+func (s *AutomationServer) call(method string, p automationParams) (interface{}, error) {
+	a := s.app
+	switch method {
+	case "navigate":
+		resp := a.NavigateToPath(p.Path)
+		if resp.Success {
+			a.requestNavigation(NavigateRequest{Path: resp.Data.CurrentPath, Source: "automation"})
+		}
+		return resp, nil
+	case "list":
+		return a.ListDirectory(p.Path), nil
+	case "copy":
+		if len(p.Paths) == 0 || p.Dest == "" {
+			return nil, fmt.Errorf("paths and dest are required")
+		}
+		if err := a.policy.Check(OpCopy, transferTargets(p.Paths, p.Dest)...); err != nil {
+			return nil, err
+		}
+		return map[string]string{"jobId": a.StartCopyJob(p.Paths, p.Dest)}, nil
+	case "move":
+		if len(p.Paths) == 0 || p.Dest == "" {
+			return nil, fmt.Errorf("paths and dest are required")
+		}
+		if err := a.policy.Check(OpMove, append(append([]string{}, p.Paths...), transferTargets(p.Paths, p.Dest)...)...); err != nil {
+			return nil, err
+		}
+		return map[string]string{"jobId": a.StartMoveJob(p.Paths, p.Dest)}, nil
+	case "rename":
+		return map[string]bool{"success": a.RenameFile(p.Path, p.NewName)}, nil
+	case "delete":
+		if p.Permanent {
+			return map[string]bool{"success": a.DeleteFiles(p.Paths)}, nil
+		}
+		return a.RecycleFiles(p.Paths), nil
+	case "search":
+		return a.SearchFiles(p.Root, p.Query, p.Limit), nil
+	case "jobs":
+		return a.GetJobs(), nil
+	case "job":
+		info, ok := a.jobs.Get(p.ID)
+		if !ok {
+			return nil, fmt.Errorf("unknown job: %s", p.ID)
+		}
+		return info, nil
+	case "cancelJob":
+		return map[string]bool{"success": a.CancelJob(p.ID)}, nil
+	default:
+		return nil, fmt.Errorf("unknown method: %s", method)
+	}
+}
+
+// respond encodes v as msgpack when the client asks for it, JSON otherwise
+func (s *AutomationServer) respond(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	accept := r.Header.Get("Accept")
+	if strings.Contains(accept, mimeMsgPack) || strings.Contains(accept, "application/x-msgpack") {
+		var packed interface{}
+		var err error
+		if resp, ok := v.(NavigationResponse); ok {
+			packed, err = GetSerializationUtils().SerializeNavigationResponse(resp)
+		} else {
+			packed, err = GetSerializationUtils().SerializeGeneric(v)
+		}
+		if data := packOrNil(packed, err); data != nil {
+			w.Header().Set("Content-Type", mimeMsgPack)
+			w.WriteHeader(status)
+			w.Write(data)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
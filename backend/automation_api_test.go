@@ -0,0 +1,34 @@
+package backend
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAutomationRefusesProtectedTransfers(t *testing.T) {
+	root := t.TempDir()
+	protected := filepath.Join(root, "protected")
+	s := NewAutomationServer(&App{policy: newTestPolicy(ProtectedPathRule{Path: protected, Action: PolicyDeny, IncludeChildren: true})})
+	s.info.Token = "secret"
+
+	for _, method := range []string{"copy", "move"} {
+		body, _ := json.Marshal(automationParams{Paths: []string{filepath.Join(root, "a.txt")}, Dest: protected})
+		req := httptest.NewRequest(http.MethodPost, "http://127.0.0.1:1/v1/"+method, strings.NewReader(string(body)))
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s into a protected folder = %d %s, want 403", method, rec.Code, rec.Body)
+			continue
+		}
+		var resp map[string]string
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || !strings.Contains(resp["error"], protected) {
+			t.Errorf("%s response = %s, want the policy error", method, rec.Body)
+		}
+	}
+}
@@ -0,0 +1,502 @@
+package backend
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Rename rule types
+const (
+	RenameRuleReplace   = "replace"
+	RenameRuleInsert    = "insert"
+	RenameRuleCounter   = "counter"
+	RenameRuleCase      = "case"
+	RenameRuleExtension = "extension"
+	RenameRuleDate      = "date"
+)
+
+const (
+	maxRenameHistory = 20
+	renameTempPrefix = ".lx-rename-"
+)
+
+// RenameRule is one step of a batch rename; rules are applied in order.
+// Unless IncludeExtension is set, rules only see the name without its extension.
+type RenameRule struct {
+	Type             string `json:"type" msgpack:"type"`
+	IncludeExtension bool   `json:"includeExtension,omitempty" msgpack:"includeExtension,omitempty"`
+
+	// replace: literal or regular expression; Replace may use $1-style capture groups
+	Find          string `json:"find,omitempty" msgpack:"find,omitempty"`
+	Replace       string `json:"replace,omitempty" msgpack:"replace,omitempty"`
+	Regex         bool   `json:"regex,omitempty" msgpack:"regex,omitempty"`
+	CaseSensitive bool   `json:"caseSensitive,omitempty" msgpack:"caseSensitive,omitempty"`
+
+	// insert, counter, date: Position is "prefix" (default) or "suffix"
+	Text      string `json:"text,omitempty" msgpack:"text,omitempty"`
+	Position  string `json:"position,omitempty" msgpack:"position,omitempty"`
+	Separator string `json:"separator,omitempty" msgpack:"separator,omitempty"`
+
+	// counter
+	Start   int `json:"start,omitempty" msgpack:"start,omitempty"`
+	Step    int `json:"step,omitempty" msgpack:"step,omitempty"`
+	Padding int `json:"padding,omitempty" msgpack:"padding,omitempty"`
+
+	// case: "lower", "upper", "title" or "sentence"
+	Case string `json:"case,omitempty" msgpack:"case,omitempty"`
+
+	// extension: new extension with or without the dot; empty removes it
+	Extension string `json:"extension,omitempty" msgpack:"extension,omitempty"`
+
+	// date: Format uses YYYY, YY, MM, DD, hh, mm, ss; Source is "mtime" (default) or "exif"
+	Format string `json:"format,omitempty" msgpack:"format,omitempty"`
+	Source string `json:"source,omitempty" msgpack:"source,omitempty"`
+}
+
+// BatchRenameItem is the planned rename of one path
+type BatchRenameItem struct {
+	Path     string `json:"path" msgpack:"path"`
+	OldName  string `json:"oldName" msgpack:"oldName"`
+	NewName  string `json:"newName" msgpack:"newName"`
+	Changed  bool   `json:"changed" msgpack:"changed"`
+	Conflict bool   `json:"conflict,omitempty" msgpack:"conflict,omitempty"`
+	Error    string `json:"error,omitempty" msgpack:"error,omitempty"`
+}
+
+// BatchRenamePreview lists the planned renames; Valid is false when any item
+// has an error or collides with another name
+type BatchRenamePreview struct {
+	Items     []BatchRenameItem `json:"items" msgpack:"items"`
+	Valid     bool              `json:"valid" msgpack:"valid"`
+	Changed   int               `json:"changed" msgpack:"changed"`
+	Conflicts int               `json:"conflicts" msgpack:"conflicts"`
+	Errors    int               `json:"errors" msgpack:"errors"`
+}
+
+// BatchRenameResult reports an applied (or undone) batch rename
+type BatchRenameResult struct {
+	Success bool              `json:"success" msgpack:"success"`
+	Renamed int               `json:"renamed" msgpack:"renamed"`
+	UndoID  string            `json:"undoId,omitempty" msgpack:"undoId,omitempty"`
+	Message string            `json:"message,omitempty" msgpack:"message,omitempty"`
+	Items   []BatchRenameItem `json:"items" msgpack:"items"`
+}
+
+type renameStep struct {
+	from string
+	to   string
+}
+
+type batchRenameRecord struct {
+	id    string
+	steps []renameStep // applied renames, used in reverse for undo
+}
+
+// BatchRenamer previews and applies multi-file renames and keeps an undo history
+type BatchRenamer struct {
+	policy *PathPolicy
+
+	mu      sync.Mutex
+	history []batchRenameRecord
+}
+
+// NewBatchRenamer creates a new batch renamer that consults policy
+func NewBatchRenamer(policy *PathPolicy) *BatchRenamer {
+	return &BatchRenamer{policy: policy}
+}
+
+// Preview computes new names for paths without touching the filesystem
+func (b *BatchRenamer) Preview(paths []string, rules []RenameRule) BatchRenamePreview {
+	preview := BatchRenamePreview{Items: make([]BatchRenameItem, 0, len(paths))}
+	validator := &FileSystemManager{}
+
+	for i, p := range paths {
+		clean := filepath.Clean(p)
+		item := BatchRenameItem{Path: clean, OldName: filepath.Base(clean)}
+
+		info, err := os.Lstat(clean)
+		if err != nil {
+			item.Error = err.Error()
+			item.NewName = item.OldName
+			preview.Items = append(preview.Items, item)
+			continue
+		}
+
+		name, err := applyRenameRules(clean, info, i, rules)
+		if err == nil {
+			name, err = validator.validateAndSanitizeFileName(name)
+		}
+		if err != nil {
+			item.Error = err.Error()
+			item.NewName = item.OldName
+		} else {
+			item.NewName = name
+			item.Changed = name != item.OldName
+		}
+		preview.Items = append(preview.Items, item)
+	}
+
+	markRenameConflicts(preview.Items)
+
+	for _, item := range preview.Items {
+		if item.Changed {
+			preview.Changed++
+		}
+		if item.Conflict {
+			preview.Conflicts++
+		} else if item.Error != "" {
+			preview.Errors++
+		}
+	}
+	preview.Valid = preview.Conflicts == 0 && preview.Errors == 0
+	return preview
+}
+
+// markRenameConflicts flags items whose new name is used by another item in the
+// batch, or by an existing file that is not itself being renamed away
+func markRenameConflicts(items []BatchRenameItem) {
+	renamedAway := make(map[string]bool)
+	for _, item := range items {
+		if item.Changed {
+			renamedAway[renameKey(item.Path)] = true
+		}
+	}
+
+	targets := make(map[string][]int)
+	for i, item := range items {
+		target := filepath.Join(filepath.Dir(item.Path), item.NewName)
+		targets[renameKey(target)] = append(targets[renameKey(target)], i)
+	}
+
+	for key, indexes := range targets {
+		if len(indexes) > 1 {
+			for _, i := range indexes {
+				items[i].Conflict = true
+				if items[i].Error == "" {
+					items[i].Error = "name collides with another item in the batch"
+				}
+			}
+			continue
+		}
+
+		i := indexes[0]
+		if !items[i].Changed || renamedAway[key] {
+			continue
+		}
+		target := filepath.Join(filepath.Dir(items[i].Path), items[i].NewName)
+		if existing, err := os.Lstat(target); err == nil {
+			// A case-only rename sees itself on case-insensitive filesystems
+			if self, err := os.Lstat(items[i].Path); err == nil && os.SameFile(existing, self) {
+				continue
+			}
+			items[i].Conflict = true
+			items[i].Error = "a file with this name already exists"
+		}
+	}
+}
+
+// renameKey compares names the way the filesystem does
+func renameKey(path string) string {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		return strings.ToLower(path)
+	}
+	return path
+}
+
+// Apply renames paths according to rules. Renames go through unique temporary
+// names first so swaps (a↔b) and chains work; any failure rolls back.
+func (b *BatchRenamer) Apply(paths []string, rules []RenameRule) BatchRenameResult {
+	preview := b.Preview(paths, rules)
+	result := BatchRenameResult{Items: preview.Items}
+	if !preview.Valid {
+		result.Message = fmt.Sprintf("%d conflicts and %d invalid names; nothing was renamed", preview.Conflicts, preview.Errors)
+		return result
+	}
+
+	var steps []renameStep
+	var touched []string
+	for _, item := range preview.Items {
+		if item.Changed {
+			target := filepath.Join(filepath.Dir(item.Path), item.NewName)
+			steps = append(steps, renameStep{from: item.Path, to: target})
+			touched = append(touched, item.Path, target)
+		}
+	}
+	if len(steps) == 0 {
+		result.Success = true
+		result.Message = "no names changed"
+		return result
+	}
+	if err := b.policy.Check(OpRename, touched...); err != nil {
+		result.Message = err.Error()
+		return result
+	}
+
+	if err := runRenameSteps(steps); err != nil {
+		result.Message = err.Error()
+		return result
+	}
+
+	id, _ := randomHex(8)
+	b.mu.Lock()
+	b.history = append(b.history, batchRenameRecord{id: id, steps: steps})
+	if len(b.history) > maxRenameHistory {
+		b.history = b.history[len(b.history)-maxRenameHistory:]
+	}
+	b.mu.Unlock()
+
+	logPrintf("✏️ Batch renamed %d items", len(steps))
+	result.Success = true
+	result.Renamed = len(steps)
+	result.UndoID = id
+	return result
+}
+
+// Undo reverts a batch rename; an empty undoID reverts the most recent one
+func (b *BatchRenamer) Undo(undoID string) BatchRenameResult {
+	b.mu.Lock()
+	index := -1
+	for i := len(b.history) - 1; i >= 0; i-- {
+		if undoID == "" || b.history[i].id == undoID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		b.mu.Unlock()
+		return BatchRenameResult{Items: []BatchRenameItem{}, Message: "nothing to undo"}
+	}
+	record := b.history[index]
+	b.mu.Unlock()
+
+	inverse := make([]renameStep, 0, len(record.steps))
+	items := make([]BatchRenameItem, 0, len(record.steps))
+	var touched []string
+	for _, s := range record.steps {
+		if _, err := os.Lstat(s.to); err != nil {
+			return BatchRenameResult{Items: []BatchRenameItem{}, Message: fmt.Sprintf("cannot undo: %s no longer exists", s.to)}
+		}
+		inverse = append(inverse, renameStep{from: s.to, to: s.from})
+		items = append(items, BatchRenameItem{Path: s.to, OldName: filepath.Base(s.to), NewName: filepath.Base(s.from), Changed: true})
+		touched = append(touched, s.to, s.from)
+	}
+	if err := b.policy.Check(OpRename, touched...); err != nil {
+		return BatchRenameResult{Items: items, Message: err.Error()}
+	}
+	if err := runRenameSteps(inverse); err != nil {
+		return BatchRenameResult{Items: items, Message: err.Error()}
+	}
+
+	b.mu.Lock()
+	for i := range b.history {
+		if b.history[i].id == record.id {
+			b.history = append(b.history[:i], b.history[i+1:]...)
+			break
+		}
+	}
+	b.mu.Unlock()
+
+	logPrintf("↩️ Undid batch rename of %d items", len(inverse))
+	return BatchRenameResult{Success: true, Renamed: len(inverse), Items: items}
+}
+
+// runRenameSteps performs renames in two phases (source → temp, temp → target),
+// undoing every completed rename if any step fails
+func runRenameSteps(steps []renameStep) error {
+	temps := make([]string, len(steps))
+	phase1, phase2 := 0, 0
+
+	rollback := func() {
+		for i := phase2 - 1; i >= 0; i-- {
+			os.Rename(steps[i].to, temps[i])
+		}
+		for i := phase1 - 1; i >= 0; i-- {
+			os.Rename(temps[i], steps[i].from)
+		}
+	}
+
+	token, err := randomHex(6)
+	if err != nil {
+		return err
+	}
+	for i, s := range steps {
+		temps[i] = filepath.Join(filepath.Dir(s.from), fmt.Sprintf("%s%s-%d", renameTempPrefix, token, i))
+		if err := os.Rename(s.from, temps[i]); err != nil {
+			rollback()
+			return fmt.Errorf("rename %s: %w", filepath.Base(s.from), err)
+		}
+		phase1++
+	}
+	for i, s := range steps {
+		if _, err := os.Lstat(s.to); err == nil {
+			rollback()
+			return fmt.Errorf("rename %s: destination appeared during rename", filepath.Base(s.to))
+		}
+		if err := os.Rename(temps[i], s.to); err != nil {
+			rollback()
+			return fmt.Errorf("rename %s: %w", filepath.Base(s.from), err)
+		}
+		phase2++
+	}
+	return nil
+}
+
+// applyRenameRules runs rules over the name of path; index is its position in the batch
+func applyRenameRules(path string, info os.FileInfo, index int, rules []RenameRule) (string, error) {
+	name := filepath.Base(path)
+	for _, rule := range rules {
+		stem, ext := name, ""
+		if !rule.IncludeExtension && !info.IsDir() {
+			ext = filepath.Ext(name)
+			stem = strings.TrimSuffix(name, ext)
+		}
+
+		switch rule.Type {
+		case RenameRuleReplace:
+			if rule.Find == "" {
+				continue
+			}
+			pattern := rule.Find
+			if !rule.Regex {
+				pattern = regexp.QuoteMeta(pattern)
+			}
+			if !rule.CaseSensitive {
+				pattern = "(?i)" + pattern
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return "", fmt.Errorf("invalid pattern: %v", err)
+			}
+			replacement := rule.Replace
+			if !rule.Regex {
+				replacement = strings.ReplaceAll(replacement, "$", "$$")
+			}
+			stem = re.ReplaceAllString(stem, replacement)
+
+		case RenameRuleInsert:
+			stem = insertText(stem, rule.Text, rule.Position, rule.Separator)
+
+		case RenameRuleCounter:
+			step := rule.Step
+			if step == 0 {
+				step = 1
+			}
+			n := rule.Start + index*step
+			counter := strconv.Itoa(n)
+			if rule.Padding > 0 {
+				counter = fmt.Sprintf("%0*d", rule.Padding, n)
+			}
+			stem = insertText(stem, counter, rule.Position, rule.Separator)
+
+		case RenameRuleCase:
+			stem = changeCase(stem, rule.Case)
+
+		case RenameRuleExtension:
+			if info.IsDir() {
+				continue
+			}
+			ext = strings.TrimPrefix(strings.TrimSpace(rule.Extension), ".")
+			if ext != "" {
+				ext = "." + ext
+			}
+			if rule.IncludeExtension {
+				stem = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+
+		case RenameRuleDate:
+			t := info.ModTime()
+			if rule.Source == "exif" && !info.IsDir() {
+				if taken, ok := readEXIFDateTime(path); ok {
+					t = taken
+				}
+			}
+			format := rule.Format
+			if format == "" {
+				format = "YYYY-MM-DD"
+			}
+			stem = insertText(stem, formatRenameDate(t, format), rule.Position, rule.Separator)
+
+		default:
+			return "", fmt.Errorf("unknown rename rule: %s", rule.Type)
+		}
+		name = stem + ext
+	}
+	return name, nil
+}
+
+func insertText(stem, text, position, separator string) string {
+	if text == "" {
+		return stem
+	}
+	if position == "suffix" {
+		return stem + separator + text
+	}
+	return text + separator + stem
+}
+
+func changeCase(s, mode string) string {
+	switch mode {
+	case "lower":
+		return strings.ToLower(s)
+	case "upper":
+		return strings.ToUpper(s)
+	case "title", "sentence":
+		runes := []rune(strings.ToLower(s))
+		start := true
+		for i, r := range runes {
+			if start && unicode.IsLetter(r) {
+				runes[i] = unicode.ToUpper(r)
+				start = false
+				continue
+			}
+			if mode == "title" && (r == ' ' || r == '-' || r == '_' || r == '.') {
+				start = true
+			}
+		}
+		return string(runes)
+	default:
+		return s
+	}
+}
+
+// formatRenameDate expands YYYY, YY, MM, DD, hh, mm and ss in format
+func formatRenameDate(t time.Time, format string) string {
+	tokens := []struct {
+		token string
+		value string
+	}{
+		{"YYYY", fmt.Sprintf("%04d", t.Year())},
+		{"YY", fmt.Sprintf("%02d", t.Year()%100)},
+		{"MM", fmt.Sprintf("%02d", int(t.Month()))},
+		{"DD", fmt.Sprintf("%02d", t.Day())},
+		{"hh", fmt.Sprintf("%02d", t.Hour())},
+		{"mm", fmt.Sprintf("%02d", t.Minute())},
+		{"ss", fmt.Sprintf("%02d", t.Second())},
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(format); {
+		matched := false
+		for _, tk := range tokens {
+			if strings.HasPrefix(format[i:], tk.token) {
+				b.WriteString(tk.value)
+				i += len(tk.token)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			b.WriteByte(format[i])
+			i++
+		}
+	}
+	return b.String()
+}
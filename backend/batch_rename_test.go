@@ -0,0 +1,297 @@
+package backend
+
+import (
+	"encoding/binary"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeRenameFiles creates each named file in dir with its own name as content
+// and returns their paths
+func writeRenameFiles(t *testing.T, dir string, names ...string) []string {
+	t.Helper()
+	paths := make([]string, 0, len(names))
+	for _, name := range names {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, p)
+	}
+	return paths
+}
+
+// dirContents maps each file in dir to its content
+func dirContents(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents := make(map[string]string, len(entries))
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents[e.Name()] = string(data)
+	}
+	return contents
+}
+
+func TestApplyRenameRules(t *testing.T) {
+	dir := t.TempDir()
+	modified := time.Date(2023, 7, 8, 9, 10, 11, 0, time.Local)
+	photo := filepath.Join(dir, "photo.jpg")
+	if err := os.WriteFile(photo, buildJPEG(buildTIFF(binary.LittleEndian, "", "2021:03:04 05:06:07")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(photo, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+	folder := filepath.Join(dir, "My.Folder")
+	if err := os.Mkdir(folder, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		path  string
+		index int
+		rules []RenameRule
+		want  string
+	}{
+		{"literal replace is case-insensitive", photo, 0, []RenameRule{{Type: RenameRuleReplace, Find: "PHOTO", Replace: "pic$1"}}, "pic$1.jpg"},
+		{"case-sensitive replace", photo, 0, []RenameRule{{Type: RenameRuleReplace, Find: "PHOTO", Replace: "pic", CaseSensitive: true}}, "photo.jpg"},
+		{"regex replace with groups", photo, 0, []RenameRule{{Type: RenameRuleReplace, Find: `^(p)(h)`, Replace: "$2$1", Regex: true}}, "hpoto.jpg"},
+		{"replace leaves the extension", photo, 0, []RenameRule{{Type: RenameRuleReplace, Find: "jpg", Replace: "png"}}, "photo.jpg"},
+		{"replace including the extension", photo, 0, []RenameRule{{Type: RenameRuleReplace, Find: "jpg", Replace: "png", IncludeExtension: true}}, "photo.png"},
+		{"insert prefix", photo, 0, []RenameRule{{Type: RenameRuleInsert, Text: "new", Separator: "-"}}, "new-photo.jpg"},
+		{"insert suffix", photo, 0, []RenameRule{{Type: RenameRuleInsert, Text: "v2", Position: "suffix", Separator: "_"}}, "photo_v2.jpg"},
+		{"padded counter steps by index", photo, 3, []RenameRule{{Type: RenameRuleCounter, Start: 10, Step: 5, Padding: 3, Position: "suffix", Separator: " "}}, "photo 025.jpg"},
+		{"counter defaults to step 1", photo, 2, []RenameRule{{Type: RenameRuleCounter, Start: 1}}, "3photo.jpg"},
+		{"upper case", photo, 0, []RenameRule{{Type: RenameRuleCase, Case: "upper"}}, "PHOTO.jpg"},
+		{"title case", folder, 0, []RenameRule{{Type: RenameRuleCase, Case: "title"}}, "My.Folder"},
+		{"sentence case", photo, 0, []RenameRule{{Type: RenameRuleInsert, Text: "HOLIDAY", Separator: " "}, {Type: RenameRuleCase, Case: "sentence"}}, "Holiday photo.jpg"},
+		{"change extension", photo, 0, []RenameRule{{Type: RenameRuleExtension, Extension: ".JPEG"}}, "photo.JPEG"},
+		{"remove extension", photo, 0, []RenameRule{{Type: RenameRuleExtension}}, "photo"},
+		{"directories keep their dots", folder, 0, []RenameRule{{Type: RenameRuleExtension, Extension: "txt"}}, "My.Folder"},
+		{"modification date", photo, 0, []RenameRule{{Type: RenameRuleDate, Separator: "_"}}, "2023-07-08_photo.jpg"},
+		{"date tokens", photo, 0, []RenameRule{{Type: RenameRuleDate, Format: "YYMMDD-hhmmss", Position: "suffix", Separator: "@"}}, "photo@230708-091011.jpg"},
+		{"EXIF date", photo, 0, []RenameRule{{Type: RenameRuleDate, Source: "exif", Format: "YYYYMMDD", Separator: "_"}}, "20210304_photo.jpg"},
+		{"EXIF date falls back to the modification date", folder, 0, []RenameRule{{Type: RenameRuleDate, Source: "exif", Format: "YYYY"}}, "2023My.Folder"},
+	}
+	if err := os.Chtimes(folder, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := os.Lstat(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := applyRenameRules(tt.path, info, tt.index, tt.rules)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	info, _ := os.Lstat(photo)
+	for _, rules := range [][]RenameRule{
+		{{Type: RenameRuleReplace, Find: "(", Regex: true}},
+		{{Type: "shuffle"}},
+	} {
+		if got, err := applyRenameRules(photo, info, 0, rules); err == nil {
+			t.Errorf("rules %+v gave %q, want an error", rules, got)
+		}
+	}
+}
+
+func TestFormatRenameDate(t *testing.T) {
+	at := time.Date(2009, 2, 3, 4, 5, 6, 0, time.UTC)
+	tests := map[string]string{
+		"YYYY-MM-DD":     "2009-02-03",
+		"YY.MM.DD hh.mm": "09.02.03 04.05",
+		"DD-MM-YYYY_ss":  "03-02-2009_06",
+		"no tokens":      "no tokens",
+		"YYYYY":          "2009Y",
+		"hhhmmmsss":      "04h05m06s",
+		"literal MMM-dd": "literal 02M-dd",
+	}
+	for format, want := range tests {
+		if got := formatRenameDate(at, format); got != want {
+			t.Errorf("formatRenameDate(%q) = %q, want %q", format, got, want)
+		}
+	}
+}
+
+func TestPreviewMarksConflicts(t *testing.T) {
+	dir := t.TempDir()
+	paths := writeRenameFiles(t, dir, "a.txt", "b.txt", "c.txt", "keep.txt")
+
+	tests := []struct {
+		name      string
+		paths     []string
+		rules     []RenameRule
+		conflicts []string // old names of the items marked as conflicts
+		errors    int
+	}{
+		{
+			name:      "two items to one name",
+			paths:     paths[:2],
+			rules:     []RenameRule{{Type: RenameRuleReplace, Find: `^[ab]$`, Replace: "x", Regex: true}},
+			conflicts: []string{"a.txt", "b.txt"},
+		},
+		{
+			name:      "onto an existing file",
+			paths:     paths[:1],
+			rules:     []RenameRule{{Type: RenameRuleReplace, Find: "a", Replace: "keep"}},
+			conflicts: []string{"a.txt"},
+		},
+		{
+			name:  "onto a file renamed away in the same batch",
+			paths: paths[:2],
+			rules: []RenameRule{{Type: RenameRuleReplace, Find: "b", Replace: "new"}, {Type: RenameRuleReplace, Find: "a", Replace: "b"}},
+		},
+		{
+			name:  "swap",
+			paths: paths[:2],
+			rules: []RenameRule{{Type: RenameRuleReplace, Find: "a", Replace: "x"}, {Type: RenameRuleReplace, Find: "b", Replace: "a"}, {Type: RenameRuleReplace, Find: "x", Replace: "b"}},
+		},
+		{
+			name:   "invalid names",
+			paths:  paths[:2],
+			rules:  []RenameRule{{Type: RenameRuleInsert, Text: "sub/"}},
+			errors: 2,
+		},
+	}
+	b := NewBatchRenamer(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preview := b.Preview(tt.paths, tt.rules)
+			var conflicts []string
+			for _, item := range preview.Items {
+				if item.Conflict {
+					conflicts = append(conflicts, item.OldName)
+				}
+			}
+			if !slices.Equal(conflicts, tt.conflicts) || preview.Conflicts != len(tt.conflicts) || preview.Errors != tt.errors {
+				t.Errorf("conflicts %v (%d), errors %d; want %v, %d errors", conflicts, preview.Conflicts, preview.Errors, tt.conflicts, tt.errors)
+			}
+			if preview.Valid != (len(tt.conflicts) == 0 && tt.errors == 0) {
+				t.Errorf("Valid = %v", preview.Valid)
+			}
+		})
+	}
+
+	// Previews never touch the filesystem
+	if got := dirContents(t, dir); len(got) != 4 || got["a.txt"] != "a.txt" {
+		t.Errorf("preview changed the directory: %v", got)
+	}
+}
+
+func TestApplySwapAndUndo(t *testing.T) {
+	dir := t.TempDir()
+	paths := writeRenameFiles(t, dir, "a.txt", "b.txt")
+	swap := []RenameRule{{Type: RenameRuleReplace, Find: "a", Replace: "x"}, {Type: RenameRuleReplace, Find: "b", Replace: "a"}, {Type: RenameRuleReplace, Find: "x", Replace: "b"}}
+
+	b := NewBatchRenamer(nil)
+	result := b.Apply(paths, swap)
+	if !result.Success || result.Renamed != 2 || result.UndoID == "" {
+		t.Fatalf("Apply = %+v", result)
+	}
+	if got := dirContents(t, dir); got["a.txt"] != "b.txt" || got["b.txt"] != "a.txt" || len(got) != 2 {
+		t.Fatalf("after swap: %v", got)
+	}
+
+	if undo := b.Undo(result.UndoID); !undo.Success || undo.Renamed != 2 {
+		t.Fatalf("Undo = %+v", undo)
+	}
+	if got := dirContents(t, dir); got["a.txt"] != "a.txt" || got["b.txt"] != "b.txt" || len(got) != 2 {
+		t.Fatalf("after undo: %v", got)
+	}
+	if undo := b.Undo(""); undo.Success {
+		t.Errorf("second Undo = %+v, want nothing to undo", undo)
+	}
+}
+
+func TestApplyRefusesInvalidBatch(t *testing.T) {
+	dir := t.TempDir()
+	paths := writeRenameFiles(t, dir, "a.txt", "b.txt")
+
+	result := NewBatchRenamer(nil).Apply(paths, []RenameRule{{Type: RenameRuleReplace, Find: `^[ab]$`, Replace: "x", Regex: true}})
+	if result.Success || result.Renamed != 0 {
+		t.Fatalf("Apply = %+v, want it refused", result)
+	}
+	if got := dirContents(t, dir); got["a.txt"] != "a.txt" || got["b.txt"] != "b.txt" || len(got) != 2 {
+		t.Errorf("refused batch changed the directory: %v", got)
+	}
+}
+
+func TestUndoRefusesWhenRenamedFileIsGone(t *testing.T) {
+	dir := t.TempDir()
+	paths := writeRenameFiles(t, dir, "a.txt")
+	b := NewBatchRenamer(nil)
+	result := b.Apply(paths, []RenameRule{{Type: RenameRuleCase, Case: "upper"}})
+	if !result.Success {
+		t.Fatalf("Apply = %+v", result)
+	}
+	if err := os.Remove(filepath.Join(dir, "A.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if undo := b.Undo(result.UndoID); undo.Success || !strings.Contains(undo.Message, "no longer exists") {
+		t.Errorf("Undo = %+v, want it refused", undo)
+	}
+}
+
+func TestRunRenameStepsRollsBack(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(dir string) []renameStep
+	}{
+		{
+			name: "missing source",
+			setup: func(dir string) []renameStep {
+				return []renameStep{
+					{filepath.Join(dir, "a"), filepath.Join(dir, "c")},
+					{filepath.Join(dir, "missing"), filepath.Join(dir, "d")},
+				}
+			},
+		},
+		{
+			name: "destination appears",
+			setup: func(dir string) []renameStep {
+				os.WriteFile(filepath.Join(dir, "d"), []byte("d"), 0o644)
+				return []renameStep{
+					{filepath.Join(dir, "a"), filepath.Join(dir, "c")},
+					{filepath.Join(dir, "b"), filepath.Join(dir, "d")},
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeRenameFiles(t, dir, "a", "b")
+			steps := tt.setup(dir)
+			before := dirContents(t, dir)
+
+			if err := runRenameSteps(steps); err == nil {
+				t.Fatal("runRenameSteps succeeded")
+			}
+			// Everything is back under its old name and no temporary names are left
+			if got := dirContents(t, dir); !maps.Equal(got, before) {
+				t.Errorf("after rollback: %v, want %v", got, before)
+			}
+		})
+	}
+}
@@ -0,0 +1,518 @@
+package backend
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// CLI exit codes, mapped from operation errors so scripts can branch on them
+const (
+	ExitOK           = 0
+	ExitFailure      = 1
+	ExitUsage        = 2
+	ExitNotFound     = 3
+	ExitPermission   = 4
+	ExitExists       = 5
+	ExitBlocked      = 6 // refused by the protected-path policy
+	ExitConfirm      = 7 // the policy requires a confirmation the CLI cannot give
+	ExitInterrupted  = 130
+	cliDefaultFormat = "table"
+)
+
+// cliCommands maps subcommand names to their handlers
+var cliCommands = map[string]func(c *cliContext, args []string) int{
+	"ls":   (*cliContext).ls,
+	"cp":   (*cliContext).cp,
+	"mv":   (*cliContext).mv,
+	"rm":   (*cliContext).rm,
+	"find": (*cliContext).find,
+	"du":   (*cliContext).du,
+	"hash": (*cliContext).hash,
+}
+
+// cliContext holds the headless backend shared by every subcommand
+type cliContext struct {
+	stdout  io.Writer
+	stderr  io.Writer
+	fs      *FileSystemManager
+	fileOps *FileOperationsManager
+	jobs    *JobManager
+	json    bool
+}
+
+// cliVerb introduces the headless subcommands, so that a folder named like one
+// of them ("lightning-explorer ls") still opens in the app
+const cliVerb = "cli"
+
+// IsCLICommand reports whether args (without the program name) ask for the
+// headless CLI, i.e. start with "cli"
+func IsCLICommand(args []string) bool {
+	return len(args) > 0 && args[0] == cliVerb
+}
+
+// RunCLI executes a headless subcommand and returns the process exit code.
+// args are the program arguments, starting with "cli".
+func RunCLI(args []string, stdout, stderr io.Writer) int {
+	if len(args) > 0 && args[0] == cliVerb {
+		args = args[1:]
+	}
+	if len(args) == 0 || args[0] == "help" || args[0] == "--help" {
+		printCLIUsage(stdout)
+		return ExitOK
+	}
+	handler, ok := cliCommands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command: %s\n", args[0])
+		printCLIUsage(stderr)
+		return ExitUsage
+	}
+
+	// Backend diagnostics would interleave with command output
+	log.SetOutput(io.Discard)
+
+	// Honour the folders the user protected in the app
+	settings, err := readSettingsFile(settingsFilePath())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		fmt.Fprintln(stderr, "error: cannot read settings:", err)
+		return ExitFailure
+	}
+
+	platform := NewPlatformManager()
+	c := &cliContext{
+		stdout:  stdout,
+		stderr:  stderr,
+		fs:      NewFileSystemManager(platform),
+		fileOps: NewFileOperationsManager(platform),
+		jobs:    NewJobManager(),
+	}
+	c.fileOps.policy.SetUserRules(settings.ProtectedPaths)
+	c.jobs.SetListener(func(info JobInfo) {
+		if !c.json && info.Status == JobStatusRunning && info.Total > 0 {
+			fmt.Fprintf(c.stderr, "%s: %d/%d\n", info.Kind, info.Done, info.Total)
+		}
+	})
+	return handler(c, args[1:])
+}
+
+func printCLIUsage(w io.Writer) {
+	fmt.Fprint(w, `Usage: lightning-explorer cli <command> [flags] [args]
+
+Commands:
+  ls   [--all] [--format table|json] <dir>
+  cp   [--archive] [--format table|json] <src>... <dest-dir>
+  mv   [--archive] [--format table|json] <src>... <dest-dir>
+  rm   [--permanent] [--format table|json] <path>...
+  find [--limit N] [--format table|json] <root> <query>
+  du   [--format table|json] <path>...
+  hash [--algo sha256|sha1|md5] [--format table|json] <file>...
+
+Exit codes: 0 ok, 1 failure, 2 usage, 3 not found, 4 permission denied, 5 already exists,
+            6 blocked by protected-path policy, 7 needs confirmation in the app, 130 interrupted
+`)
+}
+
+// flags creates a FlagSet with the shared --format flag
+func (c *cliContext) flags(name string) (*flag.FlagSet, *string) {
+	set := flag.NewFlagSet(name, flag.ContinueOnError)
+	set.SetOutput(c.stderr)
+	format := set.String("format", cliDefaultFormat, "output format: table or json")
+	return set, format
+}
+
+func (c *cliContext) parse(set *flag.FlagSet, format *string, args []string, minArgs int) bool {
+	if err := set.Parse(args); err != nil {
+		return false
+	}
+	if *format != "table" && *format != "json" {
+		fmt.Fprintf(c.stderr, "unknown format: %s\n", *format)
+		return false
+	}
+	c.json = *format == "json"
+	if set.NArg() < minArgs {
+		fmt.Fprintf(c.stderr, "%s: expected at least %d argument(s)\n", set.Name(), minArgs)
+		return false
+	}
+	return true
+}
+
+// fail prints err and maps it to an exit code
+func (c *cliContext) fail(err error) int {
+	fmt.Fprintln(c.stderr, "error:", err)
+	return exitCodeFor(err)
+}
+
+func exitCodeFor(err error) int {
+	var policyErr *PolicyError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &policyErr):
+		if policyErr.Decision.Action == PolicyConfirm {
+			return ExitConfirm
+		}
+		return ExitBlocked
+	case errors.Is(err, fs.ErrNotExist):
+		return ExitNotFound
+	case errors.Is(err, fs.ErrPermission):
+		return ExitPermission
+	case errors.Is(err, fs.ErrExist):
+		return ExitExists
+	default:
+		return ExitFailure
+	}
+}
+
+func (c *cliContext) printJSON(v interface{}) {
+	enc := json.NewEncoder(c.stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func (c *cliContext) ls(args []string) int {
+	set, format := c.flags("ls")
+	all := set.Bool("all", false, "include hidden files")
+	if !c.parse(set, format, args, 0) {
+		return ExitUsage
+	}
+	dir := set.Arg(0)
+	if dir == "" {
+		dir = "."
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return c.fail(err)
+	}
+
+	c.fs.SetShowHidden(*all)
+	resp := c.fs.ListDirectory(dir)
+	if !resp.Success {
+		return c.fail(errors.New(resp.Message))
+	}
+
+	entries := append(append([]FileInfo{}, resp.Data.Directories...), resp.Data.Files...)
+	if c.json {
+		c.printJSON(entries)
+		return ExitOK
+	}
+	tw := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	for _, e := range entries {
+		size := c.fs.platform.FormatFileSize(e.Size)
+		name := e.Name
+		if e.IsDir {
+			size = "-"
+			name += string(filepath.Separator)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", e.Permissions, size, time.Unix(e.ModTime, 0).Format("2006-01-02 15:04"), name)
+	}
+	tw.Flush()
+	return ExitOK
+}
+
+func (c *cliContext) cp(args []string) int {
+	return c.transfer("cp", "copy", args, c.fileOps.CopyFilesWithOptions)
+}
+
+func (c *cliContext) mv(args []string) int {
+	return c.transfer("mv", "move", args, c.fileOps.MoveFilesWithOptions)
+}
+
+// transfer runs a copy or move through the job system, after the same
+// preflight checks the managers perform so failures map to precise exit codes.
+// --archive preserves all metadata, like cp -a.
+func (c *cliContext) transfer(name, kind string, args []string, op func(context.Context, []string, string, CopyOptions) bool) int {
+	set, format := c.flags(name)
+	archive := set.Bool("archive", false, "preserve times, ownership, xattrs, ACLs, symlinks, hardlinks and sparse files")
+	set.BoolVar(archive, "a", false, "shorthand for --archive")
+	if !c.parse(set, format, args, 2) {
+		return ExitUsage
+	}
+	var opts CopyOptions
+	if *archive {
+		opts = archiveCopyOptions()
+	}
+	paths := absPaths(set.Args())
+	sources, dest := paths[:len(paths)-1], paths[len(paths)-1]
+
+	if err := preflightTransfer(sources, dest); err != nil {
+		return c.fail(err)
+	}
+	// The managers refuse the same way, but only report a bare failure
+	targets := transferTargets(sources, dest)
+	if kind == OpMove {
+		targets = append(append([]string{}, sources...), targets...)
+	}
+	if err := c.fileOps.policy.Check(kind, targets...); err != nil {
+		return c.fail(err)
+	}
+
+	id := c.jobs.Start(kind, func(ctx context.Context, job *Job) error {
+		finish, err := trackTransfer(ctx, job, sources, &opts)
+		if err != nil {
+			return err
+		}
+		opts.Stats = NewCopyStats()
+		ok := op(ctx, sources, dest, opts)
+		opts.Stats.recordIn(job)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%s failed (changes were rolled back)", kind)
+		}
+		finish()
+		return nil
+	})
+	return c.finishJob(id)
+}
+
+// finishJob waits for a job, cancelling it on Ctrl-C, and prints its result
+func (c *cliContext) finishJob(id string) int {
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-interrupts:
+			c.jobs.Cancel(id)
+		case <-done:
+		}
+	}()
+
+	info, _ := c.jobs.Wait(id)
+	if c.json {
+		c.printJSON(info)
+	} else {
+		if strategy, ok := info.Result["strategy"]; ok {
+			fmt.Fprintf(c.stdout, "%s\t%s\t%d/%d\t%v\n", info.ID, info.Status, info.Done, info.Total, strategy)
+		} else {
+			fmt.Fprintf(c.stdout, "%s\t%s\t%d/%d\n", info.ID, info.Status, info.Done, info.Total)
+		}
+	}
+
+	switch info.Status {
+	case JobStatusCompleted:
+		return ExitOK
+	case JobStatusCancelled:
+		return ExitInterrupted
+	default:
+		fmt.Fprintln(c.stderr, "error:", info.Error)
+		return ExitFailure
+	}
+}
+
+func preflightTransfer(sources []string, dest string) error {
+	destInfo, err := os.Stat(dest)
+	if err != nil {
+		return err
+	}
+	if !destInfo.IsDir() {
+		return fmt.Errorf("destination is not a directory: %s", dest)
+	}
+	for _, src := range sources {
+		if _, err := os.Stat(src); err != nil {
+			return err
+		}
+		target := filepath.Join(dest, filepath.Base(src))
+		if _, err := os.Stat(target); err == nil {
+			return &fs.PathError{Op: "copy", Path: target, Err: fs.ErrExist}
+		}
+	}
+	return nil
+}
+
+func (c *cliContext) rm(args []string) int {
+	set, format := c.flags("rm")
+	permanent := set.Bool("permanent", false, "delete permanently instead of moving to trash")
+	if !c.parse(set, format, args, 1) {
+		return ExitUsage
+	}
+	paths := absPaths(set.Args())
+	for _, p := range paths {
+		if _, err := os.Lstat(p); err != nil {
+			return c.fail(err)
+		}
+	}
+	op := OpRecycle
+	if *permanent {
+		op = OpDelete
+	}
+	if err := c.fileOps.policy.Check(op, paths...); err != nil {
+		return c.fail(err)
+	}
+
+	if *permanent {
+		ok := c.fileOps.DeleteFiles(paths)
+		if c.json {
+			c.printJSON(map[string]interface{}{"success": ok, "paths": paths, "permanent": true})
+		}
+		if !ok {
+			fmt.Fprintln(c.stderr, "error: delete failed")
+			return ExitFailure
+		}
+		return ExitOK
+	}
+
+	result := c.fileOps.RecycleFiles(paths)
+	if c.json {
+		c.printJSON(result)
+	}
+	if !result.Success {
+		for _, p := range result.Failed {
+			fmt.Fprintln(c.stderr, "not trashed:", p)
+		}
+		fmt.Fprintln(c.stderr, "error: trash unavailable; nothing was deleted permanently (use --permanent to force)")
+		return ExitFailure
+	}
+	return ExitOK
+}
+
+func (c *cliContext) find(args []string) int {
+	set, format := c.flags("find")
+	limit := set.Int("limit", defaultSearchLimit, "maximum number of results")
+	if !c.parse(set, format, args, 2) {
+		return ExitUsage
+	}
+	root, err := filepath.Abs(set.Arg(0))
+	if err != nil {
+		return c.fail(err)
+	}
+	if _, err := os.Stat(root); err != nil {
+		return c.fail(err)
+	}
+
+	c.fs.SetShowHidden(true)
+	results, err := c.fs.SearchFiles(root, set.Arg(1), *limit)
+	if err != nil {
+		return c.fail(err)
+	}
+	if c.json {
+		c.printJSON(results)
+		return ExitOK
+	}
+	for _, r := range results {
+		fmt.Fprintln(c.stdout, r.Path)
+	}
+	return ExitOK
+}
+
+type cliUsage struct {
+	Path  string `json:"path"`
+	Bytes int64  `json:"bytes"`
+	Files int64  `json:"files"`
+	Dirs  int64  `json:"dirs"`
+}
+
+func (c *cliContext) du(args []string) int {
+	set, format := c.flags("du")
+	if !c.parse(set, format, args, 1) {
+		return ExitUsage
+	}
+
+	var usages []cliUsage
+	for _, p := range absPaths(set.Args()) {
+		usage := cliUsage{Path: p}
+		err := filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				usage.Dirs++
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				usage.Bytes += info.Size()
+			}
+			usage.Files++
+			return nil
+		})
+		if err != nil {
+			return c.fail(err)
+		}
+		usages = append(usages, usage)
+	}
+
+	if c.json {
+		c.printJSON(usages)
+		return ExitOK
+	}
+	tw := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	for _, u := range usages {
+		fmt.Fprintf(tw, "%s\t%d files\t%d dirs\t%s\n", c.fs.platform.FormatFileSize(u.Bytes), u.Files, u.Dirs, u.Path)
+	}
+	tw.Flush()
+	return ExitOK
+}
+
+func (c *cliContext) hash(args []string) int {
+	set, format := c.flags("hash")
+	algo := set.String("algo", "sha256", "hash algorithm: sha256, sha1 or md5")
+	if !c.parse(set, format, args, 1) {
+		return ExitUsage
+	}
+
+	newHash := map[string]func() hash.Hash{"sha256": sha256.New, "sha1": sha1.New, "md5": md5.New}[strings.ToLower(*algo)]
+	if newHash == nil {
+		fmt.Fprintf(c.stderr, "unknown algorithm: %s\n", *algo)
+		return ExitUsage
+	}
+
+	results := make(map[string]string)
+	var order []string
+	for _, p := range absPaths(set.Args()) {
+		f, err := os.Open(p)
+		if err != nil {
+			return c.fail(err)
+		}
+		h := newHash()
+		buffer := bufferPool.Get().([]byte)
+		_, err = io.CopyBuffer(h, f, buffer)
+		bufferPool.Put(buffer)
+		f.Close()
+		if err != nil {
+			return c.fail(err)
+		}
+		results[p] = hex.EncodeToString(h.Sum(nil))
+		order = append(order, p)
+	}
+
+	if c.json {
+		c.printJSON(results)
+		return ExitOK
+	}
+	for _, p := range order {
+		fmt.Fprintf(c.stdout, "%s  %s\n", results[p], p)
+	}
+	return ExitOK
+}
+
+func absPaths(paths []string) []string {
+	out := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if abs, err := filepath.Abs(p); err == nil {
+			p = abs
+		}
+		out = append(out, p)
+	}
+	return out
+}
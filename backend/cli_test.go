@@ -0,0 +1,82 @@
+package backend
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExitCodeFor(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, ExitOK},
+		{"generic", errors.New("boom"), ExitFailure},
+		{"not found", fs.ErrNotExist, ExitNotFound},
+		{"wrapped not found", &os.PathError{Op: "stat", Path: "/x", Err: fs.ErrNotExist}, ExitNotFound},
+		{"permission", fmt.Errorf("open: %w", fs.ErrPermission), ExitPermission},
+		{"exists", &os.PathError{Op: "copy", Path: "/x", Err: fs.ErrExist}, ExitExists},
+		{"policy deny", &PolicyError{Decision: PolicyDecision{Action: PolicyDeny}}, ExitBlocked},
+		{"policy confirm", &PolicyError{Decision: PolicyDecision{Action: PolicyConfirm}}, ExitConfirm},
+		{"wrapped policy", fmt.Errorf("rm: %w", &PolicyError{Decision: PolicyDecision{Action: PolicyDeny}}), ExitBlocked},
+	}
+	for _, tt := range tests {
+		if got := exitCodeFor(tt.err); got != tt.want {
+			t.Errorf("%s: exitCodeFor(%v) = %d, want %d", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestIsCLICommand(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{nil, false},
+		{[]string{"cli", "ls"}, true},
+		{[]string{"cli"}, true},
+		// A folder named like a subcommand opens in the app
+		{[]string{"ls"}, false},
+		{[]string{"--", "cli"}, false},
+		{[]string{"/home/user/cli"}, false},
+	}
+	for _, tt := range tests {
+		if got := IsCLICommand(tt.args); got != tt.want {
+			t.Errorf("IsCLICommand(%q) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestCLIHonoursSavedProtectedPaths(t *testing.T) {
+	config := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", config)
+	t.Setenv("HOME", config)
+
+	dir := t.TempDir()
+	protected := filepath.Join(dir, "protected")
+	if err := os.Mkdir(protected, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	settings := `{"protectedPaths":[{"path":` + fmt.Sprintf("%q", protected) + `,"action":"deny"}]}`
+	path := settingsFilePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(settings), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := RunCLI([]string{"cli", "rm", "--permanent", protected}, &stdout, &stderr); code != ExitBlocked {
+		t.Fatalf("rm of a protected folder exited %d, want %d (%s)", code, ExitBlocked, stderr.String())
+	}
+	if _, err := os.Stat(protected); err != nil {
+		t.Fatalf("protected folder was removed: %v", err)
+	}
+}
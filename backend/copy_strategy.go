@@ -0,0 +1,129 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Strategies for copying file contents. CopyStrategyAuto tries the kernel
+// paths in order and falls back to the userspace buffer loop.
+const (
+	CopyStrategyAuto          = "auto"
+	CopyStrategyCopyFileRange = "copy_file_range"
+	CopyStrategyReflink       = "reflink"
+	CopyStrategySendfile      = "sendfile"
+	CopyStrategyBuffer        = "buffer"
+	CopyStrategySparse        = "sparse"
+)
+
+// errStrategyUnsupported reports that a forced strategy cannot copy this file
+type errStrategyUnsupported struct {
+	strategy string
+	err      error
+}
+
+func (e *errStrategyUnsupported) Error() string {
+	return fmt.Sprintf("copy strategy %s is not supported here: %v", e.strategy, e.err)
+}
+
+func (e *errStrategyUnsupported) Unwrap() error {
+	return e.err
+}
+
+// CopyStats records which strategies copied how many files and bytes during
+// one operation. It is safe for concurrent use.
+type CopyStats struct {
+	mu    sync.Mutex
+	files map[string]int
+	bytes map[string]int64
+}
+
+// NewCopyStats creates empty copy statistics
+func NewCopyStats() *CopyStats {
+	return &CopyStats{files: make(map[string]int), bytes: make(map[string]int64)}
+}
+
+func (s *CopyStats) record(strategy string, size int64) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.files[strategy]++
+	s.bytes[strategy] += size
+	s.mu.Unlock()
+}
+
+// Files returns the number of files copied per strategy
+func (s *CopyStats) Files() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	files := make(map[string]int, len(s.files))
+	for k, v := range s.files {
+		files[k] = v
+	}
+	return files
+}
+
+// Primary returns the strategy that copied the most bytes, or "" if nothing was copied
+func (s *CopyStats) Primary() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.bytes))
+	for name := range s.bytes {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if s.bytes[names[i]] != s.bytes[names[j]] {
+			return s.bytes[names[i]] > s.bytes[names[j]]
+		}
+		return s.files[names[i]] > s.files[names[j]]
+	})
+	if len(names) == 0 {
+		return ""
+	}
+	return names[0]
+}
+
+// recordIn stores the strategy summary of a copy in a job result
+func (s *CopyStats) recordIn(job *Job) {
+	if primary := s.Primary(); primary != "" {
+		job.SetResult("strategy", primary)
+		job.SetResult("strategies", s.Files())
+	}
+}
+
+// copyWithBuffer is the portable userspace copy loop. It reads and writes
+// directly so the runtime cannot switch to a kernel path itself, reports each
+// chunk to progress and stops between chunks once ctx is cancelled.
+func copyWithBuffer(ctx context.Context, dst, src *os.File, buffer []byte, progress func(int64)) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, err := src.Read(buffer)
+		if n > 0 {
+			if _, werr := dst.Write(buffer[:n]); werr != nil {
+				return werr
+			}
+			progress(int64(n))
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func validCopyStrategy(strategy string) bool {
+	switch strategy {
+	case "", CopyStrategyAuto, CopyStrategyCopyFileRange, CopyStrategyReflink, CopyStrategySendfile, CopyStrategyBuffer:
+		return true
+	}
+	return false
+}
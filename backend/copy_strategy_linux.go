@@ -0,0 +1,128 @@
+//go:build linux
+
+package backend
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// maxKernelCopyChunk bounds each copy_file_range/sendfile call; cancellation is
+// checked and progress reported between chunks, so very large files stay responsive
+const maxKernelCopyChunk = 64 << 20
+
+// copyContents copies size bytes from src to dst. With the auto strategy it
+// tries copy_file_range, then a FICLONE reflink, then sendfile, and finally the
+// buffer loop; a forced strategy fails instead of falling back. It returns the
+// strategy that did the work. Progress is told the bytes copied after each chunk.
+func copyContents(ctx context.Context, dst, src *os.File, size int64, buffer []byte, strategy string, progress func(int64)) (string, error) {
+	auto := strategy == "" || strategy == CopyStrategyAuto
+	var copied int64
+
+	if auto || strategy == CopyStrategyCopyFileRange {
+		n, err := copyFileRange(ctx, dst, src, size, progress)
+		copied += n
+		switch {
+		case err == nil && copied == size:
+			return CopyStrategyCopyFileRange, nil
+		case err != nil && (ctx.Err() != nil || !kernelCopyUnsupported(err)):
+			return CopyStrategyCopyFileRange, err
+		case !auto:
+			return "", &errStrategyUnsupported{CopyStrategyCopyFileRange, errOrShort(err)}
+		}
+	}
+
+	// A reflink replaces the whole file, so it only applies before any bytes moved
+	if (auto && copied == 0) || strategy == CopyStrategyReflink {
+		err := unix.IoctlFileClone(int(dst.Fd()), int(src.Fd()))
+		switch {
+		case err == nil:
+			progress(size)
+			return CopyStrategyReflink, nil
+		case !auto:
+			return "", &errStrategyUnsupported{CopyStrategyReflink, err}
+		case !kernelCopyUnsupported(err):
+			return CopyStrategyReflink, err
+		}
+	}
+
+	if auto || strategy == CopyStrategySendfile {
+		n, err := sendfile(ctx, dst, src, size-copied, progress)
+		copied += n
+		switch {
+		case err == nil && copied == size:
+			return CopyStrategySendfile, nil
+		case err != nil && (ctx.Err() != nil || !kernelCopyUnsupported(err)):
+			return CopyStrategySendfile, err
+		case !auto:
+			return "", &errStrategyUnsupported{CopyStrategySendfile, errOrShort(err)}
+		}
+	}
+
+	// Both descriptors' offsets have advanced past anything copied so far
+	return CopyStrategyBuffer, copyWithBuffer(ctx, dst, src, buffer, progress)
+}
+
+// copyFileRange copies until size bytes moved, the source reports EOF, ctx is
+// cancelled or an error occurs
+func copyFileRange(ctx context.Context, dst, src *os.File, size int64, progress func(int64)) (int64, error) {
+	var copied int64
+	for copied < size {
+		if err := ctx.Err(); err != nil {
+			return copied, err
+		}
+		n, err := unix.CopyFileRange(int(src.Fd()), nil, int(dst.Fd()), nil, int(min(size-copied, maxKernelCopyChunk)), 0)
+		copied += int64(n)
+		if n > 0 {
+			progress(int64(n))
+		}
+		if err != nil {
+			return copied, err
+		}
+		if n == 0 {
+			break
+		}
+	}
+	return copied, nil
+}
+
+func sendfile(ctx context.Context, dst, src *os.File, remaining int64, progress func(int64)) (int64, error) {
+	var copied int64
+	for copied < remaining {
+		if err := ctx.Err(); err != nil {
+			return copied, err
+		}
+		n, err := unix.Sendfile(int(dst.Fd()), int(src.Fd()), nil, int(min(remaining-copied, maxKernelCopyChunk)))
+		copied += int64(n)
+		if n > 0 {
+			progress(int64(n))
+		}
+		if err != nil {
+			return copied, err
+		}
+		if n == 0 {
+			break
+		}
+	}
+	return copied, nil
+}
+
+// kernelCopyUnsupported reports errors meaning "use another strategy" rather than an I/O failure
+func kernelCopyUnsupported(err error) bool {
+	return errors.Is(err, unix.ENOSYS) || errors.Is(err, unix.EXDEV) || errors.Is(err, unix.EINVAL) ||
+		errors.Is(err, unix.EOPNOTSUPP) || errors.Is(err, unix.ENOTTY) || errors.Is(err, unix.EBADF) ||
+		errors.Is(err, unix.EPERM)
+}
+
+// errOrShort turns a short kernel copy (e.g. procfs files that report a size
+// but copy nothing) into an error for forced strategies
+func errOrShort(err error) error {
+	if err != nil {
+		return err
+	}
+	return io.ErrUnexpectedEOF
+}
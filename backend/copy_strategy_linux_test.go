@@ -0,0 +1,234 @@
+//go:build linux
+
+package backend
+
+import (
+	"bytes"
+	"context"
+	crand "crypto/rand"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// copyWithStrategy copies src into a new file with copyContents, returning the
+// strategy used, the copied bytes and the total reported to progress
+func copyWithStrategy(t *testing.T, ctx context.Context, src *os.File, size int64, strategy string) (string, []byte, int64, error) {
+	t.Helper()
+	dstPath := filepath.Join(t.TempDir(), "dst")
+	dst, err := os.Create(dstPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dst.Close()
+
+	var reported int64
+	used, err := copyContents(ctx, dst, src, size, make([]byte, copyBufferSize), strategy, func(n int64) { reported += n })
+	if err != nil {
+		return used, nil, reported, err
+	}
+	data, readErr := os.ReadFile(dstPath)
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	return used, data, reported, nil
+}
+
+func TestCopyContentsStrategiesMatch(t *testing.T) {
+	dir := t.TempDir()
+	if err := writeBenchFiles(dir, 1, 3*copyBufferSize+123); err != nil {
+		t.Fatal(err)
+	}
+	srcPath := filepath.Join(dir, "file-00000.bin")
+	want, err := os.ReadFile(srcPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, strategy := range benchStrategies {
+		t.Run(strategy, func(t *testing.T) {
+			src, err := os.Open(srcPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer src.Close()
+
+			used, got, reported, err := copyWithStrategy(t, context.Background(), src, int64(len(want)), strategy)
+			var unsupported *errStrategyUnsupported
+			if errors.As(err, &unsupported) {
+				t.Skipf("%s is not supported on this filesystem or kernel: %v", strategy, err)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strategy != CopyStrategyAuto && used != strategy {
+				t.Errorf("copied with %s, want %s", used, strategy)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s copy differs from the source (%d bytes, want %d)", used, len(got), len(want))
+			}
+			if reported != int64(len(want)) {
+				t.Errorf("progress reported %d bytes, want %d", reported, len(want))
+			}
+		})
+	}
+}
+
+// A pipe supports none of the kernel strategies, so auto must end in the buffer
+// loop with every byte intact while forced kernel strategies fail
+func TestCopyContentsAutoFallsBack(t *testing.T) {
+	want := bytes.Repeat([]byte("lightning "), 10000)
+	pipeSource := func(t *testing.T) *os.File {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { r.Close() })
+		go func() {
+			w.Write(want)
+			w.Close()
+		}()
+		return r
+	}
+
+	used, got, reported, err := copyWithStrategy(t, context.Background(), pipeSource(t), int64(len(want)), CopyStrategyAuto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if used != CopyStrategyBuffer {
+		t.Errorf("auto copied from a pipe with %s, want %s", used, CopyStrategyBuffer)
+	}
+	if !bytes.Equal(got, want) || reported != int64(len(want)) {
+		t.Errorf("copied %d bytes and reported %d, want %d", len(got), reported, len(want))
+	}
+
+	for _, strategy := range []string{CopyStrategyCopyFileRange, CopyStrategyReflink, CopyStrategySendfile} {
+		_, _, _, err := copyWithStrategy(t, context.Background(), pipeSource(t), int64(len(want)), strategy)
+		var unsupported *errStrategyUnsupported
+		if !errors.As(err, &unsupported) {
+			t.Errorf("forced %s from a pipe: got %v, want errStrategyUnsupported", strategy, err)
+		}
+	}
+}
+
+func TestCopyContentsCancelled(t *testing.T) {
+	srcPath := filepath.Join(t.TempDir(), "src")
+	if err := os.WriteFile(srcPath, bytes.Repeat([]byte{1}, copyBufferSize), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// A reflink is a single atomic call, so there is no chunk to stop between
+	for _, strategy := range []string{CopyStrategyAuto, CopyStrategyCopyFileRange, CopyStrategySendfile, CopyStrategyBuffer} {
+		src, err := os.Open(srcPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, reported, err := copyWithStrategy(t, ctx, src, copyBufferSize, strategy)
+		src.Close()
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("%s with a cancelled context: got %v, want context.Canceled", strategy, err)
+		}
+		if reported != 0 {
+			t.Errorf("%s reported %d bytes after cancellation", strategy, reported)
+		}
+	}
+}
+
+// Run with -benchtime=Nx and TMPDIR on the filesystem you care about: reflink
+// only works within btrfs, XFS and similar, so it is skipped elsewhere.
+//
+//	go test ./backend -run '^$' -bench BenchmarkCopy -benchtime 5x
+
+const (
+	benchLargeFileSize = 256 << 20
+	benchSmallFiles    = 2000
+	benchSmallFileSize = 4 << 10
+)
+
+var benchStrategies = []string{CopyStrategyAuto, CopyStrategyCopyFileRange, CopyStrategyReflink, CopyStrategySendfile, CopyStrategyBuffer}
+
+func BenchmarkCopyLargeFile(b *testing.B) {
+	benchmarkCopyStrategies(b, 1, benchLargeFileSize)
+}
+
+func BenchmarkCopySmallFiles(b *testing.B) {
+	benchmarkCopyStrategies(b, benchSmallFiles, benchSmallFileSize)
+}
+
+// benchmarkCopyStrategies copies a directory of count files of size bytes once
+// per iteration with each strategy, reporting the strategy that actually moved
+// the data, which differs from the requested one under auto
+func benchmarkCopyStrategies(b *testing.B, count int, size int64) {
+	src := filepath.Join(b.TempDir(), "src")
+	if err := writeBenchFiles(src, count, size); err != nil {
+		b.Fatal(err)
+	}
+
+	for _, strategy := range benchStrategies {
+		b.Run(strategy, func(b *testing.B) {
+			fo := &FileOperationsManager{}
+			b.SetBytes(int64(count) * size)
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				dest := filepath.Join(b.TempDir(), "dest")
+				if err := os.Mkdir(dest, 0o755); err != nil {
+					b.Fatal(err)
+				}
+				stats := NewCopyStats()
+				b.StartTimer()
+
+				if !fo.CopyFilesWithOptions(context.Background(), []string{src}, dest, CopyOptions{Strategy: strategy, Stats: stats}) {
+					b.Skipf("%s is not supported on this filesystem or kernel", strategy)
+				}
+
+				b.StopTimer()
+				if i == 0 {
+					b.Logf("copied with %s", stats.Primary())
+				}
+				os.RemoveAll(dest)
+				b.StartTimer()
+			}
+		})
+	}
+}
+
+// writeBenchFiles fills dir with count files of random, incompressible data
+func writeBenchFiles(dir string, count int, size int64) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	var seed [32]byte
+	if _, err := crand.Read(seed[:]); err != nil {
+		return err
+	}
+	random := rand.NewChaCha8(seed)
+	buffer := make([]byte, copyBufferSize)
+
+	for i := 0; i < count; i++ {
+		f, err := os.Create(filepath.Join(dir, fmt.Sprintf("file-%05d.bin", i)))
+		if err != nil {
+			return err
+		}
+		for written := int64(0); written < size; {
+			chunk := buffer[:min(int64(len(buffer)), size-written)]
+			random.Read(chunk)
+			n, err := f.Write(chunk)
+			written += int64(n)
+			if err != nil {
+				f.Close()
+				return err
+			}
+		}
+		// Flush now so writeback of the fixture is not charged to the first strategy
+		f.Sync()
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+//go:build !linux
+
+package backend
+
+import (
+	"context"
+	"errors"
+	"os"
+)
+
+var errNoKernelCopy = errors.New("kernel-assisted copy is only available on Linux")
+
+// copyContents copies src to dst with the buffer loop; the kernel strategies
+// exist only on Linux. Progress is told the bytes copied after each chunk.
+func copyContents(ctx context.Context, dst, src *os.File, size int64, buffer []byte, strategy string, progress func(int64)) (string, error) {
+	switch strategy {
+	case "", CopyStrategyAuto, CopyStrategyBuffer:
+		return CopyStrategyBuffer, copyWithBuffer(ctx, dst, src, buffer, progress)
+	default:
+		return "", &errStrategyUnsupported{strategy, errNoKernelCopy}
+	}
+}
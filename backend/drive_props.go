@@ -0,0 +1,59 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxVolumeLabelLength is the longest label any supported filesystem accepts
+// (ext4 takes 16 bytes, FAT 11, NTFS 32 characters); each tool enforces its own limit
+const maxVolumeLabelLength = 255
+
+// GetDriveProperties describes the volume holding path: filesystem, label,
+// capacity, mount options and, where readable, the hardware behind it
+func GetDriveProperties(ctx context.Context, path string) (DriveProperties, error) {
+	stats, err := GetVolumeStats(ctx, path)
+	if err != nil {
+		return DriveProperties{}, err
+	}
+	props := DriveProperties{
+		Path:           stats.Path,
+		MountPoint:     stats.MountPoint,
+		FSType:         stats.FSType,
+		MountOptions:   []string{},
+		TotalBytes:     stats.TotalBytes,
+		UsedBytes:      stats.TotalBytes - stats.FreeBytes,
+		FreeBytes:      stats.FreeBytes,
+		AvailableBytes: stats.AvailableBytes,
+		ReadOnly:       stats.ReadOnly,
+	}
+	if props.MountPoint == "" {
+		props.MountPoint = props.Path
+	}
+	fillDriveProperties(&props)
+	return props, nil
+}
+
+// SetVolumeLabel renames the volume holding path
+func SetVolumeLabel(ctx context.Context, path, label string) error {
+	if !utf8.ValidString(label) || strings.ContainsAny(label, "\x00\n\r") {
+		return fmt.Errorf("invalid volume label")
+	}
+	if len(label) > maxVolumeLabelLength {
+		return fmt.Errorf("volume label is too long")
+	}
+	props, err := GetDriveProperties(ctx, path)
+	if err != nil {
+		return err
+	}
+	if props.ReadOnly {
+		return fmt.Errorf("%s is mounted read-only", props.MountPoint)
+	}
+	if err := setVolumeLabel(props, label); err != nil {
+		return err
+	}
+	logPrintf("Set label of %s to %q", props.MountPoint, label)
+	return nil
+}
@@ -0,0 +1,60 @@
+//go:build darwin
+
+package backend
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// relabelFilesystems are the types diskutil can rename
+var relabelFilesystems = map[string]bool{"apfs": true, "hfs": true, "msdos": true, "exfat": true}
+
+var darwinMountFlags = []struct {
+	flag uint32
+	name string
+}{
+	{unix.MNT_RDONLY, "ro"},
+	{unix.MNT_NOSUID, "nosuid"},
+	{unix.MNT_NOEXEC, "noexec"},
+	{unix.MNT_LOCAL, "local"},
+	{unix.MNT_JOURNALED, "journaled"},
+	{unix.MNT_DONTBROWSE, "nobrowse"},
+}
+
+func fillDriveProperties(props *DriveProperties) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(props.Path, &st); err != nil {
+		return
+	}
+	props.Device = unix.ByteSliceToString(st.Mntfromname[:])
+	for _, f := range darwinMountFlags {
+		if st.Flags&f.flag != 0 {
+			props.MountOptions = append(props.MountOptions, f.name)
+		}
+	}
+	props.Removable = st.Flags&unix.MNT_REMOVABLE != 0
+
+	// Finder shows the mount directory name under /Volumes as the volume name
+	if strings.HasPrefix(props.MountPoint, "/Volumes/") {
+		props.Label = filepath.Base(props.MountPoint)
+	} else if props.MountPoint == "/" {
+		props.Label = "Macintosh HD"
+	}
+	props.CanSetLabel = relabelFilesystems[props.FSType] && !props.ReadOnly
+}
+
+func setVolumeLabel(props DriveProperties, label string) error {
+	if !relabelFilesystems[props.FSType] {
+		return fmt.Errorf("changing the label of %s volumes is not supported", props.FSType)
+	}
+	out, err := exec.Command("diskutil", "rename", props.MountPoint, label).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("diskutil: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
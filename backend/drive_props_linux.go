@@ -0,0 +1,128 @@
+//go:build linux
+
+package backend
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const diskUUIDDir = "/dev/disk/by-uuid"
+
+// labelCommand returns the tool invocation that relabels an fsType filesystem
+// when UDisks2 is unavailable, or nil if there is none. XFS and FAT can only be
+// relabelled while unmounted; the tool reports that itself.
+func labelCommand(fsType, device, mountPoint, label string) []string {
+	switch fsType {
+	case "ext2", "ext3", "ext4":
+		return []string{"e2label", device, label}
+	case "btrfs":
+		return []string{"btrfs", "filesystem", "label", mountPoint, label}
+	case "vfat":
+		return []string{"fatlabel", device, label}
+	case "exfat":
+		return []string{"exfatlabel", device, label}
+	case "ntfs", "ntfs3":
+		return []string{"ntfslabel", device, label}
+	case "xfs":
+		return []string{"xfs_admin", "-L", label, device}
+	}
+	return nil
+}
+
+func fillDriveProperties(props *DriveProperties) {
+	entries, err := readMountInfo()
+	if err != nil {
+		return
+	}
+	resolved, err := filepath.EvalSymlinks(props.Path)
+	if err != nil {
+		resolved = props.Path
+	}
+	m, ok := mountForPath(entries, resolved)
+	if !ok {
+		return
+	}
+
+	props.Device = m.Source
+	seen := make(map[string]bool)
+	for _, opt := range append(append([]string{}, m.Options...), m.SuperOptions...) {
+		if !seen[opt] {
+			seen[opt] = true
+			props.MountOptions = append(props.MountOptions, opt)
+		}
+	}
+	if m.network() || !strings.HasPrefix(m.Source, "/dev/") {
+		return
+	}
+
+	device := m.Source
+	if resolved, err := filepath.EvalSymlinks(device); err == nil {
+		device = resolved
+	}
+	props.Label = diskLabels()[device]
+	props.UUID = diskLinks(diskUUIDDir)[device]
+
+	number := blockDeviceNumber(m)
+	props.Removable = blockDeviceRemovable(number)
+	fillBlockHardware(props, number)
+
+	props.CanSetLabel = !props.ReadOnly && labelCommand(m.FSType, device, m.MountPoint, "") != nil
+}
+
+// fillBlockHardware reads the vendor, model and serial of the disk holding the
+// block device from sysfs. Which files exist depends on the bus and driver.
+func fillBlockHardware(props *DriveProperties, deviceNumber string) {
+	disk, err := filepath.EvalSymlinks(filepath.Join("/sys/dev/block", deviceNumber))
+	if err != nil {
+		return
+	}
+	if _, err := os.Stat(filepath.Join(disk, "partition")); err == nil {
+		disk = filepath.Dir(disk)
+	}
+
+	read := func(names ...string) string {
+		for _, name := range names {
+			if data, err := os.ReadFile(filepath.Join(disk, name)); err == nil {
+				if value := strings.TrimSpace(string(data)); value != "" {
+					return value
+				}
+			}
+		}
+		return ""
+	}
+	// Bus drivers like virtio expose a numeric PCI vendor ID rather than a name
+	if vendor := read("device/vendor"); !strings.HasPrefix(vendor, "0x") {
+		props.Vendor = vendor
+	}
+	props.Model = read("device/model")
+	props.Serial = read("device/serial", "serial")
+}
+
+// setVolumeLabel relabels through UDisks2, which asks polkit for permission,
+// falling back to the filesystem's own tool when UDisks2 is unavailable
+func setVolumeLabel(props DriveProperties, label string) error {
+	args := labelCommand(props.FSType, props.Device, props.MountPoint, label)
+	if args == nil || !strings.HasPrefix(props.Device, "/dev/") {
+		return fmt.Errorf("changing the label of %s volumes is not supported", props.FSType)
+	}
+
+	if udisks, err := connectUDisks(); err == nil {
+		defer udisks.Close()
+		if block, ok := udisks.blockForDevice(props.Device); ok && udisks.has(block, udisksFilesystem) {
+			return udisks.call(block, udisksFilesystem+".SetLabel", label).Err
+		}
+	}
+
+	out, err := exec.Command(args[0], args[1:]...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%s: %s", args[0], msg)
+		}
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	return nil
+}
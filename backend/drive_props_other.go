@@ -0,0 +1,14 @@
+//go:build !linux && !darwin && !windows
+
+package backend
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func fillDriveProperties(_ *DriveProperties) {}
+
+func setVolumeLabel(_ DriveProperties, _ string) error {
+	return fmt.Errorf("changing volume labels is not supported on %s", runtime.GOOS)
+}
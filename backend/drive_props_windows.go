@@ -0,0 +1,60 @@
+//go:build windows
+
+package backend
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+var windowsVolumeFlags = []struct {
+	flag uint32
+	name string
+}{
+	{windows.FILE_READ_ONLY_VOLUME, "ro"},
+	{windows.FILE_VOLUME_IS_COMPRESSED, "compressed"},
+	{windows.FILE_SUPPORTS_ENCRYPTION, "encryption"},
+	{windows.FILE_PERSISTENT_ACLS, "acls"},
+	{windows.FILE_CASE_SENSITIVE_SEARCH, "case-sensitive"},
+}
+
+func fillDriveProperties(props *DriveProperties) {
+	root, err := windows.UTF16PtrFromString(props.MountPoint)
+	if err != nil {
+		return
+	}
+
+	label := make([]uint16, windows.MAX_PATH+1)
+	var serial, flags uint32
+	if err := windows.GetVolumeInformation(root, &label[0], uint32(len(label)), &serial, nil, &flags, nil, 0); err == nil {
+		props.Label = windows.UTF16ToString(label)
+		props.UUID = fmt.Sprintf("%04X-%04X", serial>>16, serial&0xFFFF)
+		for _, f := range windowsVolumeFlags {
+			if flags&f.flag != 0 {
+				props.MountOptions = append(props.MountOptions, f.name)
+			}
+		}
+	}
+
+	volume := make([]uint16, windows.MAX_PATH+1)
+	if err := windows.GetVolumeNameForVolumeMountPoint(root, &volume[0], uint32(len(volume))); err == nil {
+		props.Device = windows.UTF16ToString(volume)
+	}
+	props.Removable = windows.GetDriveType(root) == windows.DRIVE_REMOVABLE
+	props.CanSetLabel = !props.ReadOnly && props.FSType != "" && !strings.EqualFold(props.FSType, "CDFS") &&
+		!strings.EqualFold(props.FSType, "UDF")
+}
+
+func setVolumeLabel(props DriveProperties, label string) error {
+	root, err := windows.UTF16PtrFromString(props.MountPoint)
+	if err != nil {
+		return err
+	}
+	name, err := windows.UTF16PtrFromString(label)
+	if err != nil {
+		return err
+	}
+	return windows.SetVolumeLabel(root, name)
+}
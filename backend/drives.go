@@ -1,8 +1,10 @@
 package backend
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
 	"time"
 )
@@ -20,8 +22,9 @@ func NewDriveManager(platform PlatformManagerInterface) *DriveManager {
 	return &DriveManager{platform: platform}
 }
 
-// GetDriveInfo returns information about available drives
-func (d *DriveManager) GetDriveInfo() []DriveInfo {
+// GetDriveInfo returns information about available drives. Each filesystem
+// query is bounded by pathCallTimeout so a dead mount cannot stall the list.
+func (d *DriveManager) GetDriveInfo(ctx context.Context) []DriveInfo {
 	if cached := d.loadDriveCache(); cached != nil {
 		return cached
 	}
@@ -32,9 +35,9 @@ func (d *DriveManager) GetDriveInfo() []DriveInfo {
 	case "windows":
 		drives = d.getWindowsDrives()
 	case "darwin":
-		drives = d.getMacVolumes()
+		drives = d.getMacVolumes(ctx)
 	case "linux":
-		drives = d.getLinuxMountPoints()
+		drives = d.getLinuxMountPoints(ctx)
 	default:
 		logPrintf("Drive enumeration not supported on %s", runtime.GOOS)
 	}
@@ -52,28 +55,31 @@ func (d *DriveManager) getWindowsDrives() []DriveInfo {
 }
 
 // getMacVolumes returns macOS volume information
-func (d *DriveManager) getMacVolumes() []DriveInfo {
+func (d *DriveManager) getMacVolumes(ctx context.Context) []DriveInfo {
 	var drives []DriveInfo
 
 	// Add root volume
-	drives = append(drives, DriveInfo{
+	root := DriveInfo{
 		Path:   "/",
 		Letter: "",
 		Name:   "Macintosh HD",
-	})
+	}
+	fillDriveStats(ctx, &root)
+	drives = append(drives, root)
 
 	// Add /Volumes if it exists
-	if _, err := os.Stat("/Volumes"); err == nil {
-		if entries, err := os.ReadDir("/Volumes"); err == nil {
-			for _, entry := range entries {
-				if entry.IsDir() {
-					volumePath := fmt.Sprintf("/Volumes/%s", entry.Name())
-					drives = append(drives, DriveInfo{
-						Path:   volumePath,
-						Letter: "",
-						Name:   entry.Name(),
-					})
+	entries, err := withPathDeadline(ctx, "/Volumes", func() ([]os.DirEntry, error) { return os.ReadDir("/Volumes") })
+	if err == nil {
+		for _, entry := range entries {
+			if entry.IsDir() {
+				volumePath := fmt.Sprintf("/Volumes/%s", entry.Name())
+				volume := DriveInfo{
+					Path:   volumePath,
+					Letter: "",
+					Name:   entry.Name(),
 				}
+				fillDriveStats(ctx, &volume)
+				drives = append(drives, volume)
 			}
 		}
 	}
@@ -81,8 +87,29 @@ func (d *DriveManager) getMacVolumes() []DriveInfo {
 	return drives
 }
 
-// getLinuxMountPoints returns Linux mount point information
-func (d *DriveManager) getLinuxMountPoints() []DriveInfo {
+// fillDriveStats adds the capacity, filesystem type and readonly state of the
+// drive's volume; drives whose volume cannot be queried are left as they are
+func fillDriveStats(ctx context.Context, drive *DriveInfo) {
+	stats, err := withPathDeadline(ctx, drive.Path, func() (VolumeStats, error) { return volumeStats(drive.Path) })
+	if err != nil {
+		return
+	}
+	if drive.FSType == "" {
+		drive.FSType = stats.FSType
+	}
+	drive.ReadOnly = drive.ReadOnly || stats.ReadOnly
+	drive.TotalBytes = stats.TotalBytes
+	drive.FreeBytes = stats.FreeBytes
+	drive.AvailableBytes = stats.AvailableBytes
+}
+
+// getLinuxMountPoints returns the real mounts from mountinfo, falling back to
+// well-known directories when the mount table cannot be read
+func (d *DriveManager) getLinuxMountPoints(ctx context.Context) []DriveInfo {
+	if drives := listRealMounts(ctx); len(drives) > 0 {
+		return drives
+	}
+
 	var drives []DriveInfo
 
 	// Add root filesystem
@@ -93,29 +120,16 @@ func (d *DriveManager) getLinuxMountPoints() []DriveInfo {
 	})
 
 	// Add common mount points
-	commonMounts := []struct {
-		path string
-		name string
-	}{
-		{"/home", "Home"},
-		{"/media", "Media"},
-		{"/mnt", "Mount"},
-		{"/opt", "Optional"},
-		{"/usr", "User Programs"},
-		{"/var", "Variable Data"},
-	}
-
-	for _, mount := range commonMounts {
-		if _, err := os.Stat(mount.path); err == nil {
-			drives = append(drives, DriveInfo{
-				Path:   mount.path,
-				Letter: "",
-				Name:   mount.name,
-			})
-		}
+	commonMounts := []quickAccessCandidate{
+		{path: "/home", name: "Home"},
+		{path: "/media", name: "Media"},
+		{path: "/mnt", name: "Mount"},
+		{path: "/opt", name: "Optional"},
+		{path: "/usr", name: "User Programs"},
+		{path: "/var", name: "Variable Data"},
 	}
 
-	return drives
+	return append(drives, existingPaths(ctx, commonMounts)...)
 }
 
 // GetSystemRoots returns system root paths for quick navigation
@@ -126,8 +140,9 @@ func (d *DriveManager) GetSystemRoots() []string {
 	return d.platform.GetSystemRoots()
 }
 
-// GetQuickAccessPaths returns commonly accessed directories for quick navigation
-func (d *DriveManager) GetQuickAccessPaths() []DriveInfo {
+// GetQuickAccessPaths returns commonly accessed directories for quick navigation.
+// Candidates that do not answer within pathCallTimeout are left out.
+func (d *DriveManager) GetQuickAccessPaths(ctx context.Context) []DriveInfo {
 	if cached := d.loadQuickAccessCache(); cached != nil {
 		return cached
 	}
@@ -136,11 +151,11 @@ func (d *DriveManager) GetQuickAccessPaths() []DriveInfo {
 
 	switch runtime.GOOS {
 	case "windows":
-		quickPaths = d.getWindowsQuickAccess()
+		quickPaths = d.getWindowsQuickAccess(ctx)
 	case "darwin":
-		quickPaths = d.getMacQuickAccess()
+		quickPaths = d.getMacQuickAccess(ctx)
 	case "linux":
-		quickPaths = d.getLinuxQuickAccess()
+		quickPaths = d.getLinuxQuickAccess(ctx)
 	}
 
 	d.storeQuickAccessCache(quickPaths)
@@ -148,9 +163,7 @@ func (d *DriveManager) GetQuickAccessPaths() []DriveInfo {
 }
 
 // getWindowsQuickAccess returns Windows quick access paths
-func (d *DriveManager) getWindowsQuickAccess() []DriveInfo {
-	var quickPaths []DriveInfo
-
+func (d *DriveManager) getWindowsQuickAccess(ctx context.Context) []DriveInfo {
 	// Get common Windows directories
 	homeDir := ""
 	if d.platform != nil {
@@ -160,39 +173,24 @@ func (d *DriveManager) getWindowsQuickAccess() []DriveInfo {
 		homeDir, _ = os.UserHomeDir()
 	}
 
-	commonPaths := []struct {
-		path string
-		name string
-	}{
-		{homeDir, "Home"},
-		{fmt.Sprintf("%s\\Desktop", homeDir), "Desktop"},
-		{fmt.Sprintf("%s\\Documents", homeDir), "Documents"},
-		{fmt.Sprintf("%s\\Downloads", homeDir), "Downloads"},
-		{fmt.Sprintf("%s\\Pictures", homeDir), "Pictures"},
-		{fmt.Sprintf("%s\\Music", homeDir), "Music"},
-		{fmt.Sprintf("%s\\Videos", homeDir), "Videos"},
-		{"C:\\Program Files", "Program Files"},
-		{"C:\\Program Files (x86)", "Program Files (x86)"},
-		{"C:\\Windows", "Windows"},
+	commonPaths := []quickAccessCandidate{
+		{homeDir, "Home", QuickAccessSourceHome},
+		{fmt.Sprintf("%s\\Desktop", homeDir), "Desktop", QuickAccessSourceUserDir},
+		{fmt.Sprintf("%s\\Documents", homeDir), "Documents", QuickAccessSourceUserDir},
+		{fmt.Sprintf("%s\\Downloads", homeDir), "Downloads", QuickAccessSourceUserDir},
+		{fmt.Sprintf("%s\\Pictures", homeDir), "Pictures", QuickAccessSourceUserDir},
+		{fmt.Sprintf("%s\\Music", homeDir), "Music", QuickAccessSourceUserDir},
+		{fmt.Sprintf("%s\\Videos", homeDir), "Videos", QuickAccessSourceUserDir},
+		{"C:\\Program Files", "Program Files", QuickAccessSourceSystem},
+		{"C:\\Program Files (x86)", "Program Files (x86)", QuickAccessSourceSystem},
+		{"C:\\Windows", "Windows", QuickAccessSourceSystem},
 	}
 
-	for _, path := range commonPaths {
-		if _, err := os.Stat(path.path); err == nil {
-			quickPaths = append(quickPaths, DriveInfo{
-				Path:   path.path,
-				Letter: "",
-				Name:   path.name,
-			})
-		}
-	}
-
-	return quickPaths
+	return existingPaths(ctx, commonPaths)
 }
 
 // getMacQuickAccess returns macOS quick access paths
-func (d *DriveManager) getMacQuickAccess() []DriveInfo {
-	var quickPaths []DriveInfo
-
+func (d *DriveManager) getMacQuickAccess(ctx context.Context) []DriveInfo {
 	homeDir := ""
 	if d.platform != nil {
 		homeDir = d.platform.GetHomeDirectory()
@@ -201,39 +199,25 @@ func (d *DriveManager) getMacQuickAccess() []DriveInfo {
 		homeDir, _ = os.UserHomeDir()
 	}
 
-	commonPaths := []struct {
-		path string
-		name string
-	}{
-		{homeDir, "Home"},
-		{fmt.Sprintf("%s/Desktop", homeDir), "Desktop"},
-		{fmt.Sprintf("%s/Documents", homeDir), "Documents"},
-		{fmt.Sprintf("%s/Downloads", homeDir), "Downloads"},
-		{fmt.Sprintf("%s/Pictures", homeDir), "Pictures"},
-		{fmt.Sprintf("%s/Music", homeDir), "Music"},
-		{fmt.Sprintf("%s/Movies", homeDir), "Movies"},
-		{"/Applications", "Applications"},
-		{"/System", "System"},
-		{"/Users", "Users"},
+	commonPaths := []quickAccessCandidate{
+		{homeDir, "Home", QuickAccessSourceHome},
+		{fmt.Sprintf("%s/Desktop", homeDir), "Desktop", QuickAccessSourceUserDir},
+		{fmt.Sprintf("%s/Documents", homeDir), "Documents", QuickAccessSourceUserDir},
+		{fmt.Sprintf("%s/Downloads", homeDir), "Downloads", QuickAccessSourceUserDir},
+		{fmt.Sprintf("%s/Pictures", homeDir), "Pictures", QuickAccessSourceUserDir},
+		{fmt.Sprintf("%s/Music", homeDir), "Music", QuickAccessSourceUserDir},
+		{fmt.Sprintf("%s/Movies", homeDir), "Movies", QuickAccessSourceUserDir},
+		{"/Applications", "Applications", QuickAccessSourceSystem},
+		{"/System", "System", QuickAccessSourceSystem},
+		{"/Users", "Users", QuickAccessSourceSystem},
 	}
 
-	for _, path := range commonPaths {
-		if _, err := os.Stat(path.path); err == nil {
-			quickPaths = append(quickPaths, DriveInfo{
-				Path:   path.path,
-				Letter: "",
-				Name:   path.name,
-			})
-		}
-	}
-
-	return quickPaths
+	return existingPaths(ctx, commonPaths)
 }
 
-// getLinuxQuickAccess returns Linux quick access paths
-func (d *DriveManager) getLinuxQuickAccess() []DriveInfo {
-	var quickPaths []DriveInfo
-
+// getLinuxQuickAccess returns home, the XDG user directories under their
+// configured (often localized) names, the GTK bookmarks and a few system paths
+func (d *DriveManager) getLinuxQuickAccess(ctx context.Context) []DriveInfo {
 	homeDir := ""
 	if d.platform != nil {
 		homeDir = d.platform.GetHomeDirectory()
@@ -241,35 +225,90 @@ func (d *DriveManager) getLinuxQuickAccess() []DriveInfo {
 	if homeDir == "" {
 		homeDir, _ = os.UserHomeDir()
 	}
+	configHome := xdgConfigHome(homeDir)
+
+	commonPaths := []quickAccessCandidate{{homeDir, "Home", QuickAccessSourceHome}}
+	commonPaths = append(commonPaths, xdgUserDirCandidates(configHome, homeDir)...)
+	commonPaths = append(commonPaths, gtkBookmarkCandidates(configHome, homeDir)...)
+	commonPaths = append(commonPaths,
+		quickAccessCandidate{"/usr", "User Programs", QuickAccessSourceSystem},
+		quickAccessCandidate{"/opt", "Optional Software", QuickAccessSourceSystem},
+		quickAccessCandidate{"/etc", "Configuration", QuickAccessSourceSystem},
+		quickAccessCandidate{"/var", "Variable Data", QuickAccessSourceSystem},
+	)
+
+	return existingPaths(ctx, commonPaths)
+}
 
-	commonPaths := []struct {
-		path string
-		name string
-	}{
-		{homeDir, "Home"},
-		{fmt.Sprintf("%s/Desktop", homeDir), "Desktop"},
-		{fmt.Sprintf("%s/Documents", homeDir), "Documents"},
-		{fmt.Sprintf("%s/Downloads", homeDir), "Downloads"},
-		{fmt.Sprintf("%s/Pictures", homeDir), "Pictures"},
-		{fmt.Sprintf("%s/Music", homeDir), "Music"},
-		{fmt.Sprintf("%s/Videos", homeDir), "Videos"},
-		{"/usr", "User Programs"},
-		{"/opt", "Optional Software"},
-		{"/etc", "Configuration"},
-		{"/var", "Variable Data"},
-	}
+// quickAccessCandidate is a well-known directory offered when it exists
+type quickAccessCandidate struct {
+	path   string
+	name   string
+	source string
+}
 
-	for _, path := range commonPaths {
-		if _, err := os.Stat(path.path); err == nil {
-			quickPaths = append(quickPaths, DriveInfo{
-				Path:   path.path,
+// existingPaths returns the candidates that exist, in order, keeping the first
+// of any duplicates. Each stat has its own deadline, and the parents of a path
+// that timed out are skipped by the backoff, so a dead mount under home cannot
+// freeze the sidebar.
+func existingPaths(ctx context.Context, candidates []quickAccessCandidate) []DriveInfo {
+	var paths []DriveInfo
+	seen := make(map[string]bool, len(candidates))
+	for _, candidate := range candidates {
+		if candidate.path == "" {
+			continue
+		}
+		path := filepath.Clean(candidate.path)
+		if seen[path] {
+			continue
+		}
+		if _, err := withPathDeadline(ctx, path, func() (os.FileInfo, error) { return os.Stat(path) }); err == nil {
+			seen[path] = true
+			paths = append(paths, DriveInfo{
+				Path:   candidate.path,
 				Letter: "",
-				Name:   path.name,
+				Name:   candidate.name,
+				Source: candidate.source,
 			})
 		}
 	}
+	return paths
+}
 
-	return quickPaths
+// pinnedEntries returns quick access entries for the pinned folders that exist,
+// under their display name when one is set
+func pinnedEntries(ctx context.Context, pins []PinnedFolder) []DriveInfo {
+	candidates := make([]quickAccessCandidate, 0, len(pins))
+	for _, pin := range pins {
+		name := pin.Name
+		if name == "" {
+			name = filepath.Base(pin.Path)
+		}
+		candidates = append(candidates, quickAccessCandidate{pin.Path, name, QuickAccessSourcePinned})
+	}
+	return existingPaths(ctx, candidates)
+}
+
+// mergePinned places pins after the user's own folders and before the system
+// paths. Pins that are already listed keep their original entry.
+func mergePinned(paths, pins []DriveInfo) []DriveInfo {
+	listed := make(map[string]bool, len(paths))
+	at := len(paths)
+	for i, p := range paths {
+		listed[filepath.Clean(p.Path)] = true
+		if p.Source == QuickAccessSourceSystem && at == len(paths) {
+			at = i
+		}
+	}
+
+	merged := make([]DriveInfo, 0, len(paths)+len(pins))
+	merged = append(merged, paths[:at]...)
+	for _, pin := range pins {
+		if !listed[filepath.Clean(pin.Path)] {
+			merged = append(merged, pin)
+		}
+	}
+	return append(merged, paths[at:]...)
 }
 
 func (d *DriveManager) loadDriveCache() []DriveInfo {
@@ -329,6 +368,9 @@ func (d *DriveManager) InvalidateCaches() {
 	d.quickAccessCacheExpiry = time.Time{}
 	d.mu.Unlock()
 
+	// The mount that timed out may be gone or replaced
+	unavailablePaths.reset()
+
 	if d.platform == nil {
 		return
 	}
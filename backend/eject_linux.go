@@ -0,0 +1,174 @@
+//go:build linux
+
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+	"golang.org/x/sys/unix"
+)
+
+// maxHolderPaths caps how many open paths are listed per busy-holding process
+const maxHolderPaths = 8
+
+// ejectVolume unmounts the mount at path, directly with umount2 when this
+// process may, otherwise through UDisks2. With powerOff the drive is then
+// powered off, or ejected if it only supports that, so it can be unplugged.
+func ejectVolume(path string, powerOff bool) EjectResult {
+	path = filepath.Clean(path)
+	result := EjectResult{Path: path}
+
+	entries, err := readMountInfo()
+	if err != nil {
+		result.Reason, result.Message = EjectReasonFailed, err.Error()
+		return result
+	}
+	var mount mountEntry
+	found := false
+	for _, m := range entries {
+		if m.MountPoint == path {
+			mount, found = m, true
+		}
+	}
+	if !found {
+		result.Reason, result.Message = EjectReasonNotMounted, path+" is not a mount point"
+		return result
+	}
+	if path == "/" {
+		result.Reason, result.Message = EjectReasonNotSupported, "the root filesystem cannot be ejected"
+		return result
+	}
+
+	// UDisks is still needed to power off after a direct unmount, so connect up front
+	udisks, udisksErr := connectUDisks()
+	if udisks != nil {
+		defer udisks.Close()
+	}
+	var block dbus.ObjectPath
+	hasBlock := false
+	if udisks != nil {
+		block, hasBlock = udisks.blockForDevice(mount.Source)
+	}
+
+	err = unix.Unmount(path, 0)
+	switch {
+	case err == nil:
+		result.Method = "umount2"
+	case errors.Is(err, unix.EBUSY):
+		return busyResult(result, path, err)
+	case errors.Is(err, unix.EPERM) && hasBlock:
+		result.Method = "udisks2"
+		if err := udisks.call(block, udisksFilesystem+".Unmount").Err; err != nil {
+			result.Reason, result.Message = udisksReason(err), err.Error()
+			if result.Reason == EjectReasonBusy {
+				return busyResult(result, path, err)
+			}
+			return result
+		}
+	case errors.Is(err, unix.EPERM):
+		result.Reason = EjectReasonPermission
+		result.Message = fmt.Sprintf("unmounting %s requires privileges and UDisks2 is unavailable", path)
+		if udisksErr != nil {
+			result.Message += ": " + udisksErr.Error()
+		}
+		return result
+	default:
+		result.Reason, result.Message = EjectReasonFailed, err.Error()
+		return result
+	}
+	result.Success = true
+	logPrintf("Unmounted %s via %s", path, result.Method)
+
+	if !powerOff {
+		return result
+	}
+	if !hasBlock {
+		if udisks == nil && strings.HasPrefix(mount.Source, "/dev/") {
+			result.Message = "unmounted; the drive cannot be powered off without UDisks2"
+		}
+		return result
+	}
+	drive, ok := udisks.driveOf(block)
+	if !ok {
+		return result
+	}
+	var call string
+	switch {
+	case udisks.boolProp(drive, udisksDrive, "CanPowerOff"):
+		call = udisksDrive + ".PowerOff"
+	case udisks.boolProp(drive, udisksDrive, "Ejectable"):
+		call = udisksDrive + ".Eject"
+	default:
+		return result
+	}
+	// Other filesystems on the drive may still be mounted; the volume itself is
+	// already safe, so a failed power-off only adds a message
+	if err := udisks.call(drive, call).Err; err != nil {
+		result.Message = "unmounted, but the drive could not be powered off: " + err.Error()
+		return result
+	}
+	result.PoweredOff = true
+	return result
+}
+
+func busyResult(result EjectResult, mountPoint string, err error) EjectResult {
+	result.Reason = EjectReasonBusy
+	result.Holders = mountHolders(mountPoint)
+	result.Message = fmt.Sprintf("%s is in use: %v", mountPoint, err)
+	if len(result.Holders) > 0 {
+		result.Message = fmt.Sprintf("%s is in use by %d process(es)", mountPoint, len(result.Holders))
+	}
+	return result
+}
+
+// mountHolders lists processes with open files or a working directory under
+// mountPoint. Processes of other users are only visible with privileges.
+func mountHolders(mountPoint string) []MountHolder {
+	procs, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+	var holders []MountHolder
+
+	for _, proc := range procs {
+		pid, err := strconv.Atoi(proc.Name())
+		if err != nil {
+			continue
+		}
+		base := filepath.Join("/proc", proc.Name())
+
+		var paths []string
+		seen := make(map[string]bool)
+		add := func(link string) {
+			target, err := os.Readlink(link)
+			if err != nil || seen[target] || !pathWithinMount(target, mountPoint) {
+				return
+			}
+			seen[target] = true
+			if len(paths) < maxHolderPaths {
+				paths = append(paths, target)
+			}
+		}
+
+		add(filepath.Join(base, "cwd"))
+		add(filepath.Join(base, "root"))
+		if fds, err := os.ReadDir(filepath.Join(base, "fd")); err == nil {
+			for _, fd := range fds {
+				add(filepath.Join(base, "fd", fd.Name()))
+			}
+		}
+		if len(paths) == 0 {
+			continue
+		}
+
+		comm, _ := os.ReadFile(filepath.Join(base, "comm"))
+		holders = append(holders, MountHolder{PID: pid, Command: strings.TrimSpace(string(comm)), Paths: paths})
+	}
+	return holders
+}
@@ -0,0 +1,17 @@
+//go:build !linux
+
+package backend
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// ejectVolume is implemented on Linux; Windows ejects through EjectDriveWindows
+func ejectVolume(path string, _ bool) EjectResult {
+	return EjectResult{
+		Path:    path,
+		Reason:  EjectReasonNotSupported,
+		Message: fmt.Sprintf("ejecting volumes is not supported on %s", runtime.GOOS),
+	}
+}
@@ -47,6 +47,15 @@ func (e *EventEmitter) EmitDirectoryError(message string) {
 	}
 }
 
+// EmitDirectoryErrorCode emits a directory error with a code such as
+// DirectoryErrorTimeout as the event's second argument
+func (e *EventEmitter) EmitDirectoryErrorCode(message, code string) {
+	if e.ctx != nil {
+		runtime.EventsEmit(e.ctx, "DirectoryError", message, code)
+		logPrintf("📡 Emitted directory error (%s): %s", code, message)
+	}
+}
+
 // EmitDirectoryBatch emits a batch of directory entries to the frontend
 func (e *EventEmitter) EmitDirectoryBatch(entries []FileInfo) {
 	if e.ctx != nil {
@@ -75,3 +84,65 @@ func (e *EventEmitter) EmitDirectoryComplete(path string, totalFiles, totalDirs
 		logPrintf("📡 Emitted directory complete for: %s (%d files, %d dirs)", path, totalFiles, totalDirs)
 	}
 }
+
+// EmitJobProgress emits a progress update for a running background job
+func (e *EventEmitter) EmitJobProgress(info JobInfo) {
+	if e.ctx != nil {
+		runtime.EventsEmit(e.ctx, "JobProgress", info)
+	}
+}
+
+// EmitJobComplete signals that a background job has finished
+func (e *EventEmitter) EmitJobComplete(info JobInfo) {
+	if e.ctx != nil {
+		runtime.EventsEmit(e.ctx, "JobComplete", info)
+		logPrintf("📡 Emitted job complete: %s (%s)", info.ID, info.Status)
+	}
+}
+
+// EmitShareAccess reports a request served by a directory share
+func (e *EventEmitter) EmitShareAccess(access ShareAccess) {
+	if e.ctx != nil {
+		runtime.EventsEmit(e.ctx, "ShareAccess", access)
+	}
+}
+
+// EmitNavigateRequested asks the frontend to navigate on behalf of an external caller
+func (e *EventEmitter) EmitNavigateRequested(req NavigateRequest) {
+	if e.ctx != nil {
+		runtime.EventsEmit(e.ctx, "NavigateRequested", req)
+		logPrintf("📡 Emitted navigate request for: %s (%s)", req.Path, req.Source)
+	}
+}
+
+// EmitConfirmPermanentDelete asks the user whether items that could not be trashed should be deleted permanently
+func (e *EventEmitter) EmitConfirmPermanentDelete(req PermanentDeleteRequest) {
+	if e.ctx != nil {
+		runtime.EventsEmit(e.ctx, "ConfirmPermanentDelete", req)
+		logPrintf("📡 Emitted permanent delete confirmation %s for %d items", req.RequestID, len(req.Paths))
+	}
+}
+
+// EmitConfirmProtectedPath asks the user to approve an operation on protected paths
+func (e *EventEmitter) EmitConfirmProtectedPath(req PolicyConfirmRequest) {
+	if e.ctx != nil {
+		runtime.EventsEmit(e.ctx, "ConfirmProtectedPath", req)
+		logPrintf("📡 Emitted protected path confirmation %s for %s", req.RequestID, req.Decision.Op)
+	}
+}
+
+// EmitProtectedPathDenied reports an operation refused by the protected-path policy
+func (e *EventEmitter) EmitProtectedPathDenied(decision PolicyDecision) {
+	if e.ctx != nil {
+		runtime.EventsEmit(e.ctx, "ProtectedPathDenied", decision)
+		logPrintf("📡 Emitted protected path denial: %s", decision.Reason)
+	}
+}
+
+// EmitPinsUpdated broadcasts the pinned folders after they changed
+func (e *EventEmitter) EmitPinsUpdated(pins []PinnedFolder) {
+	if e.ctx != nil {
+		runtime.EventsEmit(e.ctx, "pinsUpdated", pins)
+		logPrintf("📡 Emitted pins update (%d pins)", len(pins))
+	}
+}
@@ -0,0 +1,130 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"time"
+)
+
+const (
+	exifScanLimit        = 256 * 1024
+	exifTagDateTime      = 0x0132
+	exifTagExifIFD       = 0x8769
+	exifTagDateTimeOrig  = 0x9003
+	exifDateTimeLayout   = "2006:01:02 15:04:05"
+	exifMaxIFDEntryCount = 1024
+)
+
+// readEXIFDateTime returns the capture time stored in a JPEG or TIFF-based
+// image (DateTimeOriginal, falling back to DateTime). Only the start of the
+// file is read.
+func readEXIFDateTime(path string) (time.Time, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, false
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, exifScanLimit))
+	if err != nil || len(data) < 8 {
+		return time.Time{}, false
+	}
+
+	tiff := data
+	if data[0] == 0xFF && data[1] == 0xD8 {
+		if tiff = findJPEGExif(data); tiff == nil {
+			return time.Time{}, false
+		}
+	}
+	return parseTIFFDateTime(tiff)
+}
+
+// findJPEGExif walks JPEG segments up to the first APP1 "Exif" block and
+// returns its TIFF payload
+func findJPEGExif(data []byte) []byte {
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return nil
+		}
+		marker := data[i+1]
+		if marker == 0xD9 || marker == 0xDA { // end of image, start of scan
+			return nil
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return nil
+		}
+		payload := data[i+4 : end]
+		if marker == 0xE1 && bytes.HasPrefix(payload, []byte("Exif\x00\x00")) {
+			return payload[6:]
+		}
+		i = end
+	}
+	return nil
+}
+
+func parseTIFFDateTime(tiff []byte) (time.Time, bool) {
+	if len(tiff) < 8 {
+		return time.Time{}, false
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return time.Time{}, false
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return time.Time{}, false
+	}
+
+	ifd0 := readIFD(tiff, order, order.Uint32(tiff[4:]))
+	if offset, ok := ifd0[exifTagExifIFD]; ok {
+		exif := readIFD(tiff, order, order.Uint32(offset))
+		if t, ok := parseEXIFTime(tiff, order, exif[exifTagDateTimeOrig]); ok {
+			return t, true
+		}
+	}
+	return parseEXIFTime(tiff, order, ifd0[exifTagDateTime])
+}
+
+// readIFD returns the raw 4-byte value field of each entry in the IFD at offset
+func readIFD(tiff []byte, order binary.ByteOrder, offset uint32) map[uint16][]byte {
+	entries := make(map[uint16][]byte)
+	if uint64(offset)+2 > uint64(len(tiff)) {
+		return entries
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	if count > exifMaxIFDEntryCount {
+		return entries
+	}
+	for i := 0; i < count; i++ {
+		start := int(offset) + 2 + i*12
+		if start+12 > len(tiff) {
+			break
+		}
+		entries[order.Uint16(tiff[start:])] = tiff[start+8 : start+12]
+	}
+	return entries
+}
+
+// parseEXIFTime reads the 20-byte ASCII "YYYY:MM:DD hh:mm:ss" value that value points to
+func parseEXIFTime(tiff []byte, order binary.ByteOrder, value []byte) (time.Time, bool) {
+	if len(value) != 4 {
+		return time.Time{}, false
+	}
+	start := uint64(order.Uint32(value))
+	if start+19 > uint64(len(tiff)) {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(exifDateTimeLayout, string(tiff[start:start+19]), time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
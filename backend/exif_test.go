@@ -0,0 +1,128 @@
+package backend
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// tiffOrder is a byte order that can both read and append
+type tiffOrder interface {
+	binary.ByteOrder
+	binary.AppendByteOrder
+}
+
+type tiffEntry struct {
+	tag, kind    uint16
+	count, value uint32
+}
+
+func appendIFD(b []byte, order tiffOrder, entries []tiffEntry) []byte {
+	b = order.AppendUint16(b, uint16(len(entries)))
+	for _, e := range entries {
+		b = order.AppendUint16(b, e.tag)
+		b = order.AppendUint16(b, e.kind)
+		b = order.AppendUint32(b, e.count)
+		b = order.AppendUint32(b, e.value)
+	}
+	return order.AppendUint32(b, 0) // no next IFD
+}
+
+// buildTIFF returns a TIFF header with IFD0 holding DateTime and, when original
+// is set, an Exif IFD holding DateTimeOriginal. Empty values are left out.
+func buildTIFF(order tiffOrder, dateTime, original string) []byte {
+	b := []byte("II")
+	if order == binary.BigEndian {
+		b = []byte("MM")
+	}
+	b = order.AppendUint16(b, 42)
+	b = order.AppendUint32(b, 8)
+
+	count := 0
+	for _, v := range []string{dateTime, original} {
+		if v != "" {
+			count++
+		}
+	}
+	exifAt := 8 + 2 + 12*count + 4
+	dataAt := exifAt
+	if original != "" {
+		dataAt += 2 + 12 + 4
+	}
+
+	var ifd0 []tiffEntry
+	var data []byte
+	if dateTime != "" {
+		ifd0 = append(ifd0, tiffEntry{exifTagDateTime, 2, 20, uint32(dataAt + len(data))})
+		data = append(data, dateTime+"\x00"...)
+	}
+	if original != "" {
+		ifd0 = append(ifd0, tiffEntry{exifTagExifIFD, 4, 1, uint32(exifAt)})
+	}
+	b = appendIFD(b, order, ifd0)
+	if original != "" {
+		b = appendIFD(b, order, []tiffEntry{{exifTagDateTimeOrig, 2, 20, uint32(dataAt + len(data))}})
+		data = append(data, original+"\x00"...)
+	}
+	return append(b, data...)
+}
+
+// buildJPEG wraps tiff in an APP1 Exif segment after a JFIF APP0 segment
+func buildJPEG(tiff []byte) []byte {
+	b := []byte{0xFF, 0xD8}
+	app0 := []byte("JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00")
+	b = append(b, 0xFF, 0xE0)
+	b = binary.BigEndian.AppendUint16(b, uint16(2+len(app0)))
+	b = append(b, app0...)
+	if tiff != nil {
+		payload := append([]byte("Exif\x00\x00"), tiff...)
+		b = append(b, 0xFF, 0xE1)
+		b = binary.BigEndian.AppendUint16(b, uint16(2+len(payload)))
+		b = append(b, payload...)
+	}
+	return append(b, 0xFF, 0xDA, 0x00, 0x02, 0xFF, 0xD9)
+}
+
+func TestReadEXIFDateTime(t *testing.T) {
+	const taken, modified = "2021:03:04 05:06:07", "2022:10:11 12:13:14"
+	full := buildTIFF(binary.LittleEndian, modified, taken)
+
+	tests := []struct {
+		name string
+		data []byte
+		want string // "" when no date should be found
+	}{
+		{"little-endian TIFF prefers DateTimeOriginal", full, taken},
+		{"big-endian TIFF", buildTIFF(binary.BigEndian, modified, taken), taken},
+		{"DateTime only", buildTIFF(binary.BigEndian, modified, ""), modified},
+		{"DateTimeOriginal only", buildTIFF(binary.LittleEndian, "", taken), taken},
+		{"JPEG with Exif after APP0", buildJPEG(full), taken},
+		{"JPEG without Exif", buildJPEG(nil), ""},
+		{"truncated TIFF", full[:20], ""},
+		{"bad byte order", append([]byte("XX"), full[2:]...), ""},
+		{"bad magic", append([]byte("II\x2b\x00"), full[4:]...), ""},
+		{"invalid date", buildTIFF(binary.LittleEndian, "not a date at all!!", ""), ""},
+		{"not an image", []byte("just some text in a file"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "image")
+			if err := os.WriteFile(path, tt.data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got, ok := readEXIFDateTime(path)
+			if tt.want == "" {
+				if ok {
+					t.Errorf("found %v, want no date", got)
+				}
+				return
+			}
+			want, _ := time.ParseInLocation(exifDateTimeLayout, tt.want, time.Local)
+			if !ok || !got.Equal(want) {
+				t.Errorf("got %v, %v; want %v", got, ok, want)
+			}
+		})
+	}
+}
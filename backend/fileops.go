@@ -3,11 +3,11 @@
 package backend
 
 import (
+	"context"
 	"log"
 	"os"
 	"path/filepath"
 	"runtime"
-	"strings"
 	"syscall"
 	"unsafe"
 )
@@ -48,6 +48,7 @@ type SHFILEOPSTRUCT struct {
 func NewFileOperationsManager(platform PlatformManagerInterface) *FileOperationsManager {
 	return &FileOperationsManager{
 		platform: platform,
+		policy:   NewPathPolicy(platform),
 	}
 }
 
@@ -78,6 +79,10 @@ func (fo *FileOperationsManager) CopyFiles(sourcePaths []string, destDir string)
 		return false
 	}
 
+	if !fo.allowedByPolicy(OpCopy, transferTargets(sourcePaths, destDir)...) {
+		return false
+	}
+
 	// ROLLBACK MECHANISM: Track successfully copied files for cleanup on failure
 	var copiedFiles []string
 	defer func() {
@@ -126,6 +131,25 @@ func (fo *FileOperationsManager) CopyFiles(sourcePaths []string, destDir string)
 	return fo.copyFilesStandardWithRollback(sourcePaths, destDir, &copiedFiles)
 }
 
+// CopyFilesWithOptions copies files like CopyFiles. The options cover Unix
+// metadata (ownership, xattrs, POSIX ACLs, symlinks, hardlinks), so they have
+// no effect on the Windows shell copy, which also cannot be interrupted once
+// started; ctx is only checked before it begins.
+func (fo *FileOperationsManager) CopyFilesWithOptions(ctx context.Context, sourcePaths []string, destDir string, opts CopyOptions) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	return fo.CopyFiles(sourcePaths, destDir)
+}
+
+// MoveFilesWithOptions moves files like MoveFiles; see CopyFilesWithOptions
+func (fo *FileOperationsManager) MoveFilesWithOptions(ctx context.Context, sourcePaths []string, destDir string, opts CopyOptions) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	return fo.MoveFiles(sourcePaths, destDir)
+}
+
 // shFileOperation wraps SHFileOperationW for copy/move operations. Returns true on success.
 func (fo *FileOperationsManager) shFileOperation(op uint32, sourcePaths []string, destDir string) bool {
 	// Build double-null-terminated UTF-16 source string list
@@ -197,6 +221,10 @@ func (fo *FileOperationsManager) MoveFiles(sourcePaths []string, destDir string)
 		return false
 	}
 
+	if !fo.allowedByPolicy(OpMove, append(append([]string{}, sourcePaths...), transferTargets(sourcePaths, destDir)...)...) {
+		return false
+	}
+
 	// ROLLBACK MECHANISM: Track moves for potential rollback
 	type moveRecord struct {
 		srcPath  string
@@ -286,6 +314,10 @@ func (fo *FileOperationsManager) MoveFiles(sourcePaths []string, destDir string)
 func (fo *FileOperationsManager) DeleteFiles(filePaths []string) bool {
 	log.Printf("Permanently deleting %d files", len(filePaths))
 
+	if !fo.allowedByPolicy(OpDelete, filePaths...) {
+		return false
+	}
+
 	for _, filePath := range filePaths {
 		err := os.RemoveAll(filePath)
 		if err != nil {
@@ -297,33 +329,10 @@ func (fo *FileOperationsManager) DeleteFiles(filePaths []string) bool {
 	return true
 }
 
-// MoveFilesToRecycleBin moves files to the system recycle bin/trash using native APIs
+// MoveFilesToRecycleBin moves files to the system recycle bin/trash using native APIs.
+// Nothing is ever deleted permanently here; see RecycleFiles for the details of a failure.
 func (fo *FileOperationsManager) MoveFilesToRecycleBin(filePaths []string) bool {
-	log.Printf("Moving %d files to recycle bin", len(filePaths))
-
-	if runtime.GOOS == "windows" {
-		if fo.moveToWindowsRecycleBinNative(filePaths) {
-			return true
-		}
-		log.Printf("Native recycle bin call failed; falling back to permanent delete")
-		for _, path := range filePaths {
-			if err := os.RemoveAll(path); err != nil {
-				log.Printf("Failed to remove %s: %v", path, err)
-				return false
-			}
-		}
-		return true
-	}
-
-	for _, filePath := range filePaths {
-		success := fo.moveToRecycleBin(filePath)
-		if !success {
-			log.Printf("Error moving %s to recycle bin", filePath)
-			return false
-		}
-	}
-
-	return true
+	return fo.RecycleFiles(filePaths).Success
 }
 
 // RenameFile renames a file or directory with comprehensive security validation
@@ -367,8 +376,13 @@ func (fo *FileOperationsManager) RenameFile(oldPath, newName string) bool {
 		return false
 	}
 
+	// Protected locations (system folders, drive roots, ...) are governed by the path policy
+	if !fo.allowedByPolicy(OpRename, cleanOldPath, newPath) {
+		return false
+	}
+
 	// Check if old path exists
-	oldInfo, err := os.Stat(cleanOldPath)
+	_, err = os.Stat(cleanOldPath)
 	if os.IsNotExist(err) {
 		log.Printf("Error: Source file does not exist: %s", cleanOldPath)
 		return false
@@ -384,19 +398,6 @@ func (fo *FileOperationsManager) RenameFile(oldPath, newName string) bool {
 		return false
 	}
 
-	// Additional validation for directories to prevent system damage
-	if oldInfo.IsDir() {
-		// Prevent renaming critical system directories
-		systemDirs := []string{"Windows", "System32", "Program Files", "Program Files (x86)"}
-		oldBaseName := strings.ToLower(filepath.Base(cleanOldPath))
-		for _, sysDir := range systemDirs {
-			if strings.ToLower(sysDir) == oldBaseName {
-				log.Printf("Error: Cannot rename system directory: %s", oldBaseName)
-				return false
-			}
-		}
-	}
-
 	// Perform the rename with error handling
 	err = os.Rename(cleanOldPath, newPath)
 	if err != nil {
@@ -412,6 +413,10 @@ func (fo *FileOperationsManager) RenameFile(oldPath, newName string) bool {
 func (fo *FileOperationsManager) HideFiles(filePaths []string) bool {
 	log.Printf("Hiding %d files", len(filePaths))
 
+	if !fo.allowedByPolicy(OpHide, filePaths...) {
+		return false
+	}
+
 	for _, filePath := range filePaths {
 		success := fo.platform.HideFile(filePath)
 		if !success {
@@ -424,6 +429,25 @@ func (fo *FileOperationsManager) HideFiles(filePaths []string) bool {
 	return true
 }
 
+// UnhideFiles clears the hidden attribute on the specified files
+func (fo *FileOperationsManager) UnhideFiles(filePaths []string) bool {
+	log.Printf("Unhiding %d files", len(filePaths))
+
+	if !fo.allowedByPolicy(OpHide, filePaths...) {
+		return false
+	}
+
+	for _, filePath := range filePaths {
+		if !fo.platform.UnhideFile(filePath) {
+			log.Printf("Error unhiding file: %s", filePath)
+			return false
+		}
+	}
+
+	log.Printf("Successfully unhid %d files", len(filePaths))
+	return true
+}
+
 // OpenFile opens a file with its default application
 func (fo *FileOperationsManager) OpenFile(filePath string) bool {
 	return fo.platform.OpenFile(filePath)
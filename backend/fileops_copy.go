@@ -1,12 +1,17 @@
 package backend
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"runtime"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 const (
@@ -18,41 +23,177 @@ var bufferPool = sync.Pool{New: func() interface{} {
 	return make([]byte, copyBufferSize)
 }}
 
+// copySession carries the options of one copy or move operation and the
+// hardlinks seen so far, so links between files in the set are recreated.
+// Cancelling ctx stops the operation before its next file or copy chunk.
+type copySession struct {
+	ctx  context.Context
+	opts CopyOptions
+
+	mu    sync.Mutex
+	links map[[2]uint64]string // source device/inode -> first destination path
+}
+
+func newCopySession(ctx context.Context, opts CopyOptions) *copySession {
+	return &copySession{ctx: ctx, opts: opts, links: make(map[[2]uint64]string)}
+}
+
+// progress reports n more bytes copied to opts.Progress
+func (s *copySession) progress(n int64) {
+	if s.opts.Progress != nil {
+		s.opts.Progress(n)
+	}
+}
+
+// stat returns info for src, not following symlinks when they are copied as links
+func (s *copySession) stat(src string) (os.FileInfo, error) {
+	if s.opts.PreserveSymlinks {
+		return os.Lstat(src)
+	}
+	return os.Stat(src)
+}
+
 func (fo *FileOperationsManager) copyFile(src, dst string) error {
-	sourceFile, err := os.Open(src)
+	return fo.copyFileWith(src, dst, newCopySession(context.Background(), CopyOptions{}))
+}
+
+func (fo *FileOperationsManager) copyDir(src, dst string) error {
+	return fo.copyDirWith(src, dst, newCopySession(context.Background(), CopyOptions{}))
+}
+
+func (fo *FileOperationsManager) copyFileWith(src, dst string, s *copySession) error {
+	srcInfo, err := s.stat(src)
 	if err != nil {
 		return err
 	}
-	defer sourceFile.Close()
+	if srcInfo.Mode()&os.ModeSymlink != 0 {
+		return s.copySymlink(src, dst, srcInfo)
+	}
+	if srcInfo.IsDir() {
+		// A followed symlink to a directory
+		return fo.copyDirWith(src, dst, s)
+	}
+
+	// Reading the source may update its access time, so capture it first
+	atime := fileAccessTime(src, srcInfo.ModTime())
 
-	destFile, err := os.Create(dst)
+	destFile, linked, err := s.createDest(src, dst, srcInfo)
 	if err != nil {
 		return err
 	}
+	if linked {
+		s.progress(srcInfo.Size())
+		return nil
+	}
 	defer destFile.Close()
 
+	sourceFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer sourceFile.Close()
+
 	buffer := bufferPool.Get().([]byte)
 	defer bufferPool.Put(buffer)
 
-	if _, err = io.CopyBuffer(destFile, sourceFile, buffer); err != nil {
+	strategy := CopyStrategySparse
+	if s.opts.PreserveSparse && isSparseFile(src, srcInfo) {
+		err = copySparse(s.ctx, destFile, sourceFile, srcInfo.Size(), buffer, s.progress)
+	} else {
+		strategy, err = copyContents(s.ctx, destFile, sourceFile, srcInfo.Size(), buffer, s.opts.Strategy, s.progress)
+	}
+	if err != nil {
+		return err
+	}
+	s.opts.Stats.record(strategy, srcInfo.Size())
+	if err := destFile.Close(); err != nil {
 		return err
 	}
 
-	if srcInfo, err := os.Stat(src); err == nil {
-		os.Chmod(dst, srcInfo.Mode())
-		os.Chtimes(dst, srcInfo.ModTime(), srcInfo.ModTime())
+	return s.applyMetadata(src, dst, srcInfo, atime)
+}
+
+// createDest creates dst, or hardlinks it to an earlier copy of the same source
+// inode when hardlinks are preserved. The lock is held across creation so a
+// concurrent copy of another link never sees a registered but missing file.
+func (s *copySession) createDest(src, dst string, srcInfo os.FileInfo) (*os.File, bool, error) {
+	if !s.opts.PreserveHardlinks {
+		f, err := os.Create(dst)
+		return f, false, err
+	}
+	key, nlink, ok := fileIdentity(src)
+	if !ok || nlink < 2 {
+		f, err := os.Create(dst)
+		return f, false, err
 	}
 
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if first, seen := s.links[key]; seen {
+		return nil, true, os.Link(first, dst)
+	}
+	f, err := os.Create(dst)
+	if err == nil {
+		s.links[key] = dst
+	}
+	return f, false, err
+}
+
+// copySymlink recreates the link itself rather than copying its target
+func (s *copySession) copySymlink(src, dst string, srcInfo os.FileInfo) error {
+	target, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+	if err := os.Symlink(target, dst); err != nil {
+		return err
+	}
+	if s.opts.PreserveOwnership {
+		if err := copyOwnership(src, dst); err != nil {
+			return err
+		}
+	}
+	if s.opts.PreserveTimes {
+		setSymlinkTimes(dst, fileAccessTime(src, srcInfo.ModTime()), srcInfo.ModTime())
+	}
 	return nil
 }
 
-func (fo *FileOperationsManager) copyDir(src, dst string) error {
+// applyMetadata restores ownership, mode, extended attributes and times on dst.
+// Ownership goes first because chown clears setuid/setgid bits, and times go
+// last because the other changes may touch them.
+func (s *copySession) applyMetadata(src, dst string, srcInfo os.FileInfo, atime time.Time) error {
+	if s.opts.PreserveOwnership {
+		if err := copyOwnership(src, dst); err != nil {
+			return err
+		}
+	}
+	if err := os.Chmod(dst, srcInfo.Mode()); err != nil {
+		return err
+	}
+	if err := copyExtendedAttributes(src, dst, s.opts); err != nil {
+		return err
+	}
+
+	switch {
+	case s.opts.PreserveTimes:
+		return os.Chtimes(dst, atime, srcInfo.ModTime())
+	case !srcInfo.IsDir():
+		os.Chtimes(dst, srcInfo.ModTime(), srcInfo.ModTime())
+	}
+	return nil
+}
+
+func (fo *FileOperationsManager) copyDirWith(src, dst string, s *copySession) error {
 	srcInfo, err := os.Stat(src)
 	if err != nil {
 		return err
 	}
 
-	if err := os.MkdirAll(dst, srcInfo.Mode()); err != nil {
+	atime := fileAccessTime(src, srcInfo.ModTime())
+
+	// Keep the directory writable until its children are in place
+	if err := os.MkdirAll(dst, srcInfo.Mode().Perm()|0o700); err != nil {
 		return err
 	}
 
@@ -84,12 +225,16 @@ func (fo *FileOperationsManager) copyDir(src, dst string) error {
 		if failed.Load() {
 			break
 		}
+		if err := s.ctx.Err(); err != nil {
+			once.Do(func() { firstErr = err; failed.Store(true) })
+			break
+		}
 
 		srcPath := filepath.Join(src, entry.Name())
 		dstPath := filepath.Join(dst, entry.Name())
 
 		if entry.IsDir() {
-			if err := fo.copyDir(srcPath, dstPath); err != nil {
+			if err := fo.copyDirWith(srcPath, dstPath, s); err != nil {
 				once.Do(func() { firstErr = err; failed.Store(true) })
 				break
 			}
@@ -98,7 +243,7 @@ func (fo *FileOperationsManager) copyDir(src, dst string) error {
 
 		sem <- struct{}{}
 		launch(func() {
-			if err := fo.copyFile(srcPath, dstPath); err != nil {
+			if err := fo.copyFileWith(srcPath, dstPath, s); err != nil {
 				once.Do(func() { firstErr = err; failed.Store(true) })
 			}
 		})
@@ -110,34 +255,127 @@ func (fo *FileOperationsManager) copyDir(src, dst string) error {
 		return firstErr
 	}
 
-	return nil
+	// Writing children updates the directory's mtime, so metadata comes last
+	return s.applyMetadata(src, dst, srcInfo, atime)
+}
+
+// isSparseFile reports whether fewer blocks are allocated than the size implies
+func isSparseFile(path string, info os.FileInfo) bool {
+	allocated, ok := fileAllocatedSize(path)
+	return ok && info.Mode().IsRegular() && allocated < info.Size()
+}
+
+// copySparse copies src to dst, seeking over all-zero blocks instead of writing
+// them so they stay holes, then truncates dst to size to keep a trailing hole.
+// Like copyWithBuffer it reports each chunk and stops once ctx is cancelled.
+func copySparse(ctx context.Context, dst, src *os.File, size int64, buffer []byte, progress func(int64)) error {
+	var zero [4096]byte
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, err := src.Read(buffer)
+		if n > 0 {
+			chunk := buffer[:n]
+			for len(chunk) > 0 {
+				block := chunk[:min(len(chunk), len(zero))]
+				if bytes.Equal(block, zero[:len(block)]) {
+					if _, err := dst.Seek(int64(len(block)), io.SeekCurrent); err != nil {
+						return err
+					}
+				} else if _, err := dst.Write(block); err != nil {
+					return err
+				}
+				chunk = chunk[len(block):]
+			}
+			progress(int64(n))
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return dst.Truncate(size)
+}
+
+// sourceRemovalError reports a copy-and-delete move whose copy was complete
+// and verified but whose source could only be partly removed. The copy is then
+// the only complete one, so callers must keep it.
+type sourceRemovalError struct {
+	src string
+	err error
 }
 
+func (e *sourceRemovalError) Error() string {
+	return fmt.Sprintf("copied %s but could not remove all of the original: %v", e.src, e.err)
+}
+
+func (e *sourceRemovalError) Unwrap() error { return e.err }
+
+// archiveCopyOptions preserves everything, like cp -a. Moves that have to copy
+// use it so the result matches what a rename would have kept.
+func archiveCopyOptions() CopyOptions {
+	return CopyOptions{PreserveTimes: true, PreserveOwnership: true, PreserveXattrs: true, PreserveACLs: true,
+		PreserveSymlinks: true, PreserveHardlinks: true, PreserveSparse: true}
+}
+
+// copyAndDelete moves src to a dst that does not exist yet, for when a rename
+// cannot: it copies, verifies the copy and only then removes src. If copying
+// or verifying fails, src is untouched and the partial dst is removed. Once
+// removal has started dst is never touched; failures are sourceRemovalErrors.
 func (fo *FileOperationsManager) copyAndDelete(src, dst string) error {
-	srcInfo, err := os.Stat(src)
+	// Lstat so that symlinks, dangling ones included, move as links
+	srcInfo, err := os.Lstat(src)
 	if err != nil {
 		return err
 	}
 
+	session := newCopySession(context.Background(), archiveCopyOptions())
 	if srcInfo.IsDir() {
-		return fo.copyDirAndDelete(src, dst)
+		err = fo.copyDirWith(src, dst, session)
+	} else {
+		err = fo.copyFileWith(src, dst, session)
 	}
-
-	return fo.copyFileAndDelete(src, dst)
-}
-
-func (fo *FileOperationsManager) copyFileAndDelete(src, dst string) error {
-	if err := fo.copyFile(src, dst); err != nil {
+	if err == nil {
+		err = verifyCopy(src, dst)
+	}
+	if err != nil {
+		os.RemoveAll(dst)
 		return err
 	}
 
-	return os.Remove(src)
-}
-
-func (fo *FileOperationsManager) copyDirAndDelete(src, dst string) error {
-	if err := fo.copyDir(src, dst); err != nil {
-		return err
+	if err := os.RemoveAll(src); err != nil {
+		return &sourceRemovalError{src: src, err: err}
 	}
+	return nil
+}
 
-	return os.RemoveAll(src)
+// verifyCopy checks that every entry under src exists in dst with the same
+// type, and that regular files have the same size
+func verifyCopy(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		srcInfo, err := d.Info()
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		dstInfo, err := os.Lstat(target)
+		if err != nil {
+			return fmt.Errorf("copy verification failed: %w", err)
+		}
+		if srcInfo.Mode().Type() != dstInfo.Mode().Type() ||
+			(srcInfo.Mode().IsRegular() && srcInfo.Size() != dstInfo.Size()) {
+			return fmt.Errorf("copy verification failed: %s does not match %s", target, path)
+		}
+		return nil
+	})
 }
@@ -0,0 +1,391 @@
+//go:build linux
+
+package backend
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func copyTree(t *testing.T, src, dst string, opts CopyOptions) {
+	t.Helper()
+	fo := &FileOperationsManager{}
+	if err := fo.copyDirWith(src, dst, newCopySession(context.Background(), opts)); err != nil {
+		t.Fatalf("copyDirWith: %v", err)
+	}
+}
+
+func statT(t *testing.T, path string) *syscall.Stat_t {
+	t.Helper()
+	info, err := os.Lstat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Sys().(*syscall.Stat_t)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCopyPreservesSymlinks(t *testing.T) {
+	src, dst := t.TempDir(), filepath.Join(t.TempDir(), "out")
+	writeFile(t, filepath.Join(src, "file"), "data")
+	os.Mkdir(filepath.Join(src, "dir"), 0o755)
+	links := map[string]string{
+		"to-file":  "file",
+		"to-dir":   "dir",
+		"dangling": "does-not-exist",
+		"absolute": "/nonexistent/target",
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(src, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	copyTree(t, src, dst, CopyOptions{PreserveSymlinks: true})
+
+	for name, want := range links {
+		info, err := os.Lstat(filepath.Join(dst, name))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			t.Errorf("%s: copied as %v, want a symlink", name, info.Mode())
+			continue
+		}
+		if got, _ := os.Readlink(filepath.Join(dst, name)); got != want {
+			t.Errorf("%s: points to %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestCopySingleDanglingSymlink(t *testing.T) {
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "link"), filepath.Join(dir, "copy")
+	if err := os.Symlink("missing", src); err != nil {
+		t.Fatal(err)
+	}
+
+	fo := &FileOperationsManager{}
+	if err := fo.copyFileWith(src, dst, newCopySession(context.Background(), CopyOptions{PreserveSymlinks: true})); err != nil {
+		t.Fatalf("copyFileWith: %v", err)
+	}
+	if got, err := os.Readlink(dst); err != nil || got != "missing" {
+		t.Fatalf("Readlink = %q, %v; want %q", got, err, "missing")
+	}
+}
+
+func TestCopyFollowsSymlinksByDefault(t *testing.T) {
+	src, dst := t.TempDir(), filepath.Join(t.TempDir(), "out")
+	writeFile(t, filepath.Join(src, "file"), "data")
+	os.Symlink("file", filepath.Join(src, "link"))
+
+	copyTree(t, src, dst, CopyOptions{})
+
+	info, err := os.Lstat(filepath.Join(dst, "link"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Mode().IsRegular() {
+		t.Fatalf("link copied as %v, want a regular file", info.Mode())
+	}
+}
+
+func TestCopyRecreatesHardlinksWithinSet(t *testing.T) {
+	outside := t.TempDir()
+	src, dst := t.TempDir(), filepath.Join(t.TempDir(), "out")
+	writeFile(t, filepath.Join(src, "a"), "shared")
+	writeFile(t, filepath.Join(outside, "external"), "external")
+	for _, link := range [][2]string{
+		{filepath.Join(src, "a"), filepath.Join(src, "b")},
+		{filepath.Join(src, "a"), filepath.Join(src, "sub", "c")},
+		{filepath.Join(outside, "external"), filepath.Join(src, "lone")},
+	} {
+		os.MkdirAll(filepath.Dir(link[1]), 0o755)
+		if err := os.Link(link[0], link[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	copyTree(t, src, dst, CopyOptions{PreserveHardlinks: true})
+
+	a := statT(t, filepath.Join(dst, "a"))
+	for _, name := range []string{"b", filepath.Join("sub", "c")} {
+		if st := statT(t, filepath.Join(dst, name)); st.Ino != a.Ino {
+			t.Errorf("%s is not linked to a", name)
+		}
+	}
+	if a.Nlink != 3 {
+		t.Errorf("a has %d links, want 3", a.Nlink)
+	}
+	// The other link of "lone" is outside the copied set, so it gets its own file
+	if st := statT(t, filepath.Join(dst, "lone")); st.Nlink != 1 {
+		t.Errorf("lone has %d links, want 1", st.Nlink)
+	}
+	if st := statT(t, filepath.Join(src, "a")); st.Ino == a.Ino {
+		t.Error("copy is linked to the source")
+	}
+}
+
+// Moves that cannot rename must keep everything a rename would
+func TestCopyAndDeleteKeepsMetadata(t *testing.T) {
+	src, dst := filepath.Join(t.TempDir(), "src"), filepath.Join(t.TempDir(), "dst")
+	writeFile(t, filepath.Join(src, "a"), "shared")
+	if err := os.Link(filepath.Join(src, "a"), filepath.Join(src, "b")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(src, "a"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	xattrs := true
+	if err := setXattr(filepath.Join(src, "a"), "user.origin", []byte("test")); err != nil {
+		if !isXattrUnsupported(err) {
+			t.Fatal(err)
+		}
+		xattrs = false
+	}
+
+	fo := &FileOperationsManager{}
+	if err := fo.copyAndDelete(src, dst); err != nil {
+		t.Fatalf("copyAndDelete: %v", err)
+	}
+
+	if _, err := os.Lstat(src); !os.IsNotExist(err) {
+		t.Errorf("source still exists: %v", err)
+	}
+	a, b := statT(t, filepath.Join(dst, "a")), statT(t, filepath.Join(dst, "b"))
+	if a.Ino != b.Ino {
+		t.Error("hardlinked files were split by the move")
+	}
+	if mode := os.FileMode(a.Mode).Perm(); mode != 0o640 {
+		t.Errorf("mode = %o, want 640", mode)
+	}
+	if xattrs {
+		if value, err := getXattr(filepath.Join(dst, "a"), "user.origin"); err != nil || string(value) != "test" {
+			t.Errorf("user.origin = %q, %v; want \"test\"", value, err)
+		}
+	}
+}
+
+func TestCopyWithoutHardlinksMakesIndependentFiles(t *testing.T) {
+	src, dst := t.TempDir(), filepath.Join(t.TempDir(), "out")
+	writeFile(t, filepath.Join(src, "a"), "shared")
+	os.Link(filepath.Join(src, "a"), filepath.Join(src, "b"))
+
+	copyTree(t, src, dst, CopyOptions{})
+
+	if statT(t, filepath.Join(dst, "a")).Ino == statT(t, filepath.Join(dst, "b")).Ino {
+		t.Fatal("a and b were linked without PreserveHardlinks")
+	}
+}
+
+func TestCopyPreservesSparseHoles(t *testing.T) {
+	src, dst := t.TempDir(), filepath.Join(t.TempDir(), "out")
+	const size = 16 << 20
+	f, err := os.Create(filepath.Join(src, "sparse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteAt([]byte("middle"), size/2)
+	f.WriteAt([]byte("end"), size-3)
+	f.Close()
+
+	if allocated := int64(statT(t, filepath.Join(src, "sparse")).Blocks) * 512; allocated >= size {
+		t.Skip("the temp filesystem does not support sparse files")
+	}
+
+	copyTree(t, src, dst, CopyOptions{PreserveSparse: true})
+
+	copied := filepath.Join(dst, "sparse")
+	if allocated := int64(statT(t, copied).Blocks) * 512; allocated >= size/4 {
+		t.Errorf("copy allocates %d bytes of %d, want the holes kept", allocated, size)
+	}
+	want, _ := os.ReadFile(filepath.Join(src, "sparse"))
+	got, err := os.ReadFile(copied)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("sparse copy has different contents")
+	}
+}
+
+func TestCopySparseKeepsTrailingHole(t *testing.T) {
+	src, dst := t.TempDir(), filepath.Join(t.TempDir(), "out")
+	const size = 8 << 20
+	f, err := os.Create(filepath.Join(src, "sparse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteAt([]byte("start"), 0)
+	f.Truncate(size)
+	f.Close()
+
+	copyTree(t, src, dst, CopyOptions{PreserveSparse: true})
+
+	info, err := os.Stat(filepath.Join(dst, "sparse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != size {
+		t.Fatalf("copy is %d bytes, want %d", info.Size(), size)
+	}
+}
+
+func TestCopyPreservesFileTimes(t *testing.T) {
+	src, dst := t.TempDir(), filepath.Join(t.TempDir(), "out")
+	file := filepath.Join(src, "file")
+	writeFile(t, file, "data")
+	atime := time.Date(2020, 1, 2, 3, 4, 5, 600, time.UTC)
+	mtime := time.Date(2019, 6, 7, 8, 9, 10, 1100, time.UTC)
+	if err := os.Chtimes(file, atime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	copyTree(t, src, dst, CopyOptions{PreserveTimes: true})
+
+	st := statT(t, filepath.Join(dst, "file"))
+	if got := time.Unix(st.Atim.Unix()); !got.Equal(atime) {
+		t.Errorf("atime = %v, want %v", got, atime)
+	}
+	if got := time.Unix(st.Mtim.Unix()); !got.Equal(mtime) {
+		t.Errorf("mtime = %v, want %v", got, mtime)
+	}
+}
+
+func TestCopyAppliesDirectoryTimesLast(t *testing.T) {
+	src, dst := t.TempDir(), filepath.Join(t.TempDir(), "out")
+	writeFile(t, filepath.Join(src, "sub", "nested", "file"), "data")
+	writeFile(t, filepath.Join(src, "sub", "file"), "data")
+
+	// Set times bottom-up so writing children does not disturb them
+	times := map[string]time.Time{
+		filepath.Join("sub", "nested"): time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC),
+		"sub":                          time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC),
+		".":                            time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for _, rel := range []string{filepath.Join("sub", "nested"), "sub", "."} {
+		if err := os.Chtimes(filepath.Join(src, rel), times[rel], times[rel]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	copyTree(t, src, dst, CopyOptions{PreserveTimes: true})
+
+	for rel, want := range times {
+		info, err := os.Stat(filepath.Join(dst, rel))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !info.ModTime().Equal(want) {
+			t.Errorf("%s: mtime = %v, want %v", rel, info.ModTime(), want)
+		}
+	}
+}
+
+func TestCopyOwnershipWithoutPrivileges(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("needs an unprivileged user")
+	}
+	// A root-owned file we can read but not give away
+	const rootFile = "/etc/passwd"
+	info, err := os.Stat(rootFile)
+	if err != nil || info.Sys().(*syscall.Stat_t).Uid != 0 {
+		t.Skip("no readable root-owned file")
+	}
+
+	dst := filepath.Join(t.TempDir(), "passwd")
+	fo := &FileOperationsManager{}
+	if err := fo.copyFileWith(rootFile, dst, newCopySession(context.Background(), CopyOptions{PreserveOwnership: true})); err != nil {
+		t.Fatalf("copyFileWith: %v", err)
+	}
+	if uid := statT(t, dst).Uid; uid != uint32(os.Geteuid()) {
+		t.Errorf("copy owned by %d, want the current user %d", uid, os.Geteuid())
+	}
+}
+
+func TestCopyOwnershipWithPrivileges(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("needs root")
+	}
+	src, dst := t.TempDir(), filepath.Join(t.TempDir(), "out")
+	file := filepath.Join(src, "file")
+	writeFile(t, file, "data")
+	os.Symlink("file", filepath.Join(src, "link"))
+	const uid, gid = 12345, 23456
+	for _, path := range []string{file, filepath.Join(src, "link")} {
+		if err := os.Lchown(path, uid, gid); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	copyTree(t, src, dst, CopyOptions{PreserveOwnership: true, PreserveSymlinks: true})
+
+	for _, name := range []string{"file", "link"} {
+		if st := statT(t, filepath.Join(dst, name)); st.Uid != uid || st.Gid != gid {
+			t.Errorf("%s owned by %d:%d, want %d:%d", name, st.Uid, st.Gid, uid, gid)
+		}
+	}
+}
+
+func TestCopyJobReportsBytesPerFile(t *testing.T) {
+	src := t.TempDir()
+	writeFile(t, filepath.Join(src, "dir", "a"), strings.Repeat("a", 1000))
+	writeFile(t, filepath.Join(src, "dir", "b"), strings.Repeat("b", 3000))
+	writeFile(t, filepath.Join(src, "c"), strings.Repeat("c", 500))
+	dest := t.TempDir()
+
+	jobs := NewJobManager()
+	var seen []int64
+	id := jobs.Start("copy", func(ctx context.Context, job *Job) error {
+		opts := CopyOptions{}
+		finish, err := trackTransfer(ctx, job, []string{filepath.Join(src, "dir"), filepath.Join(src, "c")}, &opts)
+		if err != nil {
+			return err
+		}
+		if total := job.Info().Total; total != 4500 {
+			t.Errorf("total = %d, want the bytes to copy", total)
+		}
+		report := opts.Progress
+		var mu sync.Mutex
+		opts.Progress = func(n int64) {
+			report(n)
+			mu.Lock()
+			seen = append(seen, n)
+			mu.Unlock()
+		}
+		fo := &FileOperationsManager{}
+		if !fo.CopyFilesWithOptions(ctx, []string{filepath.Join(src, "dir"), filepath.Join(src, "c")}, dest, opts) {
+			t.Error("copy failed")
+		}
+		if done := job.Info().Done; done != 4500 {
+			t.Errorf("done = %d after copying, want 4500", done)
+		}
+		finish()
+		return nil
+	})
+	info, _ := jobs.Wait(id)
+	if info.Done != info.Total {
+		t.Errorf("finished at %d/%d", info.Done, info.Total)
+	}
+	slices.Sort(seen)
+	if !slices.Equal(seen, []int64{500, 1000, 3000}) {
+		t.Errorf("progress reports = %v, want one per file", seen)
+	}
+}
@@ -0,0 +1,71 @@
+//go:build !windows
+
+package backend
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// fileIdentity returns the device/inode pair and link count of path, without following symlinks
+func fileIdentity(path string) (key [2]uint64, nlink uint64, ok bool) {
+	var st unix.Stat_t
+	if err := unix.Lstat(path, &st); err != nil {
+		return key, 0, false
+	}
+	return [2]uint64{uint64(st.Dev), uint64(st.Ino)}, uint64(st.Nlink), true
+}
+
+// fileAccessTime returns the last access time of path, falling back to modTime
+func fileAccessTime(path string, modTime time.Time) time.Time {
+	var st unix.Stat_t
+	if err := unix.Lstat(path, &st); err != nil {
+		return modTime
+	}
+	return time.Unix(st.Atim.Unix())
+}
+
+// fileAllocatedSize returns the bytes actually allocated on disk for path
+func fileAllocatedSize(path string) (int64, bool) {
+	var st unix.Stat_t
+	if err := unix.Lstat(path, &st); err != nil {
+		return 0, false
+	}
+	return int64(st.Blocks) * 512, true
+}
+
+// allocatedSize is fileAllocatedSize for an entry that was already stat'ed
+func allocatedSize(info os.FileInfo) (int64, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return int64(st.Blocks) * 512, true
+}
+
+// copyOwnership gives dst the owner and group of src. Unprivileged users can
+// only keep the group, and only when they belong to it; anything else is left as is.
+func copyOwnership(src, dst string) error {
+	var st unix.Stat_t
+	if err := unix.Lstat(src, &st); err != nil {
+		return err
+	}
+	err := os.Lchown(dst, int(st.Uid), int(st.Gid))
+	if errors.Is(err, os.ErrPermission) {
+		if err := os.Lchown(dst, -1, int(st.Gid)); err != nil && !errors.Is(err, os.ErrPermission) {
+			return err
+		}
+		return nil
+	}
+	return err
+}
+
+// setSymlinkTimes sets the times of a symlink itself rather than its target
+func setSymlinkTimes(path string, atime, mtime time.Time) error {
+	ts := []unix.Timespec{unix.NsecToTimespec(atime.UnixNano()), unix.NsecToTimespec(mtime.UnixNano())}
+	return unix.UtimesNanoAt(unix.AT_FDCWD, path, ts, unix.AT_SYMLINK_NOFOLLOW)
+}
@@ -0,0 +1,32 @@
+//go:build windows
+
+package backend
+
+import (
+	"os"
+	"time"
+)
+
+func fileIdentity(path string) (key [2]uint64, nlink uint64, ok bool) {
+	return key, 0, false
+}
+
+func fileAccessTime(path string, modTime time.Time) time.Time {
+	return modTime
+}
+
+func fileAllocatedSize(path string) (int64, bool) {
+	return 0, false
+}
+
+func allocatedSize(info os.FileInfo) (int64, bool) {
+	return 0, false
+}
+
+func copyOwnership(src, dst string) error {
+	return nil
+}
+
+func setSymlinkTimes(path string, atime, mtime time.Time) error {
+	return nil
+}
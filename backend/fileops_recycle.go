@@ -3,23 +3,62 @@ package backend
 import (
 	"fmt"
 	"log"
-	"os"
 	"os/exec"
-	"path/filepath"
 	"runtime"
+	"slices"
 )
 
+// RecycleFiles moves files to the recycle bin/trash. It never falls back to a
+// permanent delete: paths that could not be trashed are reported in Failed
+// with TrashUnavailable set, and it is up to the caller to ask the user.
+func (fo *FileOperationsManager) RecycleFiles(filePaths []string) RecycleResult {
+	log.Printf("Moving %d files to recycle bin", len(filePaths))
+	result := RecycleResult{Trashed: []string{}, Failed: []string{}}
+
+	if err := fo.policy.Check(OpRecycle, filePaths...); err != nil {
+		log.Printf("⛔ %v", err)
+		result.Failed = append(result.Failed, filePaths...)
+		result.Message = err.Error()
+		return result
+	}
+
+	if runtime.GOOS == "windows" {
+		// One shell call for the whole selection is much faster on Windows
+		failed := fo.recycleWindows(filePaths)
+		for _, filePath := range filePaths {
+			if !slices.Contains(failed, filePath) {
+				result.Trashed = append(result.Trashed, filePath)
+			}
+		}
+		result.Failed = append(result.Failed, failed...)
+	} else {
+		for _, filePath := range filePaths {
+			if fo.moveToRecycleBin(filePath) {
+				result.Trashed = append(result.Trashed, filePath)
+				continue
+			}
+			log.Printf("Error moving %s to recycle bin", filePath)
+			result.Failed = append(result.Failed, filePath)
+		}
+	}
+
+	result.Success = len(result.Failed) == 0
+	if !result.Success {
+		result.TrashUnavailable = true
+		result.Message = fmt.Sprintf("%d of %d items could not be moved to the recycle bin", len(result.Failed), len(filePaths))
+	}
+	return result
+}
+
 func (fo *FileOperationsManager) moveToRecycleBin(filePath string) bool {
 	switch runtime.GOOS {
-	case "windows":
-		return fo.moveToWindowsRecycleBinNative([]string{filepath.Clean(filePath)})
 	case "darwin":
 		return fo.moveToMacTrash(filePath)
 	case "linux":
 		return fo.moveToLinuxTrash(filePath)
 	default:
-		log.Printf("Recycle bin not supported on %s, falling back to permanent delete", runtime.GOOS)
-		return os.RemoveAll(filePath) == nil
+		log.Printf("Recycle bin not supported on %s", runtime.GOOS)
+		return false
 	}
 }
 
@@ -29,16 +68,9 @@ func (fo *FileOperationsManager) moveToMacTrash(filePath string) bool {
 }
 
 func (fo *FileOperationsManager) moveToLinuxTrash(filePath string) bool {
-	cmd := exec.Command("gio", "trash", filePath)
-	if err := cmd.Run(); err != nil {
-		cmd = exec.Command("gvfs-trash", filePath)
-		if err := cmd.Run(); err != nil {
-			homeDir, _ := os.UserHomeDir()
-			trashDir := filepath.Join(homeDir, ".local", "share", "Trash", "files")
-			os.MkdirAll(trashDir, 0755)
-			trashPath := filepath.Join(trashDir, filepath.Base(filePath))
-			return os.Rename(filePath, trashPath) == nil
-		}
+	if err := fo.moveToXDGTrash(filePath); err != nil {
+		log.Printf("Failed to move %s to trash: %v", filePath, err)
+		return false
 	}
 	return true
 }
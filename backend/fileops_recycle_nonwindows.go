@@ -2,6 +2,6 @@
 
 package backend
 
-func (fo *FileOperationsManager) moveToWindowsRecycleBinNative(filePaths []string) bool {
-	return false
+func (fo *FileOperationsManager) recycleWindows(filePaths []string) []string {
+	return filePaths
 }
@@ -4,32 +4,62 @@ package backend
 
 import (
 	"log"
+	"os"
 	"path/filepath"
 	"syscall"
 	"unsafe"
 )
 
-func (fo *FileOperationsManager) moveToWindowsRecycleBinNative(filePaths []string) bool {
-	log.Printf("Moving files to Windows Recycle Bin using native API")
+const (
+	// FOF_WANTNUKEWARNING makes the shell ask before it deletes an item it
+	// cannot recycle, instead of silently deleting it under FOF_NOCONFIRMATION
+	FOF_WANTNUKEWARNING = 0x4000
+	FOF_NOERRORUI       = 0x0400
+)
 
-	if len(filePaths) == 0 {
-		return true
-	}
+var shQueryRecycleBinW = shell32.NewProc("SHQueryRecycleBinW")
+
+// shQueryRBInfo is SHQUERYRBINFO
+type shQueryRBInfo struct {
+	CbSize      uint32
+	I64Size     int64
+	I64NumItems int64
+}
+
+// recycleWindows moves paths to the Recycle Bin and returns those still in
+// place. Volumes without a Recycle Bin (network shares, most removable media)
+// are skipped up front; for anything else the shell cannot recycle, such as
+// items larger than the bin, FOF_WANTNUKEWARNING makes it ask rather than
+// delete, and a refusal leaves the item in place to be reported.
+func (fo *FileOperationsManager) recycleWindows(filePaths []string) []string {
+	log.Printf("Moving files to Windows Recycle Bin using native API")
 
+	var failed, recyclable []string
 	var pathsUTF16 []uint16
 	for _, path := range filePaths {
 		clean := filepath.Clean(path)
 		if !filepath.IsAbs(clean) {
 			log.Printf("Error: File path must be absolute: %s", path)
-			return false
+			failed = append(failed, path)
+			continue
+		}
+		if !hasRecycleBin(clean) {
+			log.Printf("No Recycle Bin on the volume of %s", clean)
+			failed = append(failed, path)
+			continue
 		}
 		pathUTF16, err := syscall.UTF16FromString(clean)
 		if err != nil {
 			log.Printf("Failed to convert path to UTF16: %s, error: %v", clean, err)
-			return false
+			failed = append(failed, path)
+			continue
 		}
 		pathsUTF16 = append(pathsUTF16, pathUTF16[:len(pathUTF16)-1]...)
 		pathsUTF16 = append(pathsUTF16, 0)
+		recyclable = append(recyclable, path)
+	}
+	if len(recyclable) == 0 {
+		return failed
 	}
 	pathsUTF16 = append(pathsUTF16, 0)
 
@@ -38,14 +68,31 @@ func (fo *FileOperationsManager) moveToWindowsRecycleBinNative(filePaths []strin
 		WFunc:  FO_DELETE,
 		PFrom:  uintptr(unsafe.Pointer(&pathsUTF16[0])),
 		PTo:    0,
-		FFlags: FOF_ALLOWUNDO | FOF_NOCONFIRMATION | FOF_SILENT,
+		FFlags: FOF_ALLOWUNDO | FOF_NOCONFIRMATION | FOF_WANTNUKEWARNING | FOF_NOERRORUI | FOF_SILENT,
 	}
 
 	ret, _, err := shFileOperationW.Call(uintptr(unsafe.Pointer(&fileOp)))
-	if ret != 0 {
-		log.Printf("SHFileOperationW failed with code %d: %v", ret, err)
-		return false
+	if ret != 0 || fileOp.FAnyOperationsAborted != 0 {
+		log.Printf("SHFileOperationW returned %d (aborted: %v): %v", ret, fileOp.FAnyOperationsAborted != 0, err)
+	}
+
+	// The shell reports one result for the whole list, so check what is left
+	for _, path := range recyclable {
+		if _, err := os.Lstat(path); !os.IsNotExist(err) {
+			failed = append(failed, path)
+		}
 	}
+	return failed
+}
 
-	return true
+// hasRecycleBin reports whether the volume holding path has a Recycle Bin
+func hasRecycleBin(path string) bool {
+	root := filepath.VolumeName(path) + `\`
+	rootUTF16, err := syscall.UTF16PtrFromString(root)
+	if err != nil {
+		return false
+	}
+	info := shQueryRBInfo{CbSize: uint32(unsafe.Sizeof(shQueryRBInfo{}))}
+	ret, _, _ := shQueryRecycleBinW.Call(uintptr(unsafe.Pointer(rootUTF16)), uintptr(unsafe.Pointer(&info)))
+	return ret == 0 // S_OK
 }
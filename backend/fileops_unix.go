@@ -3,17 +3,24 @@
 package backend
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 )
 
 // NewFileOperationsManager creates a new file operations manager instance
 func NewFileOperationsManager(platform PlatformManagerInterface) *FileOperationsManager {
-	return &FileOperationsManager{platform: platform}
+	return &FileOperationsManager{platform: platform, policy: NewPathPolicy(platform)}
 }
 
 // CopyFiles copies files from source paths to destination directory with rollback support
 func (fo *FileOperationsManager) CopyFiles(sourcePaths []string, destDir string) bool {
+	return fo.CopyFilesWithOptions(context.Background(), sourcePaths, destDir, CopyOptions{})
+}
+
+// CopyFilesWithOptions copies files like CopyFiles, carrying over the metadata
+// selected by opts. Cancelling ctx stops before the next file and rolls back.
+func (fo *FileOperationsManager) CopyFilesWithOptions(ctx context.Context, sourcePaths []string, destDir string, opts CopyOptions) bool {
 	logPrintf("Copying %d files to: %s", len(sourcePaths), destDir)
 
 	if len(sourcePaths) == 0 {
@@ -25,6 +32,11 @@ func (fo *FileOperationsManager) CopyFiles(sourcePaths []string, destDir string)
 		return false
 	}
 
+	if !validCopyStrategy(opts.Strategy) {
+		logPrintf("Error: Unknown copy strategy: %s", opts.Strategy)
+		return false
+	}
+
 	destInfo, err := os.Stat(destDir)
 	if err != nil {
 		logPrintf("Error: Cannot access destination directory: %v", err)
@@ -35,6 +47,11 @@ func (fo *FileOperationsManager) CopyFiles(sourcePaths []string, destDir string)
 		return false
 	}
 
+	if !fo.allowedByPolicy(OpCopy, transferTargets(sourcePaths, destDir)...) {
+		return false
+	}
+
+	session := newCopySession(ctx, opts)
 	var copiedFiles []string
 	defer func() {
 		if len(copiedFiles) > 0 && len(copiedFiles) < len(sourcePaths) {
@@ -50,22 +67,29 @@ func (fo *FileOperationsManager) CopyFiles(sourcePaths []string, destDir string)
 			logPrintf("Error: Empty source path found")
 			return false
 		}
-		if _, err := os.Stat(srcPath); err != nil {
+		if _, err := session.stat(srcPath); err != nil {
 			logPrintf("Error: Cannot access source file %s: %v", srcPath, err)
 			return false
 		}
 		destPath := filepath.Join(destDir, filepath.Base(srcPath))
-		if _, err := os.Stat(destPath); err == nil {
+		if _, err := os.Lstat(destPath); err == nil {
 			logPrintf("Error: Destination file already exists: %s", destPath)
 			return false
 		}
 	}
 
-	return fo.copyFilesStandardWithRollback(sourcePaths, destDir, &copiedFiles)
+	return fo.copyFilesStandardWithRollback(sourcePaths, destDir, &copiedFiles, session)
 }
 
 // MoveFiles moves files from source paths to destination directory with rollback
 func (fo *FileOperationsManager) MoveFiles(sourcePaths []string, destDir string) bool {
+	return fo.MoveFilesWithOptions(context.Background(), sourcePaths, destDir, CopyOptions{})
+}
+
+// MoveFilesWithOptions moves files like MoveFiles. A rename keeps all metadata;
+// opts applies when a move falls back to copy and delete across filesystems.
+// Cancelling ctx stops before the next file and moves the finished ones back.
+func (fo *FileOperationsManager) MoveFilesWithOptions(ctx context.Context, sourcePaths []string, destDir string, opts CopyOptions) bool {
 	logPrintf("Moving %d files to: %s", len(sourcePaths), destDir)
 
 	if len(sourcePaths) == 0 {
@@ -87,6 +111,11 @@ func (fo *FileOperationsManager) MoveFiles(sourcePaths []string, destDir string)
 		return false
 	}
 
+	if !fo.allowedByPolicy(OpMove, append(append([]string{}, sourcePaths...), transferTargets(sourcePaths, destDir)...)...) {
+		return false
+	}
+
+	session := newCopySession(ctx, opts)
 	type moveRecord struct {
 		srcPath string
 		dstPath string
@@ -107,11 +136,18 @@ func (fo *FileOperationsManager) MoveFiles(sourcePaths []string, destDir string)
 	}
 
 	for _, srcPath := range sourcePaths {
+		if err := ctx.Err(); err != nil {
+			logPrintf("Move cancelled: %v", err)
+			rollback()
+			return false
+		}
 		destPath := filepath.Join(destDir, filepath.Base(srcPath))
 		wasCopy := false
 		if err := os.Rename(srcPath, destPath); err != nil {
-			if err := fo.copyDirOrFile(srcPath, destPath); err != nil {
+			if err := fo.copyDirOrFile(srcPath, destPath, session); err != nil {
 				logPrintf("Error moving %s: %v", srcPath, err)
+				// The source is untouched; drop the partial copy
+				os.RemoveAll(destPath)
 				rollback()
 				return false
 			}
@@ -129,20 +165,23 @@ func (fo *FileOperationsManager) MoveFiles(sourcePaths []string, destDir string)
 }
 
 // helper to copy file or directory
-func (fo *FileOperationsManager) copyDirOrFile(src, dst string) error {
-	info, err := os.Stat(src)
+func (fo *FileOperationsManager) copyDirOrFile(src, dst string, session *copySession) error {
+	info, err := session.stat(src)
 	if err != nil {
 		return err
 	}
 	if info.IsDir() {
-		return fo.copyDir(src, dst)
+		return fo.copyDirWith(src, dst, session)
 	}
-	return fo.copyFile(src, dst)
+	return fo.copyFileWith(src, dst, session)
 }
 
 // DeleteFiles permanently deletes the specified files and directories
 func (fo *FileOperationsManager) DeleteFiles(filePaths []string) bool {
 	logPrintf("Permanently deleting %d files", len(filePaths))
+	if !fo.allowedByPolicy(OpDelete, filePaths...) {
+		return false
+	}
 	for _, filePath := range filePaths {
 		if err := os.RemoveAll(filePath); err != nil {
 			logPrintf("Error permanently deleting %s: %v", filePath, err)
@@ -152,16 +191,10 @@ func (fo *FileOperationsManager) DeleteFiles(filePaths []string) bool {
 	return true
 }
 
-// MoveFilesToRecycleBin moves files to the system recycle bin/trash using platform tools
+// MoveFilesToRecycleBin moves files to the system recycle bin/trash.
+// Nothing is ever deleted permanently here; see RecycleFiles for the details of a failure.
 func (fo *FileOperationsManager) MoveFilesToRecycleBin(filePaths []string) bool {
-	logPrintf("Moving %d files to recycle bin", len(filePaths))
-	for _, filePath := range filePaths {
-		if !fo.moveToRecycleBin(filePath) {
-			logPrintf("Error moving %s to recycle bin", filePath)
-			return false
-		}
-	}
-	return true
+	return fo.RecycleFiles(filePaths).Success
 }
 
 // RenameFile renames a file or directory with validation
@@ -189,6 +222,10 @@ func (fo *FileOperationsManager) RenameFile(oldPath, newName string) bool {
 	dir := filepath.Dir(cleanOldPath)
 	newPath := filepath.Join(dir, sanitizedNewName)
 
+	if !fo.allowedByPolicy(OpRename, cleanOldPath, newPath) {
+		return false
+	}
+
 	if _, err := os.Stat(cleanOldPath); os.IsNotExist(err) {
 		logPrintf("Error: Source file does not exist: %s", cleanOldPath)
 		return false
@@ -208,6 +245,9 @@ func (fo *FileOperationsManager) RenameFile(oldPath, newName string) bool {
 // HideFiles sets the hidden attribute on the specified files
 func (fo *FileOperationsManager) HideFiles(filePaths []string) bool {
 	logPrintf("Hiding %d files", len(filePaths))
+	if !fo.allowedByPolicy(OpHide, filePaths...) {
+		return false
+	}
 	for _, filePath := range filePaths {
 		if !fo.platform.HideFile(filePath) {
 			logPrintf("Error hiding file: %s", filePath)
@@ -217,15 +257,34 @@ func (fo *FileOperationsManager) HideFiles(filePaths []string) bool {
 	return true
 }
 
+// UnhideFiles removes the leading dot from the specified files
+func (fo *FileOperationsManager) UnhideFiles(filePaths []string) bool {
+	logPrintf("Unhiding %d files", len(filePaths))
+	if !fo.allowedByPolicy(OpHide, filePaths...) {
+		return false
+	}
+	for _, filePath := range filePaths {
+		if !fo.platform.UnhideFile(filePath) {
+			logPrintf("Error unhiding file: %s", filePath)
+			return false
+		}
+	}
+	return true
+}
+
 // OpenFile opens a file with its default application
 func (fo *FileOperationsManager) OpenFile(filePath string) bool {
 	return fo.platform.OpenFile(filePath)
 }
 
 // copyFilesStandardWithRollback uses Go standard library for file copying with rollback support
-func (fo *FileOperationsManager) copyFilesStandardWithRollback(sourcePaths []string, destDir string, copiedFiles *[]string) bool {
+func (fo *FileOperationsManager) copyFilesStandardWithRollback(sourcePaths []string, destDir string, copiedFiles *[]string, session *copySession) bool {
 	for _, srcPath := range sourcePaths {
-		srcInfo, err := os.Stat(srcPath)
+		if err := session.ctx.Err(); err != nil {
+			logPrintf("Copy cancelled: %v", err)
+			return false
+		}
+		srcInfo, err := session.stat(srcPath)
 		if err != nil {
 			logPrintf("Error getting source file info: %v", err)
 			return false
@@ -235,16 +294,18 @@ func (fo *FileOperationsManager) copyFilesStandardWithRollback(sourcePaths []str
 
 		var copyErr error
 		if srcInfo.IsDir() {
-			copyErr = fo.copyDir(srcPath, destPath)
+			copyErr = fo.copyDirWith(srcPath, destPath, session)
 		} else {
-			copyErr = fo.copyFile(srcPath, destPath)
+			copyErr = fo.copyFileWith(srcPath, destPath, session)
 		}
 		if copyErr != nil {
 			logPrintf("Error copying %s: %v", srcPath, copyErr)
+			// destPath did not exist before; remove what was copied of it
+			os.RemoveAll(destPath)
 			return false
 		}
 		*copiedFiles = append(*copiedFiles, destPath)
-		if _, err := os.Stat(destPath); err != nil {
+		if _, err := os.Lstat(destPath); err != nil {
 			logPrintf("Copy verification failed for %s: %v", destPath, err)
 			return false
 		}
@@ -64,9 +64,9 @@ func (fs *FileSystemManager) ListDirectory(path string) NavigationResponse {
 
 	path = filepath.Clean(path)
 
-	info, err := os.Stat(path)
+	info, err := withPathDeadline(fs.ctx, path, func() (os.FileInfo, error) { return os.Stat(path) })
 	if err != nil {
-		return NavigationResponse{Success: false, Message: fmt.Sprintf("Cannot access path: %v", err)}
+		return NavigationResponse{Success: false, Message: fmt.Sprintf("Cannot access path: %v", err), ErrorCode: errorCode(err)}
 	}
 	if !info.IsDir() {
 		return NavigationResponse{Success: false, Message: "Path is not a directory"}
@@ -80,9 +80,9 @@ func (fs *FileSystemManager) ListDirectory(path string) NavigationResponse {
 		}
 	}
 
-	allEntries, err := fs.listDirectoryFast(path)
+	allEntries, err := fs.listDirectoryFast(fs.ctx, path)
 	if err != nil {
-		return NavigationResponse{Success: false, Message: fmt.Sprintf("Cannot read directory: %v", err)}
+		return NavigationResponse{Success: false, Message: fmt.Sprintf("Cannot read directory: %v", err), ErrorCode: errorCode(err)}
 	}
 
 	if fs.dirCache != nil {
@@ -92,9 +92,9 @@ func (fs *FileSystemManager) ListDirectory(path string) NavigationResponse {
 	return fs.buildDirectoryResponse(path, allEntries, startTime)
 }
 
-func (fs *FileSystemManager) listDirectoryFast(path string) ([]FileInfo, error) {
+func (fs *FileSystemManager) listDirectoryFast(ctx context.Context, path string) ([]FileInfo, error) {
 	entries := make([]FileInfo, 0, 256)
-	err := enumerateDirectoryBasicEnhanced(path, fs.showHidden, func(entry EnhancedBasicEntry) bool {
+	err := enumerateDirectoryBasicEnhanced(ctx, path, fs.showHidden, func(entry EnhancedBasicEntry) bool {
 		if fs.shouldSkipFile(entry.Name, entry.IsHidden) {
 			return true
 		}
@@ -194,10 +194,10 @@ func (fs *FileSystemManager) StreamDirectory(dir string) {
 		fs.eventEmitter.EmitDirectoryStart(dir)
 	}
 
-	info, err := os.Stat(dir)
+	info, err := withPathDeadline(fs.ctx, dir, func() (os.FileInfo, error) { return os.Stat(dir) })
 	if err != nil {
 		if fs.eventEmitter != nil {
-			fs.eventEmitter.EmitDirectoryError("Cannot access path: " + err.Error())
+			fs.eventEmitter.EmitDirectoryErrorCode("Cannot access path: "+err.Error(), errorCode(err))
 		}
 		return
 	}
@@ -217,7 +217,7 @@ func (fs *FileSystemManager) StreamDirectory(dir string) {
 		}
 	}
 
-	fs.streamByEnumerating(dir, modUnix)
+	fs.streamByEnumerating(fs.ctx, dir, modUnix)
 }
 
 func (fs *FileSystemManager) streamFromSnapshot(dir string, files []FileInfo) {
@@ -248,7 +248,7 @@ func (fs *FileSystemManager) streamFromSnapshot(dir string, files []FileInfo) {
 	}
 }
 
-func (fs *FileSystemManager) streamByEnumerating(dir string, modUnix int64) {
+func (fs *FileSystemManager) streamByEnumerating(ctx context.Context, dir string, modUnix int64) {
 	totalFiles, totalDirs := 0, 0
 	batchPtr := wireBatchPool.Get().(*[]WireEntry)
 	batch := (*batchPtr)[:0]
@@ -261,7 +261,7 @@ func (fs *FileSystemManager) streamByEnumerating(dir string, modUnix int64) {
 	}
 	cacheExceeded := false
 
-	err := enumerateDirectoryBasicEnhanced(dir, fs.showHidden, func(entry EnhancedBasicEntry) bool {
+	err := enumerateDirectoryBasicEnhanced(ctx, dir, fs.showHidden, func(entry EnhancedBasicEntry) bool {
 		if fs.shouldSkipFile(entry.Name, entry.IsHidden) {
 			return true
 		}
@@ -291,7 +291,7 @@ func (fs *FileSystemManager) streamByEnumerating(dir string, modUnix int64) {
 	if err != nil {
 		wireBatchPool.Put(batchPtr)
 		if fs.eventEmitter != nil {
-			fs.eventEmitter.EmitDirectoryError("Cannot read directory: " + err.Error())
+			fs.eventEmitter.EmitDirectoryErrorCode("Cannot read directory: "+err.Error(), errorCode(err))
 		}
 		return
 	}
@@ -437,6 +437,11 @@ func (fs *FileSystemManager) validateAndSanitizeFileName(name string) (string, e
 }
 
 func (fs *FileSystemManager) isPathWithinParent(childPath, parentPath string) bool {
+	return isPathWithinParent(childPath, parentPath)
+}
+
+// isPathWithinParent reports whether childPath lies inside parentPath, or is it
+func isPathWithinParent(childPath, parentPath string) bool {
 	cleanChild := filepath.Clean(childPath)
 	cleanParent := filepath.Clean(parentPath)
 
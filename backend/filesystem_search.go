@@ -0,0 +1,101 @@
+package backend
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	defaultSearchLimit = 500
+	maxSearchDepth     = 32
+)
+
+// SearchFiles walks root and returns entries whose name matches query.
+// Queries containing * or ? are treated as glob patterns, anything else as a
+// case-insensitive substring.
+func (fs *FileSystemManager) SearchFiles(root, query string, limit int) ([]FileInfo, error) {
+	if root == "" {
+		root = fs.platform.GetHomeDirectory()
+	}
+	root = filepath.Clean(root)
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("search query cannot be empty")
+	}
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if err := fs.ValidatePath(root); err != nil {
+		return nil, err
+	}
+
+	isGlob := strings.ContainsAny(query, "*?[")
+	lowerQuery := strings.ToLower(query)
+	matches := func(name string) bool {
+		if isGlob {
+			ok, _ := filepath.Match(lowerQuery, strings.ToLower(name))
+			return ok
+		}
+		return strings.Contains(strings.ToLower(name), lowerQuery)
+	}
+
+	results := make([]FileInfo, 0, 64)
+	rootDepth := strings.Count(root, string(filepath.Separator))
+	errLimitReached := fmt.Errorf("limit reached")
+
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable folders are skipped rather than aborting the search
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if path == root {
+			return nil
+		}
+
+		name := d.Name()
+		if fs.shouldSkipFile(name, fs.platform.IsHidden(path)) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if matches(name) {
+			info, err := d.Info()
+			if err == nil {
+				fi := FileInfo{
+					Name:        name,
+					Path:        path,
+					IsDir:       d.IsDir(),
+					ModTime:     info.ModTime().Unix(),
+					Permissions: info.Mode().String(),
+					Extension:   fs.platform.GetExtension(name),
+					IsHidden:    fs.platform.IsHidden(path),
+				}
+				if !d.IsDir() {
+					fi.Size = info.Size()
+				}
+				results = append(results, fi)
+				if len(results) >= limit {
+					return errLimitReached
+				}
+			}
+		}
+
+		if d.IsDir() && strings.Count(path, string(filepath.Separator))-rootDepth >= maxSearchDepth {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil && err != errLimitReached {
+		return nil, err
+	}
+
+	logPrintf("🔍 Search for %q in %s found %d results", query, root, len(results))
+	return results, nil
+}
@@ -26,7 +26,9 @@ type EnhancedBasicEntry struct {
 	Permissions string `json:"permissions"`
 }
 
-func enumerateDirectoryBasicEnhanced(dir string, includeHidden bool, fn func(EnhancedBasicEntry) bool) error {
+// enumerateDirectory calls fn for each entry of dir until fn returns false.
+// It blocks for as long as the filesystem does; see enumerateDirectoryBasicEnhanced.
+func enumerateDirectory(dir string, includeHidden bool, fn func(EnhancedBasicEntry) bool) error {
 	search := filepath.Join(dir, "*")
 	searchPtr, err := syscall.UTF16PtrFromString(search)
 	if err != nil {
@@ -140,7 +142,7 @@ func enumerateDirectoryBasicEnhanced(dir string, includeHidden bool, fn func(Enh
 
 func listDirectoryBasicEnhanced(dir string, includeHidden bool) ([]EnhancedBasicEntry, error) {
 	entries := make([]EnhancedBasicEntry, 0, 256)
-	err := enumerateDirectoryBasicEnhanced(dir, includeHidden, func(entry EnhancedBasicEntry) bool {
+	err := enumerateDirectory(dir, includeHidden, func(entry EnhancedBasicEntry) bool {
 		entries = append(entries, entry)
 		return true
 	})
@@ -3,6 +3,7 @@
 package backend
 
 import (
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -25,57 +26,75 @@ type EnhancedBasicEntry struct {
 	Permissions string `json:"permissions"`
 }
 
-func enumerateDirectoryBasicEnhanced(dir string, includeHidden bool, fn func(EnhancedBasicEntry) bool) error {
-	entries, err := os.ReadDir(dir)
+// enumerateDirectoryBatch is how many entries enumerateDirectory reads at a
+// time, so a large directory yields entries while it is still being read
+const enumerateDirectoryBatch = 256
+
+// enumerateDirectory calls fn for each entry of dir until fn returns false.
+// It blocks for as long as the filesystem does; see enumerateDirectoryBasicEnhanced.
+func enumerateDirectory(dir string, includeHidden bool, fn func(EnhancedBasicEntry) bool) error {
+	f, err := os.Open(dir)
 	if err != nil {
 		return err
 	}
+	defer f.Close()
 
-	for _, entry := range entries {
-		name := entry.Name()
-		isHidden := strings.HasPrefix(name, ".")
-		if !includeHidden && isHidden {
-			continue
+	for {
+		entries, err := f.ReadDir(enumerateDirectoryBatch)
+		for _, entry := range entries {
+			enhanced, ok := enhancedEntry(dir, entry, includeHidden)
+			if ok && !fn(enhanced) {
+				return nil
+			}
+		}
+		if err == io.EOF {
+			return nil
 		}
-
-		fullPath := filepath.Join(dir, name)
-		info, err := entry.Info()
 		if err != nil {
-			continue
+			return err
 		}
-		isDir := entry.IsDir()
+	}
+}
 
-		var ext string
-		if !isDir {
-			if idx := strings.LastIndexByte(name, '.'); idx >= 0 && idx+1 < len(name) {
-				ext = strings.ToLower(name[idx+1:])
-			}
-		}
+// enhancedEntry describes one directory entry, or reports false when it is
+// hidden and hidden files are not wanted, or when it vanished meanwhile
+func enhancedEntry(dir string, entry os.DirEntry, includeHidden bool) (EnhancedBasicEntry, bool) {
+	name := entry.Name()
+	isHidden := strings.HasPrefix(name, ".")
+	if !includeHidden && isHidden {
+		return EnhancedBasicEntry{}, false
+	}
 
-		enhanced := EnhancedBasicEntry{
-			BasicEntry: BasicEntry{
-				Name:      name,
-				Path:      fullPath,
-				IsDir:     isDir,
-				Extension: ext,
-				IsHidden:  isHidden,
-			},
-			Size:        info.Size(),
-			ModTime:     info.ModTime().Unix(),
-			Permissions: info.Mode().String(),
-		}
+	info, err := entry.Info()
+	if err != nil {
+		return EnhancedBasicEntry{}, false
+	}
+	isDir := entry.IsDir()
 
-		if !fn(enhanced) {
-			break
+	var ext string
+	if !isDir {
+		if idx := strings.LastIndexByte(name, '.'); idx >= 0 && idx+1 < len(name) {
+			ext = strings.ToLower(name[idx+1:])
 		}
 	}
 
-	return nil
+	return EnhancedBasicEntry{
+		BasicEntry: BasicEntry{
+			Name:      name,
+			Path:      filepath.Join(dir, name),
+			IsDir:     isDir,
+			Extension: ext,
+			IsHidden:  isHidden,
+		},
+		Size:        info.Size(),
+		ModTime:     info.ModTime().Unix(),
+		Permissions: info.Mode().String(),
+	}, true
 }
 
 func listDirectoryBasicEnhanced(dir string, includeHidden bool) ([]EnhancedBasicEntry, error) {
 	result := make([]EnhancedBasicEntry, 0, 256)
-	err := enumerateDirectoryBasicEnhanced(dir, includeHidden, func(entry EnhancedBasicEntry) bool {
+	err := enumerateDirectory(dir, includeHidden, func(entry EnhancedBasicEntry) bool {
 		result = append(result, entry)
 		return true
 	})
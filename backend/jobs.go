@@ -0,0 +1,308 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Job status values reported to the frontend
+const (
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+)
+
+const (
+	jobProgressInterval = 200 * time.Millisecond
+	jobRetention        = 10 * time.Minute
+)
+
+// JobInfo is a snapshot of a background job's state
+type JobInfo struct {
+	ID         string                 `json:"id" msgpack:"id"`
+	Kind       string                 `json:"kind" msgpack:"kind"`
+	Status     string                 `json:"status" msgpack:"status"`
+	Message    string                 `json:"message" msgpack:"message"`
+	Error      string                 `json:"error,omitempty" msgpack:"error,omitempty"`
+	Done       int64                  `json:"done" msgpack:"done"`
+	Total      int64                  `json:"total" msgpack:"total"`
+	StartedAt  int64                  `json:"startedAt" msgpack:"startedAt"`
+	FinishedAt int64                  `json:"finishedAt,omitempty" msgpack:"finishedAt,omitempty"`
+	Result     map[string]interface{} `json:"result,omitempty" msgpack:"result,omitempty"`
+}
+
+// Job is the handle a running job uses to report progress
+type Job struct {
+	id      string
+	kind    string
+	started time.Time
+	cancel  context.CancelFunc
+	manager *JobManager
+	doneCh  chan struct{}
+
+	done  atomic.Int64
+	total atomic.Int64
+
+	mu       sync.Mutex
+	status   string
+	message  string
+	err      string
+	finished time.Time
+	result   map[string]interface{}
+	lastEmit time.Time
+}
+
+// JobManager runs cancellable background jobs and reports their progress
+type JobManager struct {
+	mu       sync.RWMutex
+	jobs     map[string]*Job
+	seq      atomic.Uint64
+	emitter  *EventEmitter
+	listener func(JobInfo)
+}
+
+// NewJobManager creates a new job manager instance
+func NewJobManager() *JobManager {
+	return &JobManager{jobs: make(map[string]*Job)}
+}
+
+// SetContext enables progress events on the Wails event bus
+func (m *JobManager) SetContext(ctx context.Context) {
+	m.mu.Lock()
+	m.emitter = NewEventEmitter(ctx)
+	m.mu.Unlock()
+}
+
+// SetListener registers a callback receiving every progress update (used by headless callers)
+func (m *JobManager) SetListener(fn func(JobInfo)) {
+	m.mu.Lock()
+	m.listener = fn
+	m.mu.Unlock()
+}
+
+// Start launches run in its own goroutine and returns the new job ID
+func (m *JobManager) Start(kind string, run func(ctx context.Context, job *Job) error) string {
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &Job{
+		id:      fmt.Sprintf("%s-%d", kind, m.seq.Add(1)),
+		kind:    kind,
+		started: time.Now(),
+		cancel:  cancel,
+		manager: m,
+		doneCh:  make(chan struct{}),
+		status:  JobStatusRunning,
+	}
+
+	m.mu.Lock()
+	m.pruneLocked()
+	m.jobs[job.id] = job
+	m.mu.Unlock()
+
+	m.publish(job, true)
+
+	go func() {
+		defer cancel()
+		err := run(ctx, job)
+		job.finish(ctx, err)
+		m.publish(job, true)
+		close(job.doneCh)
+	}()
+
+	return job.id
+}
+
+// Wait blocks until the job finishes and returns its final state
+func (m *JobManager) Wait(id string) (JobInfo, bool) {
+	m.mu.RLock()
+	job, ok := m.jobs[id]
+	m.mu.RUnlock()
+	if !ok {
+		return JobInfo{}, false
+	}
+	<-job.doneCh
+	return job.Info(), true
+}
+
+// Get returns the current state of a job
+func (m *JobManager) Get(id string) (JobInfo, bool) {
+	m.mu.RLock()
+	job, ok := m.jobs[id]
+	m.mu.RUnlock()
+	if !ok {
+		return JobInfo{}, false
+	}
+	return job.Info(), true
+}
+
+// List returns all known jobs, newest first
+func (m *JobManager) List() []JobInfo {
+	m.mu.RLock()
+	infos := make([]JobInfo, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		infos = append(infos, job.Info())
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].StartedAt > infos[j].StartedAt })
+	return infos
+}
+
+// Cancel requests cancellation of a running job
+func (m *JobManager) Cancel(id string) bool {
+	m.mu.RLock()
+	job, ok := m.jobs[id]
+	m.mu.RUnlock()
+	if !ok {
+		return false
+	}
+	job.cancel()
+	return true
+}
+
+func (m *JobManager) pruneLocked() {
+	cutoff := time.Now().Add(-jobRetention)
+	for id, job := range m.jobs {
+		job.mu.Lock()
+		expired := !job.finished.IsZero() && job.finished.Before(cutoff)
+		job.mu.Unlock()
+		if expired {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+func (m *JobManager) publish(job *Job, force bool) {
+	job.mu.Lock()
+	if !force && time.Since(job.lastEmit) < jobProgressInterval {
+		job.mu.Unlock()
+		return
+	}
+	job.lastEmit = time.Now()
+	job.mu.Unlock()
+
+	info := job.Info()
+
+	m.mu.RLock()
+	emitter, listener := m.emitter, m.listener
+	m.mu.RUnlock()
+
+	if emitter != nil {
+		if info.Status == JobStatusRunning {
+			emitter.EmitJobProgress(info)
+		} else {
+			emitter.EmitJobComplete(info)
+		}
+	}
+	if listener != nil {
+		listener(info)
+	}
+}
+
+// ID returns the job identifier
+func (j *Job) ID() string {
+	return j.id
+}
+
+// SetTotal sets the amount of work (bytes or items) the job expects to do
+func (j *Job) SetTotal(total int64) {
+	j.total.Store(total)
+	j.manager.publish(j, false)
+}
+
+// AddTotal grows the expected amount of work
+func (j *Job) AddTotal(delta int64) {
+	j.total.Add(delta)
+}
+
+// Add records completed work
+func (j *Job) Add(delta int64) {
+	j.done.Add(delta)
+	j.manager.publish(j, false)
+}
+
+// SetMessage updates the human-readable status line
+func (j *Job) SetMessage(format string, args ...interface{}) {
+	j.mu.Lock()
+	j.message = fmt.Sprintf(format, args...)
+	j.mu.Unlock()
+	j.manager.publish(j, false)
+}
+
+// SetResult attaches a value to the job result
+func (j *Job) SetResult(key string, value interface{}) {
+	j.mu.Lock()
+	if j.result == nil {
+		j.result = make(map[string]interface{})
+	}
+	j.result[key] = value
+	j.mu.Unlock()
+}
+
+// Info returns a snapshot of the job state
+func (j *Job) Info() JobInfo {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	info := JobInfo{
+		ID:        j.id,
+		Kind:      j.kind,
+		Status:    j.status,
+		Message:   j.message,
+		Error:     j.err,
+		Done:      j.done.Load(),
+		Total:     j.total.Load(),
+		StartedAt: j.started.Unix(),
+	}
+	if !j.finished.IsZero() {
+		info.FinishedAt = j.finished.Unix()
+	}
+	if len(j.result) > 0 {
+		info.Result = make(map[string]interface{}, len(j.result))
+		for k, v := range j.result {
+			info.Result[k] = v
+		}
+	}
+	return info
+}
+
+func (j *Job) finish(ctx context.Context, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.finished = time.Now()
+	switch {
+	case err == nil:
+		j.status = JobStatusCompleted
+	case errors.Is(err, context.Canceled) || ctx.Err() != nil:
+		j.status = JobStatusCancelled
+		j.err = "cancelled"
+	default:
+		j.status = JobStatusFailed
+		j.err = err.Error()
+	}
+}
+
+// progressReader reports bytes read to a job
+type progressReader struct {
+	ctx context.Context
+	r   io.Reader
+	job *Job
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	if err := p.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := p.r.Read(b)
+	if n > 0 && p.job != nil {
+		p.job.Add(int64(n))
+	}
+	return n, err
+}
@@ -0,0 +1,116 @@
+package backend
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// standaloneEnv marks a process spawned for --new-window so it skips the
+// single-instance lock instead of forwarding its arguments back to us.
+const standaloneEnv = "LIGHTNING_EXPLORER_STANDALONE"
+
+// IsStandaloneLaunch reports whether this process was spawned as an extra window
+func IsStandaloneLaunch() bool {
+	return os.Getenv(standaloneEnv) == "1"
+}
+
+// ClearStandaloneLaunch removes the marker from the environment so that
+// programs started from this window, and launches made from them, are not
+// mistaken for extra windows
+func ClearStandaloneLaunch() {
+	os.Unsetenv(standaloneEnv)
+}
+
+// ParseLaunchArgs turns command line arguments into a navigation request.
+// Supported forms:
+//
+//	lightning-explorer <path>
+//	lightning-explorer --select <file>
+//	lightning-explorer --new-window [<path>]
+//
+// Relative paths resolve against workingDir, and arguments after "--" are always
+// paths. "~" and "~name" expand to the matching user's home; an unknown user is
+// left for the shell's meaning, a relative name. A file given as the path opens
+// its folder with the file selected. ok is false when there is nothing to
+// navigate to.
+func ParseLaunchArgs(args []string, workingDir string) (req NavigateRequest, ok bool) {
+	req.Source = "command-line"
+
+	resolve := func(p string) string {
+		if p == "" {
+			return ""
+		}
+		p = expandHome(p)
+		if !filepath.IsAbs(p) && workingDir != "" {
+			p = filepath.Join(workingDir, p)
+		}
+		return filepath.Clean(p)
+	}
+
+	pathsOnly := false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		switch {
+		case pathsOnly:
+			if req.Path == "" {
+				req.Path = resolve(arg)
+			}
+		case arg == "--":
+			pathsOnly = true
+		case arg == "--new-window" || arg == "-n":
+			req.NewWindow = true
+		case strings.HasPrefix(arg, "--") && name == "select":
+			if !hasValue && i+1 < len(args) {
+				i++
+				value = args[i]
+			}
+			req.Select = resolve(value)
+		case strings.HasPrefix(arg, "-"):
+			// Unknown flags (e.g. macOS -psn_*) are ignored
+		case req.Path == "":
+			req.Path = resolve(arg)
+		}
+	}
+
+	if req.Path != "" {
+		if info, err := os.Stat(req.Path); err == nil && !info.IsDir() {
+			if req.Select == "" {
+				req.Select = req.Path
+			}
+			req.Path = filepath.Dir(req.Path)
+		}
+	}
+	if req.Path == "" && req.Select != "" {
+		req.Path = filepath.Dir(req.Select)
+	}
+
+	return req, req.Path != "" || req.NewWindow
+}
+
+// expandHome expands a leading "~" or "~name" the way a shell would. Paths that
+// name an unknown user are returned unchanged.
+func expandHome(p string) string {
+	if !strings.HasPrefix(p, "~") {
+		return p
+	}
+	name, rest, _ := strings.Cut(filepath.FromSlash(p[1:]), string(filepath.Separator))
+
+	var home string
+	if name == "" {
+		h, err := os.UserHomeDir()
+		if err != nil {
+			return p
+		}
+		home = h
+	} else {
+		u, err := user.Lookup(name)
+		if err != nil || u.HomeDir == "" {
+			return p
+		}
+		home = u.HomeDir
+	}
+	return filepath.Join(home, rest)
+}
@@ -0,0 +1,59 @@
+package backend
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseLaunchArgs(t *testing.T) {
+	wd := t.TempDir()
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"relative", []string{"docs"}, filepath.Join(wd, "docs")},
+		{"home", []string{"~/docs"}, filepath.Join(home, "docs")},
+		{"bare home", []string{"~"}, home},
+		{"unknown user", []string{"~no-such-user-here/docs"}, filepath.Join(wd, "~no-such-user-here", "docs")},
+		{"flag after separator", []string{"--", "--select"}, filepath.Join(wd, "--select")},
+		{"unknown flag", []string{"-psn_0_1234", "docs"}, filepath.Join(wd, "docs")},
+	}
+	if u, err := user.Current(); err == nil && u.HomeDir != "" {
+		tests = append(tests, struct {
+			name string
+			args []string
+			want string
+		}{"named user", []string{"~" + u.Username + "/docs"}, filepath.Join(u.HomeDir, "docs")})
+	}
+
+	for _, tt := range tests {
+		req, ok := ParseLaunchArgs(tt.args, wd)
+		if !ok || req.Path != tt.want {
+			t.Errorf("%s: ParseLaunchArgs(%q) = %q, %v; want %q", tt.name, tt.args, req.Path, ok, tt.want)
+		}
+	}
+}
+
+func TestParseLaunchArgsSelectsFiles(t *testing.T) {
+	wd := t.TempDir()
+	file := filepath.Join(wd, "notes.txt")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	req, ok := ParseLaunchArgs([]string{"notes.txt"}, wd)
+	if !ok || req.Path != wd || req.Select != file {
+		t.Errorf("file argument = %+v, want its folder with the file selected", req)
+	}
+	if _, ok := ParseLaunchArgs(nil, wd); ok {
+		t.Error("empty arguments produced a navigation request")
+	}
+}
@@ -0,0 +1,219 @@
+//go:build linux
+
+package backend
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	// UDisks probes a new loop device asynchronously; wait this long for its filesystems
+	loopProbeTimeout  = 5 * time.Second
+	loopProbeInterval = 100 * time.Millisecond
+)
+
+// diskImageExtensions are the files MountVolume attaches as loop devices
+var diskImageExtensions = map[string]bool{".iso": true, ".img": true}
+
+// mountVolume mounts a block device, or attaches a disk image as a loop device
+// and mounts the filesystems on it, through UDisks2. Mount points are chosen
+// by UDisks, normally under /run/media/<user>.
+func mountVolume(source string) MountResult {
+	result := MountResult{Source: source, MountPoints: []string{}}
+
+	info, err := os.Stat(source)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	isImage := info.Mode().IsRegular()
+	if isImage && !diskImageExtensions[strings.ToLower(filepath.Ext(source))] {
+		result.Message = "only .iso and .img disk images can be mounted"
+		return result
+	}
+	if !isImage && info.Mode()&os.ModeDevice == 0 {
+		result.Message = source + " is neither a block device nor a disk image"
+		return result
+	}
+
+	udisks, err := connectUDisks()
+	if err != nil {
+		result.Message = "UDisks2 is unavailable: " + err.Error()
+		return result
+	}
+	defer udisks.Close()
+
+	var targets []dbus.ObjectPath
+	if isImage {
+		loop, err := udisks.setupLoop(source)
+		if err != nil {
+			result.Message = "attaching disk image failed: " + err.Error()
+			return result
+		}
+		result.LoopDevice = udisks.bytesProp(loop, udisksBlock, "Device")
+		// Autoclear only detaches once a filesystem was mounted and unmounted
+		defer func() {
+			if !result.Success {
+				udisks.deleteLoop(loop)
+			}
+		}()
+		targets = udisks.waitForFilesystems(loop)
+		if len(targets) == 0 {
+			result.Message = "no mountable filesystem found in " + filepath.Base(source)
+			return result
+		}
+	} else {
+		block, ok := udisks.blockForDevice(source)
+		if !ok || !udisks.has(block, udisksFilesystem) {
+			result.Message = source + " does not contain a mountable filesystem"
+			return result
+		}
+		targets = []dbus.ObjectPath{block}
+	}
+
+	var failures []string
+	for _, fs := range targets {
+		if points := udisks.mountPoints(fs); len(points) > 0 {
+			result.MountPoints = append(result.MountPoints, points[0])
+			continue
+		}
+		var mountPoint string
+		if err := udisks.call(fs, udisksFilesystem+".Mount").Store(&mountPoint); err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+		result.MountPoints = append(result.MountPoints, mountPoint)
+	}
+
+	result.Success = len(result.MountPoints) > 0
+	if len(failures) > 0 {
+		result.Message = strings.Join(failures, "; ")
+	}
+	if result.Success {
+		logPrintf("Mounted %s at %s", source, strings.Join(result.MountPoints, ", "))
+	}
+	return result
+}
+
+// setupLoop attaches image as a loop device that detaches itself once its
+// filesystems are unmounted. ISO images are attached read-only.
+func (c *udisksClient) setupLoop(image string) (dbus.ObjectPath, error) {
+	readOnly := strings.EqualFold(filepath.Ext(image), ".iso")
+	flag := os.O_RDWR
+	if readOnly {
+		flag = os.O_RDONLY
+	}
+	f, err := os.OpenFile(image, flag, 0)
+	if err != nil && !readOnly {
+		readOnly = true
+		f, err = os.Open(image)
+	}
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var loop dbus.ObjectPath
+	options := map[string]dbus.Variant{"read-only": dbus.MakeVariant(readOnly)}
+	err = c.callWithOptions(udisksManagerPath, udisksManager+".LoopSetup", options, dbus.UnixFD(f.Fd())).Store(&loop)
+	if err != nil {
+		return "", err
+	}
+	if err := c.call(loop, udisksLoop+".SetAutoclear", true).Err; err != nil {
+		logPrintf("Failed to set autoclear on %s: %v", loop, err)
+	}
+	if err := c.refresh(); err != nil {
+		c.deleteLoop(loop)
+		return "", err
+	}
+	return loop, nil
+}
+
+// deleteLoop detaches a loop device that nothing was mounted from
+func (c *udisksClient) deleteLoop(loop dbus.ObjectPath) {
+	if err := c.call(loop, udisksLoop+".Delete").Err; err != nil {
+		logPrintf("Failed to detach loop device %s: %v", loop, err)
+	}
+}
+
+// waitForFilesystems returns the filesystems on a loop device and its
+// partitions once UDisks has probed them, or nil after loopProbeTimeout
+func (c *udisksClient) waitForFilesystems(loop dbus.ObjectPath) []dbus.ObjectPath {
+	deadline := time.Now().Add(loopProbeTimeout)
+	for {
+		var found []dbus.ObjectPath
+		for path := range c.objects {
+			if !c.has(path, udisksFilesystem) {
+				continue
+			}
+			table, _ := c.prop(path, udisksPartition, "Table")
+			if path == loop || table == loop {
+				found = append(found, path)
+			}
+		}
+		if len(found) > 0 || time.Now().After(deadline) {
+			sort.Slice(found, func(i, j int) bool { return found[i] < found[j] })
+			return found
+		}
+		time.Sleep(loopProbeInterval)
+		if err := c.refresh(); err != nil {
+			return nil
+		}
+	}
+}
+
+// listUnmountedVolumes returns the filesystems UDisks knows about that are not
+// mounted, skipping those it hints should be hidden (swap, recovery partitions, ...)
+func listUnmountedVolumes() ([]BlockVolume, error) {
+	udisks, err := connectUDisks()
+	if err != nil {
+		return nil, fmt.Errorf("UDisks2 is unavailable: %w", err)
+	}
+	defer udisks.Close()
+
+	volumes := []BlockVolume{}
+	for path := range udisks.objects {
+		if !udisks.has(path, udisksFilesystem) || len(udisks.mountPoints(path)) > 0 {
+			continue
+		}
+		if udisks.boolProp(path, udisksBlock, "HintIgnore") {
+			continue
+		}
+
+		device := udisks.bytesProp(path, udisksBlock, "PreferredDevice")
+		if device == "" {
+			device = udisks.bytesProp(path, udisksBlock, "Device")
+		}
+		volume := BlockVolume{
+			Device: device,
+			Label:  udisks.stringProp(path, udisksBlock, "IdLabel"),
+			FSType: udisks.stringProp(path, udisksBlock, "IdType"),
+			UUID:   udisks.stringProp(path, udisksBlock, "IdUUID"),
+			Size:   int64(udisks.uint64Prop(path, udisksBlock, "Size")),
+			System: udisks.boolProp(path, udisksBlock, "HintSystem"),
+		}
+		if drive, ok := udisks.driveOf(path); ok {
+			volume.Removable = udisks.boolProp(drive, udisksDrive, "Removable") ||
+				udisks.boolProp(drive, udisksDrive, "MediaRemovable")
+		}
+		switch {
+		case udisks.stringProp(path, udisksBlock, "HintName") != "":
+			volume.Name = udisks.stringProp(path, udisksBlock, "HintName")
+		case volume.Label != "":
+			volume.Name = volume.Label
+		default:
+			volume.Name = filepath.Base(device)
+		}
+		volumes = append(volumes, volume)
+	}
+
+	sort.Slice(volumes, func(i, j int) bool { return volumes[i].Device < volumes[j].Device })
+	return volumes, nil
+}
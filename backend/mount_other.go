@@ -0,0 +1,18 @@
+//go:build !linux
+
+package backend
+
+import (
+	"fmt"
+	"runtime"
+)
+
+var errMountUnsupported = fmt.Errorf("mounting volumes is not supported on %s", runtime.GOOS)
+
+func mountVolume(source string) MountResult {
+	return MountResult{Source: source, MountPoints: []string{}, Message: errMountUnsupported.Error()}
+}
+
+func listUnmountedVolumes() ([]BlockVolume, error) {
+	return nil, errMountUnsupported
+}
@@ -0,0 +1,370 @@
+//go:build linux
+
+package backend
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	mountInfoPath = "/proc/self/mountinfo"
+	diskLabelDir  = "/dev/disk/by-label"
+
+	// mountPollTimeoutMs bounds each poll so the watcher notices cancellation
+	mountPollTimeoutMs = 1000
+)
+
+// mountEntry is one line of /proc/self/mountinfo
+type mountEntry struct {
+	ID           int
+	ParentID     int
+	DeviceNumber string // "major:minor"
+	Root         string // subtree of the filesystem mounted here; not "/" for bind mounts and btrfs subvolumes
+	MountPoint   string
+	Options      []string
+	FSType       string
+	Source       string
+	SuperOptions []string
+}
+
+// pseudoFilesystems never hold user files and are hidden from the sidebar
+var pseudoFilesystems = map[string]bool{
+	"autofs": true, "binfmt_misc": true, "bpf": true, "cgroup": true, "cgroup2": true,
+	"configfs": true, "debugfs": true, "devpts": true, "devtmpfs": true, "efivarfs": true,
+	"fusectl": true, "hugetlbfs": true, "mqueue": true, "nsfs": true, "overlay": true,
+	"proc": true, "pstore": true, "ramfs": true, "rpc_pipefs": true, "securityfs": true,
+	"selinuxfs": true, "squashfs": true, "sysfs": true, "tmpfs": true, "tracefs": true,
+	"fuse.gvfsd-fuse": true, "fuse.portal": true, "fuse.lxcfs": true, "fuse.snapfuse": true,
+}
+
+var networkFilesystems = map[string]bool{
+	"nfs": true, "nfs4": true, "cifs": true, "smb3": true, "smbfs": true, "9p": true,
+	"afs": true, "ceph": true, "glusterfs": true, "fuse.sshfs": true, "fuse.rclone": true,
+	"davfs": true, "fuse.davfs2": true,
+}
+
+// systemMountPrefixes hold mounts managed by the system rather than the user
+var systemMountPrefixes = []string{"/proc", "/sys", "/dev", "/run", "/snap", "/var/lib/docker", "/var/lib/snapd"}
+
+// readMountInfo parses the mount table of the current mount namespace
+func readMountInfo() ([]mountEntry, error) {
+	f, err := os.Open(mountInfoPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseMountInfo(f)
+}
+
+// parseMountInfo parses the mountinfo format described in proc(5). Malformed
+// lines are skipped.
+func parseMountInfo(r io.Reader) ([]mountEntry, error) {
+	var entries []mountEntry
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// Optional fields end at a lone "-", followed by type, source and super options
+		sep := -1
+		for i := 6; i < len(fields); i++ {
+			if fields[i] == "-" {
+				sep = i
+				break
+			}
+		}
+		if sep < 0 || len(fields) < sep+3 {
+			continue
+		}
+		id, err1 := strconv.Atoi(fields[0])
+		parent, err2 := strconv.Atoi(fields[1])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		entry := mountEntry{
+			ID:           id,
+			ParentID:     parent,
+			DeviceNumber: fields[2],
+			Root:         unescapeMountField(fields[3]),
+			MountPoint:   unescapeMountField(fields[4]),
+			Options:      strings.Split(fields[5], ","),
+			FSType:       fields[sep+1],
+			Source:       unescapeMountField(fields[sep+2]),
+		}
+		if len(fields) > sep+3 {
+			entry.SuperOptions = strings.Split(fields[sep+3], ",")
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+func (m mountEntry) readOnly() bool {
+	for _, opt := range m.Options {
+		if opt == "ro" {
+			return true
+		}
+	}
+	for _, opt := range m.SuperOptions {
+		if opt == "ro" {
+			return true
+		}
+	}
+	return false
+}
+
+func (m mountEntry) network() bool {
+	return networkFilesystems[m.FSType]
+}
+
+// watchMountChanges signals on the returned channel after every mount or
+// unmount in this namespace. The kernel raises POLLPRI on an open mountinfo
+// file whenever the mount table changes, so no polling interval is involved.
+func watchMountChanges(ctx context.Context) (<-chan struct{}, error) {
+	// A raw descriptor keeps the file out of the runtime's epoll set, whose
+	// readiness checks would otherwise consume the change event
+	fd, err := unix.Open(mountInfoPath, unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: mountInfoPath, Err: err}
+	}
+
+	updates := make(chan struct{}, 1)
+	go func() {
+		defer close(updates)
+		defer unix.Close(fd)
+
+		fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLPRI}}
+		for ctx.Err() == nil {
+			n, err := unix.Poll(fds, mountPollTimeoutMs)
+			if errors.Is(err, unix.EINTR) || n == 0 {
+				continue
+			}
+			if err != nil {
+				logPrintf("Mount watcher stopped: %v", err)
+				return
+			}
+			if fds[0].Revents&(unix.POLLPRI|unix.POLLERR) == 0 {
+				continue
+			}
+			select {
+			case updates <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return updates, nil
+}
+
+// realMounts drops pseudo filesystems, system mounts and bind mounts. A later
+// mount on the same point hides the earlier one, and a mount whose subtree is
+// already visible through an earlier mount of the same device is a bind mount.
+// Btrfs subvolumes have disjoint subtrees and are all kept.
+func realMounts(entries []mountEntry) []mountEntry {
+	var kept []mountEntry
+	byPoint := make(map[string]int)
+
+	for _, m := range entries {
+		if pseudoFilesystems[m.FSType] || isSystemMountPoint(m.MountPoint) {
+			continue
+		}
+		if !m.network() && !strings.HasPrefix(m.Source, "/dev/") {
+			continue
+		}
+		if i, ok := byPoint[m.MountPoint]; ok {
+			kept[i] = m
+			continue
+		}
+		if isBindMount(m, kept) {
+			continue
+		}
+		byPoint[m.MountPoint] = len(kept)
+		kept = append(kept, m)
+	}
+	return kept
+}
+
+func isSystemMountPoint(path string) bool {
+	// Desktop automounters put removable media under /run/media/<user>
+	if strings.HasPrefix(path, "/run/media/") {
+		return false
+	}
+	for _, prefix := range systemMountPrefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func isBindMount(m mountEntry, kept []mountEntry) bool {
+	for _, k := range kept {
+		if k.DeviceNumber != m.DeviceNumber || k.network() {
+			continue
+		}
+		if k.Root == "/" || m.Root == k.Root || strings.HasPrefix(m.Root, k.Root+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// listRealMounts returns the user-visible mounts with label, device and capacity
+func listRealMounts(ctx context.Context) []DriveInfo {
+	entries, err := readMountInfo()
+	if err != nil {
+		logPrintf("Failed to read %s: %v", mountInfoPath, err)
+		return nil
+	}
+
+	labels := diskLabels()
+	var drives []DriveInfo
+	for _, m := range realMounts(entries) {
+		drives = append(drives, driveInfoForMount(ctx, m, labels))
+	}
+
+	// The root filesystem leads; the rest keep mount order
+	sort.SliceStable(drives, func(i, j int) bool {
+		return drives[i].Path == "/" && drives[j].Path != "/"
+	})
+	return drives
+}
+
+func driveInfoForMount(ctx context.Context, m mountEntry, labels map[string]string) DriveInfo {
+	drive := DriveInfo{
+		Path:     m.MountPoint,
+		FSType:   m.FSType,
+		Device:   m.Source,
+		ReadOnly: m.readOnly(),
+	}
+
+	if !m.network() {
+		device := m.Source
+		if resolved, err := filepath.EvalSymlinks(device); err == nil {
+			device = resolved
+		}
+		drive.Label = labels[device]
+		drive.Removable = blockDeviceRemovable(blockDeviceNumber(m))
+	}
+
+	switch {
+	case drive.Label != "":
+		drive.Name = drive.Label
+	case m.MountPoint == "/":
+		drive.Name = "Root Filesystem"
+	default:
+		drive.Name = filepath.Base(m.MountPoint)
+	}
+
+	// A dead network mount stays listed, just without its capacity
+	stats, err := withPathDeadline(ctx, m.MountPoint, func() (VolumeStats, error) {
+		var stats VolumeStats
+		err := statVolume(m.MountPoint, &stats)
+		return stats, err
+	})
+	if err == nil {
+		drive.TotalBytes = stats.TotalBytes
+		drive.FreeBytes = stats.FreeBytes
+		drive.AvailableBytes = stats.AvailableBytes
+	}
+	return drive
+}
+
+// diskLabels maps resolved device paths to the filesystem labels udev publishes
+func diskLabels() map[string]string {
+	return diskLinks(diskLabelDir)
+}
+
+// diskLinks maps the targets of the udev symlinks in dir to their decoded names
+func diskLinks(dir string) map[string]string {
+	links := make(map[string]string)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return links
+	}
+	for _, entry := range entries {
+		device, err := filepath.EvalSymlinks(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		links[device] = unescapeUdevLabel(entry.Name())
+	}
+	return links
+}
+
+// unescapeUdevLabel decodes the \xNN escapes udev uses in by-label link names
+func unescapeUdevLabel(s string) string {
+	if !strings.Contains(s, `\x`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) && s[i+1] == 'x' {
+			if v, err := strconv.ParseUint(s[i+2:i+4], 16, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// blockDeviceNumber returns the "major:minor" of the block device behind a
+// mount. The number in mountinfo is the superblock's, which for btrfs and
+// other multi-device filesystems does not name a block device.
+func blockDeviceNumber(m mountEntry) string {
+	var st unix.Stat_t
+	if err := unix.Stat(m.Source, &st); err == nil && st.Mode&unix.S_IFMT == unix.S_IFBLK {
+		return fmt.Sprintf("%d:%d", unix.Major(uint64(st.Rdev)), unix.Minor(uint64(st.Rdev)))
+	}
+	return m.DeviceNumber
+}
+
+// blockDeviceRemovable reports whether the block device with the given
+// "major:minor" number is removable media or sits on a USB bus. Partitions
+// inherit the flag from their parent disk.
+func blockDeviceRemovable(deviceNumber string) bool {
+	sysPath, err := filepath.EvalSymlinks(filepath.Join("/sys/dev/block", deviceNumber))
+	if err != nil {
+		return false
+	}
+	if strings.Contains(sysPath, "/usb") {
+		return true
+	}
+	for _, dir := range []string{sysPath, filepath.Dir(sysPath)} {
+		if data, err := os.ReadFile(filepath.Join(dir, "removable")); err == nil {
+			return strings.TrimSpace(string(data)) == "1"
+		}
+	}
+	return false
+}
+
+// mountForPath returns the mount containing path, which should be absolute
+// and free of symlinks. Later entries hide earlier ones on the same point.
+func mountForPath(entries []mountEntry, path string) (mountEntry, bool) {
+	var best mountEntry
+	found := false
+	for _, m := range entries {
+		if !pathWithinMount(path, m.MountPoint) {
+			continue
+		}
+		if !found || len(m.MountPoint) >= len(best.MountPoint) {
+			best, found = m, true
+		}
+	}
+	return best, found
+}
+
+func pathWithinMount(path, mountPoint string) bool {
+	return mountPoint == "/" || path == mountPoint || strings.HasPrefix(path, mountPoint+"/")
+}
@@ -0,0 +1,19 @@
+//go:build !linux
+
+package backend
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+)
+
+// listRealMounts is only implemented on Linux; other platforms enumerate drives their own way
+func listRealMounts(_ context.Context) []DriveInfo {
+	return nil
+}
+
+// watchMountChanges is only implemented on Linux; callers fall back to polling
+func watchMountChanges(ctx context.Context) (<-chan struct{}, error) {
+	return nil, fmt.Errorf("drive change monitoring not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,301 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Protocols of saved network locations
+const (
+	NetworkProtocolSMB  = "smb"
+	NetworkProtocolNFS  = "nfs"
+	NetworkProtocolSFTP = "sftp"
+)
+
+// Connection states of a network location. Offline means the host did not
+// answer; its mount, if any, is treated as dead and never listed.
+const (
+	NetworkStatusConnected    = "connected"
+	NetworkStatusDisconnected = "disconnected"
+	NetworkStatusOffline      = "offline"
+)
+
+const (
+	networkProbeInterval = 15 * time.Second
+	networkProbeTimeout  = 3 * time.Second
+	networkMountTimeout  = 60 * time.Second
+)
+
+var defaultNetworkPorts = map[string]int{
+	NetworkProtocolSMB:  445,
+	NetworkProtocolNFS:  2049,
+	NetworkProtocolSFTP: 22,
+}
+
+// NetworkManager tracks the connection state of saved network locations. A
+// background loop probes each host so that views never touch a dead mount.
+type NetworkManager struct {
+	mu      sync.RWMutex
+	states  map[string]networkState
+	changes chan struct{}
+}
+
+type networkState struct {
+	status     string
+	mountPoint string
+	err        string
+}
+
+// NewNetworkManager creates a network manager with every location unknown
+func NewNetworkManager() *NetworkManager {
+	return &NetworkManager{
+		states:  make(map[string]networkState),
+		changes: make(chan struct{}, 1),
+	}
+}
+
+// Changes signals whenever the state of any location changes
+func (n *NetworkManager) Changes() <-chan struct{} {
+	return n.changes
+}
+
+func (l NetworkLocation) validate() error {
+	if l.ID == "" || strings.Contains(l.ID, "/") {
+		return fmt.Errorf("invalid network location id: %q", l.ID)
+	}
+	if _, ok := defaultNetworkPorts[l.Protocol]; !ok {
+		return fmt.Errorf("unsupported network protocol: %q", l.Protocol)
+	}
+	if l.Host == "" || strings.ContainsAny(l.Host, "/\\@ ") {
+		return fmt.Errorf("invalid host: %q", l.Host)
+	}
+	if l.Port < 0 || l.Port > 65535 {
+		return fmt.Errorf("invalid port: %d", l.Port)
+	}
+	if l.Protocol != NetworkProtocolSFTP && strings.Trim(l.Share, "/\\") == "" {
+		return fmt.Errorf("a share or export is required for %s", l.Protocol)
+	}
+	return nil
+}
+
+func (l NetworkLocation) port() int {
+	if l.Port != 0 {
+		return l.Port
+	}
+	return defaultNetworkPorts[l.Protocol]
+}
+
+// URI returns the location as a gio-style URI such as smb://user@host/share
+func (l NetworkLocation) URI() string {
+	u := url.URL{Scheme: l.Protocol, Host: l.Host, Path: "/" + strings.Trim(filepath.ToSlash(l.Share), "/")}
+	if l.Port != 0 && l.Port != defaultNetworkPorts[l.Protocol] {
+		u.Host = net.JoinHostPort(l.Host, strconv.Itoa(l.Port))
+	}
+	if l.Username != "" {
+		u.User = url.User(l.Username)
+	}
+	return u.String()
+}
+
+// Status returns the last known state of loc
+func (n *NetworkManager) Status(loc NetworkLocation) NetworkLocationStatus {
+	n.mu.RLock()
+	state, ok := n.states[loc.ID]
+	n.mu.RUnlock()
+	if !ok {
+		state.status = NetworkStatusDisconnected
+	}
+	return NetworkLocationStatus{
+		NetworkLocation: loc,
+		URI:             loc.URI(),
+		Status:          state.status,
+		MountPoint:      state.mountPoint,
+		Error:           state.err,
+	}
+}
+
+func (n *NetworkManager) setState(id string, state networkState) {
+	n.mu.Lock()
+	changed := n.states[id] != state
+	n.states[id] = state
+	n.mu.Unlock()
+	if changed {
+		select {
+		case n.changes <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// setOffline records loc as unreachable. The mount point is kept from the last
+// state, or looked up locally when loc was already down at startup, so paths
+// on the stale mount are still recognised as offline.
+func (n *NetworkManager) setOffline(loc NetworkLocation) {
+	mountPoint := n.Status(loc).MountPoint
+	if mountPoint == "" {
+		mountPoint, _ = localNetworkMount(loc)
+	}
+	n.setState(loc.ID, networkState{status: NetworkStatusOffline, mountPoint: mountPoint, err: "host is not reachable"})
+}
+
+// Connect mounts loc, answering the mount helper's prompts with password when given
+func (n *NetworkManager) Connect(loc NetworkLocation, password string) (NetworkLocationStatus, error) {
+	if err := loc.validate(); err != nil {
+		return n.Status(loc), err
+	}
+	if !hostReachable(loc) {
+		n.setOffline(loc)
+		return n.Status(loc), fmt.Errorf("%s is not reachable", loc.Host)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), networkMountTimeout)
+	defer cancel()
+	mountPoint, err := connectNetworkLocation(ctx, loc, password)
+	if err != nil {
+		n.setState(loc.ID, networkState{status: NetworkStatusDisconnected, err: err.Error()})
+		return n.Status(loc), err
+	}
+	n.setState(loc.ID, networkState{status: NetworkStatusConnected, mountPoint: mountPoint})
+	logPrintf("Connected %s at %s", loc.URI(), mountPoint)
+	return n.Status(loc), nil
+}
+
+// Disconnect unmounts loc
+func (n *NetworkManager) Disconnect(loc NetworkLocation) error {
+	ctx, cancel := context.WithTimeout(context.Background(), networkMountTimeout)
+	defer cancel()
+	if err := disconnectNetworkLocation(ctx, loc); err != nil {
+		return err
+	}
+	n.setState(loc.ID, networkState{status: NetworkStatusDisconnected})
+	return nil
+}
+
+// Run probes the locations returned by locations until ctx is cancelled
+func (n *NetworkManager) Run(ctx context.Context, locations func() []NetworkLocation) {
+	ticker := time.NewTicker(networkProbeInterval)
+	defer ticker.Stop()
+	for {
+		n.probe(ctx, locations())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probe refreshes the state of each location concurrently. Mount lookups only
+// run against reachable hosts so a dead server cannot block the probe.
+func (n *NetworkManager) probe(ctx context.Context, locations []NetworkLocation) {
+	var wg sync.WaitGroup
+	for _, loc := range locations {
+		wg.Add(1)
+		go func(loc NetworkLocation) {
+			defer wg.Done()
+			if !hostReachable(loc) {
+				n.setOffline(loc)
+				return
+			}
+			lookupCtx, cancel := context.WithTimeout(ctx, networkProbeTimeout)
+			defer cancel()
+			if mountPoint, ok := findNetworkMount(lookupCtx, loc); ok {
+				n.setState(loc.ID, networkState{status: NetworkStatusConnected, mountPoint: mountPoint})
+				return
+			}
+			n.setState(loc.ID, networkState{status: NetworkStatusDisconnected})
+		}(loc)
+	}
+	wg.Wait()
+
+	// Forget locations that were removed from the settings
+	keep := make(map[string]bool, len(locations))
+	for _, loc := range locations {
+		keep[loc.ID] = true
+	}
+	n.mu.Lock()
+	for id := range n.states {
+		if !keep[id] {
+			delete(n.states, id)
+		}
+	}
+	n.mu.Unlock()
+}
+
+func hostReachable(loc NetworkLocation) bool {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(loc.Host, strconv.Itoa(loc.port())), networkProbeTimeout)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// offlineLocation returns the offline location whose mount contains path
+func (n *NetworkManager) offlineLocation(locations []NetworkLocation, path string) (NetworkLocationStatus, bool) {
+	clean := filepath.Clean(path)
+	for _, loc := range locations {
+		status := n.Status(loc)
+		if status.Status != NetworkStatusOffline || status.MountPoint == "" {
+			continue
+		}
+		mount := filepath.Clean(status.MountPoint)
+		if clean == mount || strings.HasPrefix(clean, mount+string(filepath.Separator)) {
+			return status, true
+		}
+	}
+	return NetworkLocationStatus{}, false
+}
+
+// driveEntries describes the locations as sidebar entries. Connected
+// locations point at their mount; the others at their URI.
+func (n *NetworkManager) driveEntries(locations []NetworkLocation) []DriveInfo {
+	entries := make([]DriveInfo, 0, len(locations))
+	for _, loc := range locations {
+		status := n.Status(loc)
+		entry := DriveInfo{
+			Path:       status.URI,
+			Name:       loc.Name,
+			FSType:     loc.Protocol,
+			Device:     status.URI,
+			LocationID: loc.ID,
+			Status:     status.Status,
+			Source:     QuickAccessSourceNetwork,
+		}
+		if status.Status == NetworkStatusConnected {
+			entry.Path = status.MountPoint
+		}
+		if entry.Name == "" {
+			entry.Name = loc.Host + "/" + strings.Trim(loc.Share, "/\\")
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// mergeDrives adds the locations to a drive list. A connected location that is
+// already listed as a mount takes over that entry instead of repeating it.
+func (n *NetworkManager) mergeDrives(drives []DriveInfo, locations []NetworkLocation) []DriveInfo {
+	merged := append([]DriveInfo{}, drives...)
+	for _, entry := range n.driveEntries(locations) {
+		replaced := false
+		for i := range merged {
+			if entry.Status == NetworkStatusConnected && merged[i].Path == entry.Path {
+				merged[i].Name, merged[i].LocationID, merged[i].Status = entry.Name, entry.LocationID, entry.Status
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, entry)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,115 @@
+//go:build linux
+
+package backend
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// connectNetworkLocation mounts loc through GVfs, which exposes it as a FUSE
+// path under $XDG_RUNTIME_DIR/gvfs. Credentials stored in the desktop keyring
+// are used automatically; otherwise password answers the gio prompts.
+func connectNetworkLocation(ctx context.Context, loc NetworkLocation, password string) (string, error) {
+	if _, err := exec.LookPath("gio"); err != nil {
+		return "", fmt.Errorf("connecting network locations requires gio from GVfs")
+	}
+	cmd := exec.CommandContext(ctx, "gio", "mount", loc.URI())
+	cmd.Stdin = strings.NewReader(mountPromptAnswers(loc, password))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		msg := strings.TrimSpace(string(out))
+		if !strings.Contains(msg, "already mounted") {
+			if msg == "" {
+				msg = err.Error()
+			}
+			return "", fmt.Errorf("gio mount: %s", msg)
+		}
+	}
+	if mountPoint, ok := findNetworkMount(ctx, loc); ok {
+		return mountPoint, nil
+	}
+	return "", fmt.Errorf("%s is mounted but has no local path; is gvfsd-fuse running?", loc.URI())
+}
+
+// mountPromptAnswers answers the prompts gio mount prints for each protocol.
+// Empty lines accept gio's defaults.
+func mountPromptAnswers(loc NetworkLocation, password string) string {
+	switch loc.Protocol {
+	case NetworkProtocolSMB:
+		return loc.Username + "\n" + loc.Domain + "\n" + password + "\n"
+	case NetworkProtocolSFTP:
+		return password + "\n"
+	}
+	return ""
+}
+
+func disconnectNetworkLocation(ctx context.Context, loc NetworkLocation) error {
+	if mountPoint, ok := kernelNetworkMount(loc); ok {
+		return fmt.Errorf("%s is mounted by the system at %s; unmount it there", loc.URI(), mountPoint)
+	}
+	out, err := exec.CommandContext(ctx, "gio", "mount", "-u", loc.URI()).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("gio mount -u: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// findNetworkMount returns where loc is mounted, either by the kernel (fstab
+// NFS and CIFS mounts) or by GVfs
+func findNetworkMount(ctx context.Context, loc NetworkLocation) (string, bool) {
+	if mountPoint, ok := kernelNetworkMount(loc); ok {
+		return mountPoint, true
+	}
+	if _, err := exec.LookPath("gio"); err != nil {
+		return "", false
+	}
+	out, err := exec.CommandContext(ctx, "gio", "info", loc.URI()).Output()
+	if err != nil {
+		return "", false
+	}
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		if path, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "local path: "); ok {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// localNetworkMount looks loc up in the kernel mount table only, which never
+// contacts the host. GVfs mounts can only be found by asking gio.
+func localNetworkMount(loc NetworkLocation) (string, bool) {
+	return kernelNetworkMount(loc)
+}
+
+// kernelNetworkMount finds an NFS or CIFS mount of loc in the mount table
+func kernelNetworkMount(loc NetworkLocation) (string, bool) {
+	entries, err := readMountInfo()
+	if err != nil {
+		return "", false
+	}
+	return networkMountIn(entries, loc)
+}
+
+func networkMountIn(entries []mountEntry, loc NetworkLocation) (string, bool) {
+	share := strings.Trim(loc.Share, "/")
+	for _, m := range entries {
+		if !m.network() {
+			continue
+		}
+		switch loc.Protocol {
+		case NetworkProtocolNFS:
+			if m.Source == loc.Host+":/"+share {
+				return m.MountPoint, true
+			}
+		case NetworkProtocolSMB:
+			if strings.EqualFold(m.Source, "//"+loc.Host+"/"+share) {
+				return m.MountPoint, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,64 @@
+//go:build linux
+
+package backend
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+)
+
+const networkMountInfo = `22 1 0:21 / / rw,relatime - ext4 /dev/sda1 rw
+40 22 0:40 / /mnt/nfs rw,relatime - nfs4 files.lan:/export/media rw,vers=4.2
+41 22 0:41 / /mnt/My\040Share rw,relatime - cifs //FILES.lan/My\040Share rw,vers=3.1.1
+`
+
+func TestNetworkMountInMatchesKernelMounts(t *testing.T) {
+	entries, err := parseMountInfo(strings.NewReader(networkMountInfo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		loc  NetworkLocation
+		want string
+	}{
+		{NetworkLocation{Protocol: NetworkProtocolNFS, Host: "files.lan", Share: "/export/media/"}, "/mnt/nfs"},
+		{NetworkLocation{Protocol: NetworkProtocolSMB, Host: "files.lan", Share: "My Share"}, "/mnt/My Share"},
+		{NetworkLocation{Protocol: NetworkProtocolNFS, Host: "other.lan", Share: "export/media"}, ""},
+		{NetworkLocation{Protocol: NetworkProtocolSFTP, Host: "files.lan", Share: "export/media"}, ""},
+	}
+	for _, tt := range tests {
+		got, ok := networkMountIn(entries, tt.loc)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("networkMountIn(%s) = %q, %v; want %q", tt.loc.URI(), got, ok, tt.want)
+		}
+	}
+}
+
+// closedPort returns a local port nothing listens on
+func closedPort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+	return port
+}
+
+func TestProbeKeepsMountPointOfUnreachableLocation(t *testing.T) {
+	loc := NetworkLocation{ID: "nas", Protocol: NetworkProtocolSMB, Host: "127.0.0.1", Port: closedPort(t), Share: "media"}
+	n := NewNetworkManager()
+	n.setState(loc.ID, networkState{status: NetworkStatusConnected, mountPoint: "/mnt/media"})
+
+	n.probe(context.Background(), []NetworkLocation{loc})
+
+	if status := n.Status(loc); status.Status != NetworkStatusOffline || status.MountPoint != "/mnt/media" {
+		t.Fatalf("status after failed probe = %+v, want offline at /mnt/media", status)
+	}
+	if _, ok := n.offlineLocation([]NetworkLocation{loc}, "/mnt/media/films"); !ok {
+		t.Error("a path on the unreachable mount is not reported offline")
+	}
+}
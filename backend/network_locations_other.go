@@ -0,0 +1,27 @@
+//go:build !linux && !windows
+
+package backend
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+)
+
+var errNetworkUnsupported = fmt.Errorf("connecting network locations is not supported on %s", runtime.GOOS)
+
+func connectNetworkLocation(_ context.Context, _ NetworkLocation, _ string) (string, error) {
+	return "", errNetworkUnsupported
+}
+
+func disconnectNetworkLocation(_ context.Context, _ NetworkLocation) error {
+	return errNetworkUnsupported
+}
+
+func findNetworkMount(_ context.Context, _ NetworkLocation) (string, bool) {
+	return "", false
+}
+
+func localNetworkMount(_ NetworkLocation) (string, bool) {
+	return "", false
+}
@@ -0,0 +1,123 @@
+//go:build windows
+
+package backend
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	mpr                    = syscall.NewLazyDLL("mpr.dll")
+	wNetAddConnection2W    = mpr.NewProc("WNetAddConnection2W")
+	wNetCancelConnection2W = mpr.NewProc("WNetCancelConnection2W")
+)
+
+const (
+	resourceTypeDisk = 0x1
+	connectTemporary = 0x4
+)
+
+// netResource mirrors NETRESOURCEW
+type netResource struct {
+	Scope       uint32
+	Type        uint32
+	DisplayType uint32
+	Usage       uint32
+	LocalName   *uint16
+	RemoteName  *uint16
+	Comment     *uint16
+	Provider    *uint16
+}
+
+// uncPath returns \\host\share, the form both SMB and the Windows NFS client accept
+func uncPath(loc NetworkLocation) string {
+	share := strings.ReplaceAll(strings.Trim(loc.Share, "/\\"), "/", `\`)
+	return `\\` + loc.Host + `\` + share
+}
+
+// connectNetworkLocation connects the share without a drive letter; it is then
+// reachable by its UNC path. Without a password Windows uses stored credentials.
+func connectNetworkLocation(_ context.Context, loc NetworkLocation, password string) (string, error) {
+	if loc.Protocol == NetworkProtocolSFTP {
+		return "", fmt.Errorf("SFTP locations are not supported on Windows")
+	}
+	remote := uncPath(loc)
+	remotePtr, err := syscall.UTF16PtrFromString(remote)
+	if err != nil {
+		return "", err
+	}
+	resource := netResource{Type: resourceTypeDisk, RemoteName: remotePtr}
+
+	var passwordPtr, userPtr *uint16
+	if password != "" {
+		if passwordPtr, err = syscall.UTF16PtrFromString(password); err != nil {
+			return "", err
+		}
+	}
+	if loc.Username != "" {
+		user := loc.Username
+		if loc.Domain != "" {
+			user = loc.Domain + `\` + user
+		}
+		if userPtr, err = syscall.UTF16PtrFromString(user); err != nil {
+			return "", err
+		}
+	}
+
+	ret, _, _ := wNetAddConnection2W.Call(
+		uintptr(unsafe.Pointer(&resource)),
+		uintptr(unsafe.Pointer(passwordPtr)),
+		uintptr(unsafe.Pointer(userPtr)),
+		connectTemporary,
+	)
+	if ret != 0 {
+		return "", fmt.Errorf("connecting %s failed: %w", remote, syscall.Errno(ret))
+	}
+	return remote, nil
+}
+
+func disconnectNetworkLocation(_ context.Context, loc NetworkLocation) error {
+	remotePtr, err := syscall.UTF16PtrFromString(uncPath(loc))
+	if err != nil {
+		return err
+	}
+	ret, _, _ := wNetCancelConnection2W.Call(uintptr(unsafe.Pointer(remotePtr)), 0, 1)
+	if ret != 0 {
+		return fmt.Errorf("disconnecting %s failed: %w", uncPath(loc), syscall.Errno(ret))
+	}
+	return nil
+}
+
+// localNetworkMount returns the UNC path, which is where a connected share
+// always lives; working it out needs no contact with the host
+func localNetworkMount(loc NetworkLocation) (string, bool) {
+	if loc.Protocol == NetworkProtocolSFTP {
+		return "", false
+	}
+	return uncPath(loc), true
+}
+
+// findNetworkMount reports the UNC path as connected when it can be opened
+// within the probe deadline
+func findNetworkMount(ctx context.Context, loc NetworkLocation) (string, bool) {
+	if loc.Protocol == NetworkProtocolSFTP {
+		return "", false
+	}
+	remote := uncPath(loc)
+	done := make(chan error, 1)
+	go func() {
+		_, err := os.Stat(remote)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		return remote, err == nil
+	case <-ctx.Done():
+		return "", false
+	}
+}
@@ -0,0 +1,177 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// pathCallTimeout bounds each blocking filesystem call (stat, statfs, or the
+	// wait for the next directory entry) made while listing
+	pathCallTimeout = 5 * time.Second
+	// pathBackoffPeriod is how long a path that timed out fails fast before it is tried again
+	pathBackoffPeriod = 30 * time.Second
+)
+
+// DirectoryErrorTimeout is the code sent with DirectoryError and set on
+// NavigationResponse.ErrorCode when a path did not respond in time
+const DirectoryErrorTimeout = "timeout"
+
+// PathTimeoutError reports a path whose filesystem did not answer within
+// pathCallTimeout, typically a stale network mount
+type PathTimeoutError struct {
+	Path  string
+	Until time.Time // the path fails fast until then
+	fresh bool
+}
+
+func (e *PathTimeoutError) Error() string {
+	if e.fresh {
+		return fmt.Sprintf("%s did not respond within %s", e.Path, pathCallTimeout)
+	}
+	retry := time.Until(e.Until).Round(time.Second)
+	return fmt.Sprintf("%s is not responding; retrying in %s", e.Path, retry)
+}
+
+// errorCode returns the DirectoryError code for err, or "" for ordinary errors
+func errorCode(err error) string {
+	var timeout *PathTimeoutError
+	if errors.As(err, &timeout) {
+		return DirectoryErrorTimeout
+	}
+	return ""
+}
+
+// pathBackoff remembers paths that recently timed out so that views do not
+// pile up more goroutines blocked on the same dead mount
+type pathBackoff struct {
+	mu    sync.Mutex
+	until map[string]time.Time
+}
+
+var unavailablePaths = &pathBackoff{until: make(map[string]time.Time)}
+
+// mark records that path timed out and returns the error to report
+func (b *pathBackoff) mark(path string) error {
+	path = filepath.Clean(path)
+	until := time.Now().Add(pathBackoffPeriod)
+	b.mu.Lock()
+	b.until[path] = until
+	b.mu.Unlock()
+	logPrintf("⏱️ %s did not respond; skipping it for %s", path, pathBackoffPeriod)
+	return &PathTimeoutError{Path: path, Until: until, fresh: true}
+}
+
+// check fails fast when path or one of its parents is backing off
+func (b *pathBackoff) check(path string) error {
+	path = filepath.Clean(path)
+	now := time.Now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for marked, until := range b.until {
+		if now.After(until) {
+			delete(b.until, marked)
+			continue
+		}
+		if path == marked || strings.HasPrefix(path, strings.TrimSuffix(marked, string(filepath.Separator))+string(filepath.Separator)) {
+			return &PathTimeoutError{Path: marked, Until: until}
+		}
+	}
+	return nil
+}
+
+// reset forgets every marked path, e.g. after the mount table changed
+func (b *pathBackoff) reset() {
+	b.mu.Lock()
+	b.until = make(map[string]time.Time)
+	b.mu.Unlock()
+}
+
+// withPathDeadline runs op against path, giving up after pathCallTimeout or
+// when ctx is done. A syscall stuck on a dead mount cannot be interrupted, so
+// op keeps running in the background and its late result is dropped; the
+// backoff stops further calls from joining it.
+func withPathDeadline[T any](ctx context.Context, path string, op func() (T, error)) (T, error) {
+	var zero T
+	if err := unavailablePaths.check(path); err != nil {
+		return zero, err
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, pathCallTimeout)
+	defer cancel()
+
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := op()
+		done <- result{value, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return zero, unavailablePaths.mark(path)
+		}
+		return zero, ctx.Err()
+	}
+}
+
+// enumerateDirectoryBasicEnhanced enumerates dir like enumerateDirectory, but
+// stops when ctx is done and fails with a PathTimeoutError when the next entry
+// takes longer than pathCallTimeout to arrive. fn runs on the caller's goroutine.
+func enumerateDirectoryBasicEnhanced(ctx context.Context, dir string, includeHidden bool, fn func(EnhancedBasicEntry) bool) error {
+	if err := unavailablePaths.check(dir); err != nil {
+		return err
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	entries := make(chan EnhancedBasicEntry, streamBatchSize)
+	result := make(chan error, 1)
+	stop := make(chan struct{})
+	defer close(stop)
+
+	go func() {
+		result <- enumerateDirectory(dir, includeHidden, func(entry EnhancedBasicEntry) bool {
+			select {
+			case entries <- entry:
+				return true
+			case <-stop:
+				return false
+			}
+		})
+		close(entries)
+	}()
+
+	timer := time.NewTimer(pathCallTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case entry, ok := <-entries:
+			if !ok {
+				return <-result
+			}
+			if !fn(entry) {
+				return nil
+			}
+			timer.Reset(pathCallTimeout)
+		case <-timer.C:
+			return unavailablePaths.mark(dir)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -132,6 +132,11 @@ func (p *PlatformManager) HideFile(filePath string) bool {
 	return p.HideFileWindows(filePath)
 }
 
+// UnhideFile clears the hidden attribute set by HideFile
+func (p *PlatformManager) UnhideFile(filePath string) bool {
+	return p.UnhideFileWindowsNative(filePath)
+}
+
 // GetExtension returns the file extension in lowercase
 func (p *PlatformManager) GetExtension(name string) string {
 	ext := filepath.Ext(name)
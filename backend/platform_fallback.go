@@ -17,6 +17,11 @@ func (p *PlatformManager) HideFileWindowsNative(filePath string) bool {
 	return false
 }
 
+func (p *PlatformManager) UnhideFileWindowsNative(filePath string) bool {
+	// This should not be called on non-Windows platforms
+	return false
+}
+
 func (p *PlatformManager) GetCurrentUserSIDNative() (string, error) {
 	return "", fmt.Errorf("native SID retrieval only available on Windows")
 }
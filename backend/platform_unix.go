@@ -25,8 +25,9 @@ func (p *PlatformManager) GetWindowsDrivesOptimized() []DriveInfo {
 
 func (p *PlatformManager) invalidateDriveCaches() {}
 
+// WatchDriveChanges signals whenever the set of mounted volumes may have changed
 func (p *PlatformManager) WatchDriveChanges(ctx context.Context) (<-chan struct{}, error) {
-	return nil, fmt.Errorf("drive change monitoring not supported on %s", runtime.GOOS)
+	return watchMountChanges(ctx)
 }
 
 // GetHomeDirectory returns the user's home directory
@@ -108,6 +109,30 @@ func (p *PlatformManager) HideFile(filePath string) bool {
 	return true
 }
 
+// UnhideFile removes the leading dot HideFile added
+func (p *PlatformManager) UnhideFile(filePath string) bool {
+	base := filepath.Base(filePath)
+	name := strings.TrimPrefix(base, ".")
+	if name == base {
+		return true
+	}
+	if name == "" || name == "." {
+		logPrintf("Error unhiding file %s: name would be empty", filePath)
+		return false
+	}
+
+	newPath := filepath.Join(filepath.Dir(filePath), name)
+	if _, err := os.Lstat(newPath); err == nil {
+		logPrintf("Error unhiding file %s: %s already exists", filePath, newPath)
+		return false
+	}
+	if err := os.Rename(filePath, newPath); err != nil {
+		logPrintf("Error unhiding file %s: %v", filePath, err)
+		return false
+	}
+	return true
+}
+
 // FormatFileSize formats file size in human readable format
 func (p *PlatformManager) FormatFileSize(size int64) string {
 	const unit = 1024
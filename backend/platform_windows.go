@@ -75,9 +75,11 @@ var (
 
 // Windows constants
 const (
+	FILE_ATTRIBUTE_READONLY  = 0x1
 	FILE_ATTRIBUTE_HIDDEN    = 0x2
 	FILE_ATTRIBUTE_DIRECTORY = 0x10
 	FILE_ATTRIBUTE_SYSTEM    = 0x4
+	FILE_ATTRIBUTE_ARCHIVE   = 0x20
 
 	TOKEN_QUERY = 0x0008
 	TokenUser   = 1
@@ -544,6 +546,7 @@ func (p *PlatformManager) GetWindowsDrivesOptimized() []DriveInfo {
 		} else {
 			driveInfo.Name = "Drive " + driveString[:2]
 		}
+		fillDriveStats(context.Background(), &driveInfo)
 
 		drives = append(drives, driveInfo)
 	}
@@ -672,36 +675,45 @@ func (p *PlatformManager) IsHiddenWindowsNative(filePath string) bool {
 // HideFileWindowsNative sets the hidden attribute on Windows using native API
 func (p *PlatformManager) HideFileWindowsNative(filePath string) bool {
 	logPrintf("Setting hidden attribute on Windows using native API: %s", filePath)
-
-	filePathPtr, err := syscall.UTF16PtrFromString(filePath)
-	if err != nil {
-		logPrintf("Failed to convert file path to UTF16: %v", err)
+	if err := updateFileAttributesNative(filePath, FILE_ATTRIBUTE_HIDDEN, 0); err != nil {
+		logPrintf("Failed to set hidden attribute: %v", err)
 		return false
 	}
+	logPrintf("Successfully set hidden attribute using native API: %s", filePath)
+	return true
+}
 
-	// Get current attributes first
-	ret, _, _ := getFileAttributesW.Call(uintptr(unsafe.Pointer(filePathPtr)))
-	if ret == INVALID_FILE_ATTRIBUTES {
-		logPrintf("Failed to get current file attributes")
+// UnhideFileWindowsNative clears the hidden attribute on Windows using native API
+func (p *PlatformManager) UnhideFileWindowsNative(filePath string) bool {
+	if err := updateFileAttributesNative(filePath, 0, FILE_ATTRIBUTE_HIDDEN); err != nil {
+		logPrintf("Failed to clear hidden attribute: %v", err)
 		return false
 	}
+	return true
+}
 
-	currentAttributes := uint32(ret)
-	newAttributes := currentAttributes | FILE_ATTRIBUTE_HIDDEN
+// updateFileAttributesNative sets the attribute bits in set and clears those in clear
+func updateFileAttributesNative(filePath string, set, clear uint32) error {
+	filePathPtr, err := syscall.UTF16PtrFromString(filePath)
+	if err != nil {
+		return err
+	}
 
-	// Set the new attributes with hidden flag
-	ret, _, err = setFileAttributesW.Call(
-		uintptr(unsafe.Pointer(filePathPtr)),
-		uintptr(newAttributes),
-	)
+	ret, _, err := getFileAttributesW.Call(uintptr(unsafe.Pointer(filePathPtr)))
+	if ret == INVALID_FILE_ATTRIBUTES {
+		return fmt.Errorf("get attributes of %s: %v", filePath, err)
+	}
 
+	current := uint32(ret)
+	updated := (current | set) &^ clear
+	if updated == current {
+		return nil
+	}
+	ret, _, err = setFileAttributesW.Call(uintptr(unsafe.Pointer(filePathPtr)), uintptr(updated))
 	if ret == 0 {
-		logPrintf("Failed to set hidden attribute: %v", err)
-		return false
+		return fmt.Errorf("set attributes of %s: %v", filePath, err)
 	}
-
-	logPrintf("Successfully set hidden attribute using native API: %s", filePath)
-	return true
+	return nil
 }
 
 // GetCurrentUserSIDNative gets the current user's SID using native Windows APIs with proper memory safety
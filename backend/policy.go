@@ -0,0 +1,370 @@
+package backend
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Policy actions, ordered by severity
+const (
+	PolicyAllow   = "allow"
+	PolicyConfirm = "confirm"
+	PolicyDeny    = "deny"
+)
+
+// Mutating operations the policy is consulted for
+const (
+	OpCopy    = "copy"
+	OpMove    = "move"
+	OpDelete  = "delete"
+	OpRecycle = "recycle"
+	OpRename  = "rename"
+	OpHide    = "hide"
+	OpCreate  = "create"
+	OpShred   = "shred"
+	OpAttrs   = "attributes"
+)
+
+// policyApprovalTTL is how long an approved confirmation stays valid, so the
+// frontend can retry the operation after the user agreed
+const policyApprovalTTL = 2 * time.Minute
+
+// policyRequestTTL is how long a confirmation request can still be approved
+const policyRequestTTL = 5 * time.Minute
+
+// destructiveOps remove or relocate everything under their paths, so a rule
+// on a descendant applies to them as well
+var destructiveOps = map[string]bool{OpDelete: true, OpMove: true, OpRecycle: true, OpShred: true, OpRename: true}
+
+// protectedSystemDirs are the top-level directories under a system root that
+// hold the operating system and installed software
+var protectedSystemDirs = map[string][]string{
+	"windows": {"Windows", "Program Files", "Program Files (x86)", "ProgramData", "$Recycle.Bin",
+		"System Volume Information", "Recovery", "Boot", "PerfLogs"},
+	"darwin": {"System", "Library", "Applications", "bin", "sbin", "usr", "etc", "var", "private",
+		"cores", "dev", "opt"},
+	"default": {"bin", "boot", "dev", "etc", "lib", "lib32", "lib64", "libx32", "proc", "run",
+		"sbin", "snap", "srv", "sys", "usr", "var", "opt"},
+}
+
+// homeStandardDirs are user folders that are rarely meant to be removed wholesale
+var homeStandardDirs = []string{"Desktop", "Documents", "Downloads", "Pictures", "Music", "Videos", "Movies"}
+
+// PolicyDecision is the result of checking an operation against the policy
+type PolicyDecision struct {
+	Action string            `json:"action" msgpack:"action"`
+	Op     string            `json:"op" msgpack:"op"`
+	Paths  []string          `json:"paths" msgpack:"paths"`
+	Rule   ProtectedPathRule `json:"rule" msgpack:"rule"`
+	Reason string            `json:"reason,omitempty" msgpack:"reason,omitempty"`
+}
+
+// PolicyConfirmRequest asks the user to approve an operation on protected paths
+type PolicyConfirmRequest struct {
+	RequestID string         `json:"requestId" msgpack:"requestId"`
+	Decision  PolicyDecision `json:"decision" msgpack:"decision"`
+}
+
+// PolicyError reports an operation blocked by the protected-path policy
+type PolicyError struct {
+	Decision PolicyDecision
+}
+
+func (e *PolicyError) Error() string {
+	return e.Decision.Reason
+}
+
+// PathPolicy decides whether mutating operations may touch a path. Built-in
+// rules protect system roots, OS directories and the home folder; users add
+// their own rules in Settings.ProtectedPaths.
+type PathPolicy struct {
+	mu        sync.Mutex
+	builtin   []ProtectedPathRule
+	user      []ProtectedPathRule
+	approved  map[string]time.Time // op and path -> expiry
+	requested map[string]pendingApproval
+}
+
+type pendingApproval struct {
+	decision PolicyDecision
+	expires  time.Time
+}
+
+// NewPathPolicy creates a policy with the built-in rules for this OS
+func NewPathPolicy(platform PlatformManagerInterface) *PathPolicy {
+	return &PathPolicy{
+		builtin:   builtinProtectedRules(platform),
+		approved:  make(map[string]time.Time),
+		requested: make(map[string]pendingApproval),
+	}
+}
+
+func builtinProtectedRules(platform PlatformManagerInterface) []ProtectedPathRule {
+	var rules []ProtectedPathRule
+	add := func(path, action string, children bool) {
+		if path != "" {
+			rules = append(rules, ProtectedPathRule{Path: filepath.Clean(path), Action: action, IncludeChildren: children, Builtin: true})
+		}
+	}
+
+	dirs, ok := protectedSystemDirs[runtime.GOOS]
+	if !ok {
+		dirs = protectedSystemDirs["default"]
+	}
+	for _, root := range platform.GetSystemRoots() {
+		add(root, PolicyDeny, false)
+		for _, dir := range dirs {
+			add(filepath.Join(root, dir), PolicyDeny, false)
+			add(filepath.Join(root, dir), PolicyConfirm, true)
+		}
+		// The folder holding every user's home is protected itself, not its contents
+		if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+			add(filepath.Join(root, "Users"), PolicyDeny, false)
+		} else {
+			add(filepath.Join(root, "home"), PolicyDeny, false)
+		}
+	}
+
+	if home := platform.GetHomeDirectory(); home != "" {
+		add(home, PolicyDeny, false)
+		add(filepath.Dir(home), PolicyDeny, false)
+		for _, dir := range homeStandardDirs {
+			add(filepath.Join(home, dir), PolicyConfirm, false)
+		}
+	}
+	return rules
+}
+
+// SetUserRules replaces the user-configured rules
+func (p *PathPolicy) SetUserRules(rules []ProtectedPathRule) {
+	cleaned := make([]ProtectedPathRule, 0, len(rules))
+	for _, r := range rules {
+		if r.Path == "" {
+			continue
+		}
+		r.Path = filepath.Clean(r.Path)
+		r.Builtin = false
+		if r.Action != PolicyDeny {
+			r.Action = PolicyConfirm
+		}
+		cleaned = append(cleaned, r)
+	}
+
+	p.mu.Lock()
+	p.user = cleaned
+	p.mu.Unlock()
+}
+
+// Rules returns the built-in rules followed by the user rules
+func (p *PathPolicy) Rules() []ProtectedPathRule {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append(append([]ProtectedPathRule{}, p.builtin...), p.user...)
+}
+
+// Evaluate returns the most severe action any rule assigns to op on paths.
+// Destructive ops are also judged by the rules on anything beneath a path.
+// Paths approved through Approve for op are allowed until the approval expires.
+func (p *PathPolicy) Evaluate(op string, paths ...string) PolicyDecision {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	decision := PolicyDecision{Action: PolicyAllow, Op: op, Paths: []string{}}
+	now := time.Now()
+
+	for _, raw := range paths {
+		if raw == "" {
+			continue
+		}
+		path := normalizePolicyPath(raw)
+		action, rule := PolicyAllow, ProtectedPathRule{}
+		for _, rules := range [][]ProtectedPathRule{p.builtin, p.user} {
+			for _, r := range rules {
+				if !r.matches(path) && !(destructiveOps[op] && r.isBeneath(path)) {
+					continue
+				}
+				if policySeverity(r.Action) > policySeverity(action) {
+					action, rule = r.Action, r
+				}
+			}
+		}
+		if action == PolicyConfirm {
+			if expires, ok := p.approved[approvalKey(op, path)]; ok && now.Before(expires) {
+				continue
+			}
+		}
+
+		switch {
+		case policySeverity(action) > policySeverity(decision.Action):
+			decision.Action, decision.Rule = action, rule
+			decision.Paths = []string{path}
+		case action == decision.Action && action != PolicyAllow:
+			decision.Paths = append(decision.Paths, path)
+		}
+	}
+
+	switch decision.Action {
+	case PolicyDeny:
+		if decision.Rule.isBeneath(decision.Paths[0]) {
+			decision.Reason = fmt.Sprintf("%s is not allowed on %s, which contains protected path %s", op, decision.Paths[0], decision.Rule.Path)
+		} else {
+			decision.Reason = fmt.Sprintf("%s is not allowed on protected path %s", op, decision.Paths[0])
+		}
+	case PolicyConfirm:
+		decision.Reason = fmt.Sprintf("%s on %d protected path(s) requires confirmation", op, len(decision.Paths))
+	}
+	return decision
+}
+
+// Check returns a *PolicyError unless op is allowed on every path
+func (p *PathPolicy) Check(op string, paths ...string) error {
+	if p == nil {
+		return nil
+	}
+	if decision := p.Evaluate(op, paths...); decision.Action != PolicyAllow {
+		return &PolicyError{Decision: decision}
+	}
+	return nil
+}
+
+// RequestApproval registers a confirm decision and returns the ID to approve it with
+func (p *PathPolicy) RequestApproval(decision PolicyDecision) (string, error) {
+	id, err := randomHex(12)
+	if err != nil {
+		return "", err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	for key, expires := range p.approved {
+		if now.After(expires) {
+			delete(p.approved, key)
+		}
+	}
+	for key, pending := range p.requested {
+		if now.After(pending.expires) {
+			delete(p.requested, key)
+		}
+	}
+	p.requested[id] = pendingApproval{decision: decision, expires: now.Add(policyRequestTTL)}
+	return id, nil
+}
+
+// Approve grants a short-lived allowance for the operation and paths of a
+// pending request; other operations on those paths still need confirming
+func (p *PathPolicy) Approve(requestID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pending, ok := p.requested[requestID]
+	if !ok {
+		return false
+	}
+	delete(p.requested, requestID)
+	now := time.Now()
+	if now.After(pending.expires) {
+		return false
+	}
+
+	expires := now.Add(policyApprovalTTL)
+	for _, path := range pending.decision.Paths {
+		p.approved[approvalKey(pending.decision.Op, path)] = expires
+	}
+	return true
+}
+
+func approvalKey(op, path string) string {
+	return op + "\x00" + policyKey(path)
+}
+
+func (r ProtectedPathRule) matches(path string) bool {
+	rulePath, target := policyKey(r.Path), policyKey(path)
+	if rulePath == target {
+		return true
+	}
+	if !r.IncludeChildren {
+		return false
+	}
+	rel, err := filepath.Rel(rulePath, target)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// isBeneath reports whether the rule protects something strictly inside path
+func (r ProtectedPathRule) isBeneath(path string) bool {
+	rel, err := filepath.Rel(policyKey(path), policyKey(r.Path))
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func policySeverity(action string) int {
+	switch action {
+	case PolicyDeny:
+		return 2
+	case PolicyConfirm:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// normalizePolicyPath cleans path and resolves symlinks in its parent so a
+// link cannot be used to reach a protected location under another name
+func normalizePolicyPath(path string) string {
+	clean := filepath.Clean(path)
+	if parent, err := filepath.EvalSymlinks(filepath.Dir(clean)); err == nil {
+		clean = filepath.Join(parent, filepath.Base(clean))
+	}
+	return clean
+}
+
+// policyKey compares paths case-insensitively on case-insensitive filesystems
+func policyKey(path string) string {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		return strings.ToLower(path)
+	}
+	return path
+}
+
+// transferTargets returns the paths a copy or move into destDir would create
+func transferTargets(sourcePaths []string, destDir string) []string {
+	targets := make([]string, 0, len(sourcePaths))
+	for _, src := range sourcePaths {
+		targets = append(targets, filepath.Join(destDir, filepath.Base(src)))
+	}
+	return targets
+}
+
+// attributeTargets returns the paths an attribute change touches that the
+// policy has rules for: paths themselves and, when recursive, the protected
+// paths beneath them, which the walk would otherwise change unchecked
+func (p *PathPolicy) attributeTargets(paths []string, recursive bool) []string {
+	targets := append([]string{}, paths...)
+	if !recursive || p == nil {
+		return targets
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, root := range paths {
+		for _, rules := range [][]ProtectedPathRule{p.builtin, p.user} {
+			for _, r := range rules {
+				if r.Action != PolicyAllow && r.isBeneath(root) {
+					targets = append(targets, r.Path)
+				}
+			}
+		}
+	}
+	return targets
+}
+
+// allowedByPolicy reports whether the policy lets op touch paths, logging refusals
+func (fo *FileOperationsManager) allowedByPolicy(op string, paths ...string) bool {
+	if err := fo.policy.Check(op, paths...); err != nil {
+		logPrintf("⛔ %v", err)
+		return false
+	}
+	return true
+}
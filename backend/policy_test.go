@@ -0,0 +1,92 @@
+package backend
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestPolicy(rules ...ProtectedPathRule) *PathPolicy {
+	p := &PathPolicy{approved: map[string]time.Time{}, requested: map[string]pendingApproval{}}
+	p.SetUserRules(rules)
+	return p
+}
+
+func TestPolicyProtectsDescendantsFromDestructiveOps(t *testing.T) {
+	root := t.TempDir()
+	important := filepath.Join(root, "data", "important")
+	p := newTestPolicy(ProtectedPathRule{Path: important, Action: PolicyDeny})
+
+	tests := []struct {
+		op   string
+		path string
+		want string
+	}{
+		{OpDelete, filepath.Join(root, "data"), PolicyDeny},
+		{OpDelete, root, PolicyDeny},
+		{OpRecycle, filepath.Join(root, "data"), PolicyDeny},
+		{OpShred, filepath.Join(root, "data"), PolicyDeny},
+		{OpMove, filepath.Join(root, "data"), PolicyDeny},
+		{OpRename, filepath.Join(root, "data"), PolicyDeny},
+		{OpDelete, important, PolicyDeny},
+		{OpDelete, filepath.Join(root, "data", "other"), PolicyAllow},
+		{OpDelete, filepath.Join(root, "data", "important-not"), PolicyAllow},
+		// Creating or copying into a parent leaves the protected folder alone
+		{OpCreate, filepath.Join(root, "data"), PolicyAllow},
+		{OpCopy, filepath.Join(root, "data"), PolicyAllow},
+	}
+	for _, tt := range tests {
+		if got := p.Evaluate(tt.op, tt.path).Action; got != tt.want {
+			t.Errorf("%s %s = %s, want %s", tt.op, tt.path, got, tt.want)
+		}
+	}
+
+	var policyErr *PolicyError
+	if err := p.Check(OpDelete, filepath.Join(root, "data")); !errors.As(err, &policyErr) {
+		t.Fatalf("Check = %v, want a PolicyError", err)
+	}
+}
+
+func TestPolicyApprovalIsPerOperation(t *testing.T) {
+	docs := filepath.Join(t.TempDir(), "Documents")
+	p := newTestPolicy(ProtectedPathRule{Path: docs, Action: PolicyConfirm})
+
+	decision := p.Evaluate(OpRename, docs)
+	if decision.Action != PolicyConfirm {
+		t.Fatalf("rename = %s, want confirm", decision.Action)
+	}
+	id, err := p.RequestApproval(decision)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Approve(id) {
+		t.Fatal("Approve failed")
+	}
+	if p.Approve(id) {
+		t.Error("a request was approved twice")
+	}
+
+	if got := p.Evaluate(OpRename, docs).Action; got != PolicyAllow {
+		t.Errorf("approved rename = %s, want allow", got)
+	}
+	if got := p.Evaluate(OpDelete, docs).Action; got != PolicyConfirm {
+		t.Errorf("delete after approving a rename = %s, want confirm", got)
+	}
+}
+
+func TestPolicyExpiresPendingRequests(t *testing.T) {
+	p := newTestPolicy()
+	stale, _ := p.RequestApproval(PolicyDecision{Op: OpDelete})
+	p.requested[stale] = pendingApproval{expires: time.Now().Add(-time.Second)}
+
+	if _, err := p.RequestApproval(PolicyDecision{Op: OpDelete}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := p.requested[stale]; ok {
+		t.Error("expired request was kept")
+	}
+	if p.Approve(stale) {
+		t.Error("expired request was approved")
+	}
+}
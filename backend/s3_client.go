@@ -0,0 +1,470 @@
+package backend
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3DefaultRegion   = "us-east-1"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3EmptyPayload    = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	s3ListPageSize    = 1000
+	s3PartSize        = 16 << 20
+	s3RequestTimeout  = 30 * time.Second
+)
+
+// s3Credentials holds an access key pair resolved from a credentials profile
+type s3Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// s3Client is a minimal SigV4 client for S3-compatible object stores
+type s3Client struct {
+	endpoint  *url.URL
+	region    string
+	pathStyle bool
+	creds     s3Credentials
+	http      *http.Client
+}
+
+type s3Object struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	Size         int64  `xml:"Size"`
+}
+
+type s3ListPage struct {
+	IsTruncated           bool       `xml:"IsTruncated"`
+	NextContinuationToken string     `xml:"NextContinuationToken"`
+	Contents              []s3Object `xml:"Contents"`
+	CommonPrefixes        []struct {
+		Prefix string `xml:"Prefix"`
+	} `xml:"CommonPrefixes"`
+}
+
+type s3Bucket struct {
+	Name         string `xml:"Name"`
+	CreationDate string `xml:"CreationDate"`
+}
+
+type s3ErrorResponse struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+type s3CompletedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// newS3Client builds a client for the given connection
+func newS3Client(conn S3Connection) (*s3Client, error) {
+	if conn.Endpoint == "" {
+		return nil, fmt.Errorf("endpoint cannot be empty")
+	}
+	endpoint, err := url.Parse(conn.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint: %s", conn.Endpoint)
+	}
+
+	creds, err := loadS3Credentials(conn.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	region := conn.Region
+	if region == "" {
+		region = s3DefaultRegion
+	}
+
+	return &s3Client{
+		endpoint:  endpoint,
+		region:    region,
+		pathStyle: conn.PathStyle,
+		creds:     creds,
+		// No overall timeout: transfers can run for hours. Dead endpoints are
+		// caught by the header timeout and cancellation via the job context.
+		http: &http.Client{Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			ResponseHeaderTimeout: s3RequestTimeout,
+		}},
+	}, nil
+}
+
+// loadS3Credentials reads a profile from the shared AWS credentials file,
+// falling back to the standard environment variables.
+func loadS3Credentials(profile string) (s3Credentials, error) {
+	if profile == "" {
+		profile = os.Getenv("AWS_PROFILE")
+	}
+	if profile == "" {
+		profile = "default"
+	}
+
+	credsPath := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if credsPath == "" {
+		homeDir, _ := os.UserHomeDir()
+		credsPath = filepath.Join(homeDir, ".aws", "credentials")
+	}
+
+	if f, err := os.Open(credsPath); err == nil {
+		defer f.Close()
+		var creds s3Credentials
+		section := ""
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+				continue
+			}
+			if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+				section = strings.TrimSpace(line[1 : len(line)-1])
+				continue
+			}
+			if section != profile {
+				continue
+			}
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				continue
+			}
+			switch strings.ToLower(strings.TrimSpace(key)) {
+			case "aws_access_key_id":
+				creds.AccessKeyID = strings.TrimSpace(value)
+			case "aws_secret_access_key":
+				creds.SecretAccessKey = strings.TrimSpace(value)
+			case "aws_session_token":
+				creds.SessionToken = strings.TrimSpace(value)
+			}
+		}
+		if creds.AccessKeyID != "" && creds.SecretAccessKey != "" {
+			return creds, nil
+		}
+	}
+
+	if id, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); id != "" && secret != "" {
+		return s3Credentials{AccessKeyID: id, SecretAccessKey: secret, SessionToken: os.Getenv("AWS_SESSION_TOKEN")}, nil
+	}
+
+	return s3Credentials{}, fmt.Errorf("no credentials found for profile %q", profile)
+}
+
+// ListBuckets returns all buckets visible to the credentials
+func (c *s3Client) ListBuckets(ctx context.Context) ([]s3Bucket, error) {
+	resp, err := c.do(ctx, http.MethodGet, "", "", nil, nil, s3EmptyPayload, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Buckets []s3Bucket `xml:"Buckets>Bucket"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode bucket list: %w", err)
+	}
+	return result.Buckets, nil
+}
+
+// ListObjectsV2 fetches one page of keys under prefix. An empty delimiter lists recursively.
+func (c *s3Client) ListObjectsV2(ctx context.Context, bucket, prefix, delimiter, token string) (*s3ListPage, error) {
+	query := url.Values{}
+	query.Set("list-type", "2")
+	query.Set("max-keys", strconv.Itoa(s3ListPageSize))
+	if prefix != "" {
+		query.Set("prefix", prefix)
+	}
+	if delimiter != "" {
+		query.Set("delimiter", delimiter)
+	}
+	if token != "" {
+		query.Set("continuation-token", token)
+	}
+
+	resp, err := c.do(ctx, http.MethodGet, bucket, "", query, nil, s3EmptyPayload, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var page s3ListPage
+	if err := xml.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("failed to decode object list: %w", err)
+	}
+	return &page, nil
+}
+
+// GetObject opens a streaming reader for an object
+func (c *s3Client) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, int64, error) {
+	resp, err := c.do(ctx, http.MethodGet, bucket, key, nil, nil, s3EmptyPayload, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	return resp.Body, resp.ContentLength, nil
+}
+
+// PutObject uploads a small object in a single request
+func (c *s3Client) PutObject(ctx context.Context, bucket, key string, body io.Reader, size int64) error {
+	resp, err := c.do(ctx, http.MethodPut, bucket, key, nil, body, s3UnsignedPayload, func(req *http.Request) {
+		req.ContentLength = size
+	})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// UploadMultipart uploads body in s3PartSize chunks, aborting the upload on failure
+func (c *s3Client) UploadMultipart(ctx context.Context, bucket, key string, body io.Reader) error {
+	query := url.Values{}
+	query.Set("uploads", "")
+	resp, err := c.do(ctx, http.MethodPost, bucket, key, query, nil, s3EmptyPayload, nil)
+	if err != nil {
+		return err
+	}
+	var initiated struct {
+		UploadID string `xml:"UploadId"`
+	}
+	err = xml.NewDecoder(resp.Body).Decode(&initiated)
+	resp.Body.Close()
+	if err != nil || initiated.UploadID == "" {
+		return fmt.Errorf("failed to initiate multipart upload for %s", key)
+	}
+
+	parts, err := c.uploadParts(ctx, bucket, key, initiated.UploadID, body)
+	if err != nil {
+		abort := url.Values{}
+		abort.Set("uploadId", initiated.UploadID)
+		if resp, abortErr := c.do(context.Background(), http.MethodDelete, bucket, key, abort, nil, s3EmptyPayload, nil); abortErr == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	payload, err := xml.Marshal(struct {
+		XMLName xml.Name          `xml:"CompleteMultipartUpload"`
+		Parts   []s3CompletedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return err
+	}
+
+	complete := url.Values{}
+	complete.Set("uploadId", initiated.UploadID)
+	resp, err = c.do(ctx, http.MethodPost, bucket, key, complete, bytes.NewReader(payload), sha256Hex(payload), func(req *http.Request) {
+		req.ContentLength = int64(len(payload))
+	})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (c *s3Client) uploadParts(ctx context.Context, bucket, key, uploadID string, body io.Reader) ([]s3CompletedPart, error) {
+	var parts []s3CompletedPart
+	buffer := make([]byte, s3PartSize)
+
+	for partNumber := 1; ; partNumber++ {
+		n, readErr := io.ReadFull(body, buffer)
+		if n == 0 && partNumber > 1 {
+			break
+		}
+
+		query := url.Values{}
+		query.Set("partNumber", strconv.Itoa(partNumber))
+		query.Set("uploadId", uploadID)
+		chunk := buffer[:n]
+		resp, err := c.do(ctx, http.MethodPut, bucket, key, query, bytes.NewReader(chunk), sha256Hex(chunk), func(req *http.Request) {
+			req.ContentLength = int64(len(chunk))
+		})
+		if err != nil {
+			return nil, err
+		}
+		resp.Body.Close()
+		parts = append(parts, s3CompletedPart{PartNumber: partNumber, ETag: resp.Header.Get("ETag")})
+
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return nil, readErr
+		}
+	}
+
+	return parts, nil
+}
+
+// do signs and sends a request, converting S3 error documents into Go errors
+func (c *s3Client) do(ctx context.Context, method, bucket, key string, query url.Values, body io.Reader, payloadHash string, prepare func(*http.Request)) (*http.Response, error) {
+	host := c.endpoint.Host
+	path := strings.TrimSuffix(c.endpoint.Path, "/")
+	if bucket != "" {
+		if c.pathStyle {
+			path += "/" + bucket
+		} else {
+			host = bucket + "." + host
+		}
+	}
+	path += "/" + key
+
+	encodedPath := s3URIEncode(path, false)
+	rawURL := c.endpoint.Scheme + "://" + host + encodedPath
+	if canonical := s3CanonicalQuery(query); canonical != "" {
+		rawURL += "?" + canonical
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return nil, err
+	}
+	if prepare != nil {
+		prepare(req)
+	}
+	c.sign(req, encodedPath, query, payloadHash, time.Now().UTC())
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		var s3Err s3ErrorResponse
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if xml.Unmarshal(data, &s3Err) == nil && s3Err.Code != "" {
+			return nil, fmt.Errorf("s3 %s: %s", s3Err.Code, s3Err.Message)
+		}
+		return nil, fmt.Errorf("s3 request failed: %s", resp.Status)
+	}
+
+	return resp, nil
+}
+
+// sign adds AWS Signature Version 4 headers to req
+func (c *s3Client) sign(req *http.Request, encodedPath string, query url.Values, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+	if c.creds.SessionToken != "" {
+		req.Header.Set("x-amz-security-token", c.creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "x-amz-date" || lower == "x-amz-content-sha256" || lower == "x-amz-security-token" || lower == "content-type" {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name)
+		canonicalHeaders.WriteByte(':')
+		canonicalHeaders.WriteString(headers[name])
+		canonicalHeaders.WriteByte('\n')
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		encodedPath,
+		s3CanonicalQuery(query),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := dateStamp + "/" + c.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	signingKey := hmacSHA256([]byte("AWS4"+c.creds.SecretAccessKey), dateStamp)
+	signingKey = hmacSHA256(signingKey, c.region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.creds.AccessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+// s3CanonicalQuery encodes query parameters sorted by key as SigV4 requires
+func s3CanonicalQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			pairs = append(pairs, s3URIEncode(k, true)+"="+s3URIEncode(v, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// s3URIEncode percent-encodes everything except RFC 3986 unreserved characters
+func s3URIEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch >= 'A' && ch <= 'Z', ch >= 'a' && ch <= 'z', ch >= '0' && ch <= '9',
+			ch == '-', ch == '_', ch == '.', ch == '~':
+			b.WriteByte(ch)
+		case ch == '/' && !encodeSlash:
+			b.WriteByte(ch)
+		default:
+			fmt.Fprintf(&b, "%%%02X", ch)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
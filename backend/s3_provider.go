@@ -0,0 +1,412 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// s3Scheme prefixes virtual paths served by the S3 provider:
+// s3://<connection-id>/<bucket>/<prefix>/
+const s3Scheme = "s3://"
+
+// S3Manager presents S3-compatible object storage as a browsable folder tree
+type S3Manager struct {
+	eventEmitter *EventEmitter
+	jobs         *JobManager
+}
+
+// NewS3Manager creates a new S3 manager instance
+func NewS3Manager(jobs *JobManager) *S3Manager {
+	return &S3Manager{jobs: jobs}
+}
+
+// SetContext enables directory streaming events
+func (s *S3Manager) SetContext(ctx context.Context) {
+	s.eventEmitter = NewEventEmitter(ctx)
+}
+
+// isS3Path reports whether p addresses the S3 provider
+func isS3Path(p string) bool {
+	return strings.HasPrefix(strings.ToLower(p), s3Scheme)
+}
+
+// parseS3Path splits s3://conn/bucket/key into its components
+func parseS3Path(p string) (connID, bucket, key string, err error) {
+	if !isS3Path(p) {
+		return "", "", "", fmt.Errorf("not an s3 path: %s", p)
+	}
+	rest := strings.TrimLeft(p[len(s3Scheme):], "/")
+	parts := strings.SplitN(rest, "/", 3)
+	connID = parts[0]
+	if connID == "" {
+		return "", "", "", fmt.Errorf("s3 path is missing a connection: %s", p)
+	}
+	if len(parts) > 1 {
+		bucket = parts[1]
+	}
+	if len(parts) > 2 {
+		key = parts[2]
+	}
+	return connID, bucket, key, nil
+}
+
+// StreamDirectory lists a bucket prefix page by page using the same
+// DirectoryStart/DirectoryBatchMP/DirectoryComplete events as local folders.
+func (s *S3Manager) StreamDirectory(conn S3Connection, dir string) {
+	if s.eventEmitter != nil {
+		s.eventEmitter.EmitDirectoryStart(dir)
+	}
+
+	if err := s.streamDirectory(context.Background(), conn, dir); err != nil {
+		if s.eventEmitter != nil {
+			s.eventEmitter.EmitDirectoryError("Cannot read bucket: " + err.Error())
+		}
+	}
+}
+
+func (s *S3Manager) streamDirectory(ctx context.Context, conn S3Connection, dir string) error {
+	_, bucket, prefix, err := parseS3Path(dir)
+	if err != nil {
+		return err
+	}
+	client, err := newS3Client(conn)
+	if err != nil {
+		return err
+	}
+
+	totalFiles, totalDirs := 0, 0
+
+	if bucket == "" {
+		buckets, err := client.ListBuckets(ctx)
+		if err != nil {
+			return err
+		}
+		batch := make([]WireEntry, 0, len(buckets))
+		for _, b := range buckets {
+			batch = append(batch, WireEntry{N: b.Name, D: true, M: parseS3Time(b.CreationDate)})
+		}
+		totalDirs = len(batch)
+		s.emitBatch(batch)
+		if s.eventEmitter != nil {
+			s.eventEmitter.EmitDirectoryComplete(dir, totalFiles, totalDirs)
+		}
+		return nil
+	}
+
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	token := ""
+	for {
+		page, err := client.ListObjectsV2(ctx, bucket, prefix, "/", token)
+		if err != nil {
+			return err
+		}
+
+		batch := make([]WireEntry, 0, len(page.CommonPrefixes)+len(page.Contents))
+		for _, cp := range page.CommonPrefixes {
+			name := strings.TrimSuffix(strings.TrimPrefix(cp.Prefix, prefix), "/")
+			if name == "" {
+				continue
+			}
+			batch = append(batch, WireEntry{N: name, D: true})
+			totalDirs++
+		}
+		for _, obj := range page.Contents {
+			name := strings.TrimPrefix(obj.Key, prefix)
+			// Zero-byte "folder marker" objects describe the prefix itself
+			if name == "" || strings.HasSuffix(name, "/") {
+				continue
+			}
+			batch = append(batch, WireEntry{N: name, S: obj.Size, M: parseS3Time(obj.LastModified), H: strings.HasPrefix(name, ".")})
+			totalFiles++
+		}
+
+		for start := 0; start < len(batch); start += streamBatchSize {
+			end := start + streamBatchSize
+			if end > len(batch) {
+				end = len(batch)
+			}
+			s.emitBatch(batch[start:end])
+		}
+
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			break
+		}
+		token = page.NextContinuationToken
+	}
+
+	if s.eventEmitter != nil {
+		s.eventEmitter.EmitDirectoryComplete(dir, totalFiles, totalDirs)
+	}
+	return nil
+}
+
+func (s *S3Manager) emitBatch(batch []WireEntry) {
+	if s.eventEmitter == nil || len(batch) == 0 {
+		return
+	}
+	if mp, err := GetSerializationUtils().encodeMsgPackBinary(batch); err == nil {
+		s.eventEmitter.EmitDirectoryBatchMP(mp, len(batch))
+	}
+}
+
+// Upload copies local files and folders under an s3:// destination as a job
+func (s *S3Manager) Upload(conn S3Connection, sourcePaths []string, destDir string) (string, error) {
+	_, bucket, prefix, err := parseS3Path(destDir)
+	if err != nil {
+		return "", err
+	}
+	if bucket == "" {
+		return "", fmt.Errorf("destination must be inside a bucket")
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	client, err := newS3Client(conn)
+	if err != nil {
+		return "", err
+	}
+
+	return s.jobs.Start("s3-upload", func(ctx context.Context, job *Job) error {
+		type upload struct {
+			local string
+			key   string
+			size  int64
+		}
+		var uploads []upload
+		var total int64
+		skipped := 0
+
+		for _, src := range sourcePaths {
+			root := filepath.Clean(src)
+			base := filepath.Base(root)
+			err := filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.IsDir() {
+					return nil
+				}
+				// Links, pipes and devices have no content of their own to upload,
+				// and opening a pipe would block the job
+				if !d.Type().IsRegular() {
+					logPrintf("⏭️ Skipping %s: not a regular file", p)
+					skipped++
+					return nil
+				}
+				info, err := d.Info()
+				if err != nil {
+					return err
+				}
+				rel, err := filepath.Rel(root, p)
+				if err != nil {
+					return err
+				}
+				key := prefix + base
+				if rel != "." {
+					key = prefix + path.Join(base, filepath.ToSlash(rel))
+				}
+				uploads = append(uploads, upload{local: p, key: key, size: info.Size()})
+				total += info.Size()
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		job.SetTotal(total)
+		for i, u := range uploads {
+			job.SetMessage("Uploading %s (%d/%d)", filepath.Base(u.local), i+1, len(uploads))
+			if err := s.uploadFile(ctx, client, job, bucket, u.key, u.local, u.size); err != nil {
+				return fmt.Errorf("upload %s: %w", u.local, err)
+			}
+		}
+		job.SetResult("files", len(uploads))
+		if skipped > 0 {
+			job.SetResult("skipped", skipped)
+		}
+		return nil
+	}), nil
+}
+
+func (s *S3Manager) uploadFile(ctx context.Context, client *s3Client, job *Job, bucket, key, localPath string, size int64) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// The file may have been replaced since the walk; upload what was opened
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("not a regular file")
+	}
+	if info.Size() != size {
+		job.AddTotal(info.Size() - size)
+		size = info.Size()
+	}
+
+	body := &progressReader{ctx: ctx, r: io.LimitReader(f, size), job: job}
+	if size < s3PartSize {
+		return client.PutObject(ctx, bucket, key, body, size)
+	}
+	return client.UploadMultipart(ctx, bucket, key, body)
+}
+
+// Download copies objects or whole prefixes from S3 into a local directory as a job
+func (s *S3Manager) Download(conn S3Connection, sourcePaths []string, destDir string) (string, error) {
+	destInfo, err := os.Stat(destDir)
+	if err != nil {
+		return "", fmt.Errorf("cannot access destination directory: %w", err)
+	}
+	if !destInfo.IsDir() {
+		return "", fmt.Errorf("destination is not a directory: %s", destDir)
+	}
+	client, err := newS3Client(conn)
+	if err != nil {
+		return "", err
+	}
+
+	return s.jobs.Start("s3-download", func(ctx context.Context, job *Job) error {
+		type download struct {
+			bucket string
+			key    string
+			local  string
+			size   int64
+		}
+		var downloads []download
+		var total int64
+
+		for _, src := range sourcePaths {
+			_, bucket, key, err := parseS3Path(src)
+			if err != nil {
+				return err
+			}
+			if bucket == "" || key == "" {
+				return fmt.Errorf("cannot download a whole connection or bucket: %s", src)
+			}
+
+			// Treat the source as a prefix; a single object is a prefix matching only itself
+			base := path.Base(strings.TrimSuffix(key, "/"))
+			prefix := strings.TrimSuffix(key, "/")
+			token := ""
+			for {
+				page, err := client.ListObjectsV2(ctx, bucket, prefix, "", token)
+				if err != nil {
+					return err
+				}
+				for _, obj := range page.Contents {
+					var rel string
+					switch {
+					case obj.Key == prefix:
+						rel = base
+					case strings.HasPrefix(obj.Key, prefix+"/"):
+						rel = path.Join(base, strings.TrimPrefix(obj.Key, prefix+"/"))
+					default:
+						continue
+					}
+					if strings.HasSuffix(obj.Key, "/") {
+						continue
+					}
+					local := filepath.Join(destDir, filepath.FromSlash(rel))
+					if !isPathWithinParent(local, destDir) {
+						return fmt.Errorf("object key escapes destination: %s", obj.Key)
+					}
+					downloads = append(downloads, download{bucket: bucket, key: obj.Key, local: local, size: obj.Size})
+					total += obj.Size
+				}
+				if !page.IsTruncated || page.NextContinuationToken == "" {
+					break
+				}
+				token = page.NextContinuationToken
+			}
+		}
+
+		for _, d := range downloads {
+			if _, err := os.Stat(d.local); err == nil {
+				return fmt.Errorf("destination file already exists: %s", d.local)
+			}
+		}
+
+		job.SetTotal(total)
+		for i, d := range downloads {
+			job.SetMessage("Downloading %s (%d/%d)", path.Base(d.key), i+1, len(downloads))
+			if err := s.downloadFile(ctx, client, job, d.bucket, d.key, d.local); err != nil {
+				return fmt.Errorf("download %s: %w", d.key, err)
+			}
+		}
+		job.SetResult("files", len(downloads))
+		return nil
+	}), nil
+}
+
+// downloadFile writes an object to localPath, which must not exist yet. The
+// name is claimed with O_EXCL before the download so that a file created in the
+// meantime is never overwritten; the data lands in a temporary file first.
+func (s *S3Manager) downloadFile(ctx context.Context, client *s3Client, job *Job, bucket, key, localPath string) (err error) {
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return err
+	}
+	claim, err := os.OpenFile(localPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	claim.Close()
+	defer func() {
+		if err != nil {
+			os.Remove(localPath)
+		}
+	}()
+
+	body, _, err := client.GetObject(ctx, bucket, key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	out, err := os.CreateTemp(filepath.Dir(localPath), "."+filepath.Base(localPath)+".*.part")
+	if err != nil {
+		return err
+	}
+	tmpPath := out.Name()
+
+	buffer := bufferPool.Get().([]byte)
+	defer bufferPool.Put(buffer)
+
+	_, err = io.CopyBuffer(out, &progressReader{ctx: ctx, r: body, job: job}, buffer)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpPath, 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, localPath)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+	}
+	return err
+}
+
+func parseS3Time(value string) int64 {
+	if value == "" {
+		return 0
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.Unix()
+	}
+	return 0
+}
@@ -0,0 +1,117 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// useFakeS3 serves objects from a stand-in endpoint two keys per list page and
+// returns a connection to it
+func useFakeS3(t *testing.T, objects map[string]string, keys []string) S3Connection {
+	t.Helper()
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "none"))
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDTEST")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path == "/bucket/" && q.Get("list-type") == "2" {
+			start := 0
+			if token := q.Get("continuation-token"); token != "" {
+				fmt.Sscanf(token, "page-%d", &start)
+			}
+			end := min(start+2, len(keys))
+			fmt.Fprint(w, "<ListBucketResult>")
+			for _, key := range keys[start:end] {
+				fmt.Fprintf(w, "<Contents><Key>%s</Key><Size>%d</Size></Contents>", key, len(objects[key]))
+			}
+			if end < len(keys) {
+				fmt.Fprintf(w, "<IsTruncated>true</IsTruncated><NextContinuationToken>page-%d</NextContinuationToken>", end)
+			}
+			fmt.Fprint(w, "</ListBucketResult>")
+			return
+		}
+		content, ok := objects[r.URL.Path[len("/bucket/"):]]
+		if !ok || r.Method != http.MethodGet {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, content)
+	}))
+	t.Cleanup(srv.Close)
+	return S3Connection{ID: "test", Endpoint: srv.URL, PathStyle: true}
+}
+
+func TestS3DownloadFollowsContinuationTokens(t *testing.T) {
+	objects := map[string]string{
+		"dir/a.txt":     "alpha",
+		"dir/b.txt":     "bravo",
+		"dir/sub/c.txt": "charlie",
+		"dir/sub/":      "",
+		"dirty.txt":     "not under dir/",
+	}
+	conn := useFakeS3(t, objects, []string{"dir/a.txt", "dir/b.txt", "dir/sub/", "dir/sub/c.txt", "dirty.txt"})
+	dest := t.TempDir()
+
+	m := NewS3Manager(NewJobManager())
+	id, err := m.Download(conn, []string{"s3://test/bucket/dir"}, dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, _ := m.jobs.Wait(id)
+	if info.Status != JobStatusCompleted {
+		t.Fatalf("download job %s: %s", info.Status, info.Error)
+	}
+	if info.Done != info.Total || info.Total != int64(len("alpha")+len("bravo")+len("charlie")) {
+		t.Errorf("progress %d/%d", info.Done, info.Total)
+	}
+
+	for key, want := range map[string]string{"dir/a.txt": "alpha", "dir/b.txt": "bravo", "dir/sub/c.txt": "charlie"} {
+		got, err := os.ReadFile(filepath.Join(dest, filepath.FromSlash(key)))
+		if err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v; want %q", key, got, err, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dest, "dirty.txt")); err == nil {
+		t.Error("an object outside the prefix was downloaded")
+	}
+}
+
+func TestS3DownloadNeverOverwrites(t *testing.T) {
+	conn := useFakeS3(t, map[string]string{"a.txt": "remote"}, []string{"a.txt"})
+	dest := t.TempDir()
+	local := filepath.Join(dest, "a.txt")
+
+	client, err := newS3Client(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A file that appears after the up-front check must survive as well
+	if err := os.WriteFile(local, []byte("local"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := NewS3Manager(NewJobManager())
+	if err := m.downloadFile(context.Background(), client, nil, "bucket", "a.txt", local); err == nil {
+		t.Error("download over an existing file succeeded")
+	}
+	if got, _ := os.ReadFile(local); string(got) != "local" {
+		t.Errorf("existing file now holds %q", got)
+	}
+
+	fresh := filepath.Join(dest, "b.txt")
+	if err := m.downloadFile(context.Background(), client, nil, "bucket", "a.txt", fresh); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(fresh); string(got) != "remote" {
+		t.Errorf("downloaded %q", got)
+	}
+	if entries, _ := os.ReadDir(dest); len(entries) != 2 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+}
@@ -0,0 +1,68 @@
+//go:build !windows
+
+package backend
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"testing"
+)
+
+func TestS3UploadSkipsNonRegularFiles(t *testing.T) {
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "none"))
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDTEST")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	var mu sync.Mutex
+	uploaded := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		uploaded[r.URL.Path] = string(body)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	root := filepath.Join(t.TempDir(), "src")
+	if err := os.Mkdir(root, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "file.txt"), []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// A FIFO would block the upload forever if it were opened
+	if err := syscall.Mkfifo(filepath.Join(root, "pipe"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("/etc/passwd", filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewS3Manager(NewJobManager())
+	id, err := m.Upload(S3Connection{Endpoint: srv.URL, PathStyle: true}, []string{root}, "s3://test/bucket/up")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, _ := m.jobs.Wait(id)
+	if info.Status != JobStatusCompleted {
+		t.Fatalf("upload job %s: %s", info.Status, info.Error)
+	}
+
+	var keys []string
+	for key := range uploaded {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if len(keys) != 1 || keys[0] != "/bucket/up/src/file.txt" || uploaded[keys[0]] != "data" {
+		t.Errorf("uploaded %v", uploaded)
+	}
+	if info.Result["skipped"] != 2 {
+		t.Errorf("skipped = %v, want 2", info.Result["skipped"])
+	}
+}
@@ -0,0 +1,32 @@
+//go:build !windows
+
+package backend
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// lockSettingsFile takes an exclusive lock shared by every process of the app,
+// so that windows started with --new-window do not overwrite each other's changes
+func lockSettingsFile(path string) (unlock func(), err error) {
+	f, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		err = unix.Flock(int(f.Fd()), unix.LOCK_EX)
+		if err != unix.EINTR {
+			break
+		}
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		unix.Flock(int(f.Fd()), unix.LOCK_UN)
+		f.Close()
+	}, nil
+}
@@ -0,0 +1,28 @@
+//go:build windows
+
+package backend
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockSettingsFile takes an exclusive lock shared by every process of the app,
+// so that windows started with --new-window do not overwrite each other's changes
+func lockSettingsFile(path string) (unlock func(), err error) {
+	f, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	handle := windows.Handle(f.Fd())
+	overlapped := new(windows.Overlapped)
+	if err := windows.LockFileEx(handle, windows.LOCKFILE_EXCLUSIVE_LOCK, 0, 1, 0, overlapped); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		windows.UnlockFileEx(handle, 0, 1, 0, overlapped)
+		f.Close()
+	}, nil
+}
@@ -0,0 +1,553 @@
+package backend
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Share modes
+const (
+	ShareModeReadOnly = "read-only"
+	ShareModeUpload   = "upload"
+)
+
+const (
+	shareTokenCookie   = "le_share_token"
+	shareMaxUploadSize = 4 << 30
+)
+
+// ShareOptions configures a directory share. Shares require a token unless
+// AllowAnonymous is set, which only read-only shares honour. A share listens on
+// the loopback interface unless ExposeOnLAN is set or BindAddress names another.
+type ShareOptions struct {
+	Mode             string `json:"mode" msgpack:"mode"`
+	AllowAnonymous   bool   `json:"allowAnonymous" msgpack:"allowAnonymous"`
+	ExpiresInMinutes int    `json:"expiresInMinutes" msgpack:"expiresInMinutes"`
+	Port             int    `json:"port" msgpack:"port"`
+	BindAddress      string `json:"bindAddress" msgpack:"bindAddress"`
+	ExposeOnLAN      bool   `json:"exposeOnLan" msgpack:"exposeOnLan"`
+}
+
+// ShareInfo describes a running share
+type ShareInfo struct {
+	ID        string   `json:"id" msgpack:"id"`
+	Path      string   `json:"path" msgpack:"path"`
+	Mode      string   `json:"mode" msgpack:"mode"`
+	Port      int      `json:"port" msgpack:"port"`
+	URLs      []string `json:"urls" msgpack:"urls"`
+	Token     string   `json:"token,omitempty" msgpack:"token,omitempty"`
+	CreatedAt int64    `json:"createdAt" msgpack:"createdAt"`
+	ExpiresAt int64    `json:"expiresAt,omitempty" msgpack:"expiresAt,omitempty"`
+}
+
+// ShareAccess is emitted for every request served by a share
+type ShareAccess struct {
+	ShareID    string `json:"shareId" msgpack:"shareId"`
+	Method     string `json:"method" msgpack:"method"`
+	Path       string `json:"path" msgpack:"path"`
+	RemoteAddr string `json:"remoteAddr" msgpack:"remoteAddr"`
+	Status     int    `json:"status" msgpack:"status"`
+	Time       int64  `json:"time" msgpack:"time"`
+}
+
+type shareListingEntry struct {
+	Name    string `json:"name"`
+	IsDir   bool   `json:"isDir"`
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"modTime"`
+}
+
+type activeShare struct {
+	info   ShareInfo
+	root   string
+	server *http.Server
+	expiry *time.Timer
+}
+
+// ShareManager serves local directories over HTTP
+type ShareManager struct {
+	mu           sync.Mutex
+	shares       map[string]*activeShare
+	eventEmitter *EventEmitter
+	fs           *FileSystemManager
+	policy       *PathPolicy
+}
+
+// NewShareManager creates a new share manager whose uploads consult policy
+func NewShareManager(policy *PathPolicy) *ShareManager {
+	return &ShareManager{
+		shares: make(map[string]*activeShare),
+		fs:     &FileSystemManager{},
+		policy: policy,
+	}
+}
+
+// SetContext enables ShareAccess events
+func (m *ShareManager) SetContext(ctx context.Context) {
+	m.mu.Lock()
+	m.eventEmitter = NewEventEmitter(ctx)
+	m.mu.Unlock()
+}
+
+// Start begins serving dir and returns the share details
+func (m *ShareManager) Start(dir string, opts ShareOptions) (ShareInfo, error) {
+	root := filepath.Clean(dir)
+	info, err := os.Stat(root)
+	if err != nil {
+		return ShareInfo{}, fmt.Errorf("cannot access path: %w", err)
+	}
+	if !info.IsDir() {
+		return ShareInfo{}, fmt.Errorf("path is not a directory")
+	}
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+
+	switch opts.Mode {
+	case "":
+		opts.Mode = ShareModeReadOnly
+	case ShareModeReadOnly, ShareModeUpload:
+	default:
+		return ShareInfo{}, fmt.Errorf("unknown share mode: %s", opts.Mode)
+	}
+
+	bind := opts.BindAddress
+	switch {
+	case bind != "":
+	case opts.ExposeOnLAN:
+		bind = "0.0.0.0"
+	default:
+		bind = "127.0.0.1"
+	}
+	listener, err := net.Listen("tcp", net.JoinHostPort(bind, strconv.Itoa(opts.Port)))
+	if err != nil {
+		return ShareInfo{}, fmt.Errorf("failed to listen: %w", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	id, err := randomHex(8)
+	if err != nil {
+		listener.Close()
+		return ShareInfo{}, err
+	}
+
+	share := &activeShare{
+		root: root,
+		info: ShareInfo{
+			ID:        id,
+			Path:      root,
+			Mode:      opts.Mode,
+			Port:      port,
+			CreatedAt: time.Now().Unix(),
+		},
+	}
+	// Anyone who can reach the port could write into an anonymous upload share
+	if !opts.AllowAnonymous || opts.Mode == ShareModeUpload {
+		if share.info.Token, err = randomHex(16); err != nil {
+			listener.Close()
+			return ShareInfo{}, err
+		}
+	}
+	share.info.URLs = shareURLs(bind, port, share.info.Token)
+
+	share.server = &http.Server{
+		Handler:           m.handler(share),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	if opts.ExpiresInMinutes > 0 {
+		ttl := time.Duration(opts.ExpiresInMinutes) * time.Minute
+		share.info.ExpiresAt = time.Now().Add(ttl).Unix()
+		share.expiry = time.AfterFunc(ttl, func() { m.Stop(id) })
+	}
+
+	m.mu.Lock()
+	m.shares[id] = share
+	m.mu.Unlock()
+
+	go func() {
+		if err := share.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logPrintf("Share %s stopped: %v", id, err)
+		}
+	}()
+
+	logPrintf("📤 Sharing %s on port %d (%s)", root, port, opts.Mode)
+	return share.info, nil
+}
+
+// Stop shuts down a share
+func (m *ShareManager) Stop(id string) bool {
+	m.mu.Lock()
+	share, ok := m.shares[id]
+	delete(m.shares, id)
+	m.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	if share.expiry != nil {
+		share.expiry.Stop()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := share.server.Shutdown(ctx); err != nil {
+		share.server.Close()
+	}
+	logPrintf("📤 Stopped share %s (%s)", id, share.root)
+	return true
+}
+
+// List returns all running shares
+func (m *ShareManager) List() []ShareInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	infos := make([]ShareInfo, 0, len(m.shares))
+	for _, share := range m.shares {
+		infos = append(infos, share.info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].CreatedAt < infos[j].CreatedAt })
+	return infos
+}
+
+func (m *ShareManager) handler(share *activeShare) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			m.mu.Lock()
+			emitter := m.eventEmitter
+			m.mu.Unlock()
+			if emitter != nil {
+				emitter.EmitShareAccess(ShareAccess{
+					ShareID:    share.info.ID,
+					Method:     r.Method,
+					Path:       r.URL.Path,
+					RemoteAddr: r.RemoteAddr,
+					Status:     rec.status,
+					Time:       time.Now().Unix(),
+				})
+			}
+		}()
+
+		if !m.authorize(share, rec, r) {
+			http.Error(rec, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case r.URL.Path == "/api/list":
+			m.serveListing(share, rec, r)
+		case r.URL.Path == "/api/upload":
+			m.serveUpload(share, rec, r)
+		default:
+			m.serveContent(share, rec, r)
+		}
+	})
+}
+
+// authorize checks the share token from the query, cookie or bearer header.
+// A valid query token is stored in a cookie so index links keep working.
+func (m *ShareManager) authorize(share *activeShare, w http.ResponseWriter, r *http.Request) bool {
+	if share.info.Token == "" {
+		return true
+	}
+	if token := r.URL.Query().Get("token"); token != "" && tokensEqual(token, share.info.Token) {
+		http.SetCookie(w, &http.Cookie{Name: shareTokenCookie, Value: token, Path: "/", HttpOnly: true, SameSite: http.SameSiteStrictMode})
+		return true
+	}
+	if cookie, err := r.Cookie(shareTokenCookie); err == nil && tokensEqual(cookie.Value, share.info.Token) {
+		return true
+	}
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && tokensEqual(bearer, share.info.Token) {
+		return true
+	}
+	return false
+}
+
+// resolve maps a URL path onto the shared directory, refusing anything that
+// escapes the share root (including via symlinks) and the hidden entries that
+// listings leave out.
+func (m *ShareManager) resolve(share *activeShare, urlPath string) (string, bool) {
+	rel := path.Clean("/" + urlPath)
+	for _, part := range strings.Split(rel, "/") {
+		if strings.HasPrefix(part, ".") {
+			return "", false
+		}
+	}
+	full := filepath.Join(share.root, filepath.FromSlash(rel))
+	if !m.fs.isPathWithinParent(full, share.root) {
+		return "", false
+	}
+	if resolved, err := filepath.EvalSymlinks(full); err == nil {
+		if !m.fs.isPathWithinParent(resolved, share.root) {
+			return "", false
+		}
+	}
+	return full, true
+}
+
+func (m *ShareManager) serveContent(share *activeShare, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	full, ok := m.resolve(share, r.URL.Path)
+	if !ok {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	info, err := os.Stat(full)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	if info.IsDir() {
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			http.Redirect(w, r, r.URL.Path+"/", http.StatusMovedPermanently)
+			return
+		}
+		entries, err := m.listEntries(share, full)
+		if err != nil {
+			http.Error(w, "Cannot read directory", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		shareIndexTemplate.Execute(w, map[string]interface{}{
+			"Path":      r.URL.Path,
+			"Entries":   entries,
+			"Upload":    share.info.Mode == ShareModeUpload,
+			"UploadDir": url.QueryEscape(r.URL.Path),
+		})
+		return
+	}
+
+	f, err := os.Open(full)
+	if err != nil {
+		http.Error(w, "Cannot open file", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+	// ServeContent handles Range, If-Modified-Since and content types for media
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+}
+
+func (m *ShareManager) serveListing(share *activeShare, w http.ResponseWriter, r *http.Request) {
+	full, ok := m.resolve(share, r.URL.Query().Get("path"))
+	if !ok {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	entries, err := m.listEntries(share, full)
+	if err != nil {
+		http.Error(w, "Cannot read directory", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"path":    path.Clean("/" + r.URL.Query().Get("path")),
+		"mode":    share.info.Mode,
+		"entries": entries,
+	})
+}
+
+func (m *ShareManager) serveUpload(share *activeShare, w http.ResponseWriter, r *http.Request) {
+	if share.info.Mode != ShareModeUpload {
+		http.Error(w, "Share is read-only", http.StatusForbidden)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	dir, ok := m.resolve(share, r.URL.Query().Get("path"))
+	if !ok {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		http.Error(w, "Target is not a directory", http.StatusNotFound)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, shareMaxUploadSize)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, "Expected multipart upload", http.StatusBadRequest)
+		return
+	}
+
+	var saved []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			http.Error(w, "Upload failed", http.StatusBadRequest)
+			return
+		}
+		if part.FileName() == "" {
+			continue
+		}
+		name, err := m.fs.validateAndSanitizeFileName(filepath.Base(part.FileName()))
+		if err == nil && strings.HasPrefix(name, ".") {
+			err = fmt.Errorf("hidden files cannot be uploaded")
+		}
+		if err != nil {
+			http.Error(w, "Invalid file name", http.StatusBadRequest)
+			return
+		}
+		target := filepath.Join(dir, name)
+		// Nobody is at the app to confirm for a remote client, so confirm refuses too
+		if err := m.policy.Check(OpCreate, target); err != nil {
+			logPrintf("⛔ Share upload refused: %v", err)
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			http.Error(w, "File already exists or cannot be created", http.StatusConflict)
+			return
+		}
+		_, err = io.Copy(out, part)
+		out.Close()
+		if err != nil {
+			os.Remove(target)
+			http.Error(w, "Upload failed", http.StatusInternalServerError)
+			return
+		}
+		saved = append(saved, name)
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "text/html") {
+		http.Redirect(w, r, path.Clean("/"+r.URL.Query().Get("path"))+"/", http.StatusSeeOther)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"saved": saved})
+}
+
+func (m *ShareManager) listEntries(share *activeShare, dir string) ([]shareListingEntry, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]shareListingEntry, 0, len(dirEntries))
+	for _, de := range dirEntries {
+		if strings.HasPrefix(de.Name(), ".") {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			continue
+		}
+		if de.Type()&os.ModeSymlink != 0 {
+			// Links that lead out of the share cannot be opened, so leave them out
+			resolved, err := filepath.EvalSymlinks(filepath.Join(dir, de.Name()))
+			if err != nil || !m.fs.isPathWithinParent(resolved, share.root) {
+				continue
+			}
+			if info, err = os.Stat(resolved); err != nil {
+				continue
+			}
+		}
+		entry := shareListingEntry{Name: de.Name(), IsDir: info.IsDir(), ModTime: info.ModTime().Unix()}
+		if !info.IsDir() {
+			entry.Size = info.Size()
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].IsDir != entries[j].IsDir {
+			return entries[i].IsDir
+		}
+		return strings.ToLower(entries[i].Name) < strings.ToLower(entries[j].Name)
+	})
+	return entries, nil
+}
+
+// shareURLs lists the addresses a colleague on the LAN can use
+func shareURLs(bind string, port int, token string) []string {
+	suffix := "/"
+	if token != "" {
+		suffix += "?token=" + token
+	}
+
+	var hosts []string
+	if bind != "0.0.0.0" && bind != "::" {
+		hosts = append(hosts, bind)
+	} else if addrs, err := net.InterfaceAddrs(); err == nil {
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || ipNet.IP.IsLoopback() || ipNet.IP.To4() == nil {
+				continue
+			}
+			hosts = append(hosts, ipNet.IP.String())
+		}
+	}
+	if len(hosts) == 0 {
+		hosts = append(hosts, "127.0.0.1")
+	}
+
+	urls := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		urls = append(urls, fmt.Sprintf("http://%s%s", net.JoinHostPort(host, strconv.Itoa(port)), suffix))
+	}
+	return urls
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func tokensEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.status = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+var shareIndexTemplate = template.Must(template.New("index").Funcs(template.FuncMap{
+	"pathEscape": url.PathEscape,
+}).Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Path}}</title>
+<style>body{font-family:monospace;margin:2em}a{text-decoration:none}td{padding:2px 12px}</style>
+</head><body>
+<h1>{{.Path}}</h1>
+<table>
+{{if ne .Path "/"}}<tr><td><a href="../">../</a></td><td></td></tr>{{end}}
+{{range .Entries}}<tr><td><a href="{{pathEscape .Name}}{{if .IsDir}}/{{end}}">{{.Name}}{{if .IsDir}}/{{end}}</a></td><td>{{if not .IsDir}}{{.Size}}{{end}}</td></tr>
+{{end}}</table>
+{{if .Upload}}<form method="post" enctype="multipart/form-data" action="/api/upload?path={{.UploadDir}}">
+<input type="file" name="file" multiple><button type="submit">Upload</button></form>{{end}}
+</body></html>
+`))
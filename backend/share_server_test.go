@@ -0,0 +1,207 @@
+package backend
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// newTestShare shares a fresh directory holding public.txt next to a secret
+// file outside the share
+func newTestShare(t *testing.T, mode, token string) (*ShareManager, *activeShare, string) {
+	t.Helper()
+	parent := t.TempDir()
+	root := filepath.Join(parent, "shared")
+	writeShareFile(t, filepath.Join(root, "public.txt"), "public")
+	writeShareFile(t, filepath.Join(root, ".hidden"), "hidden")
+	writeShareFile(t, filepath.Join(parent, "secret.txt"), "secret")
+
+	m := NewShareManager(nil)
+	share := &activeShare{root: root, info: ShareInfo{ID: "test", Mode: mode, Token: token}}
+	return m, share, parent
+}
+
+func writeShareFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func serveShare(m *ShareManager, share *activeShare, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	m.handler(share).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestShareStaysInsideRoot(t *testing.T) {
+	m, share, parent := newTestShare(t, ShareModeReadOnly, "")
+	if err := os.Symlink(parent, filepath.Join(share.root, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(parent, "secret.txt"), filepath.Join(share.root, "leak")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("public.txt", filepath.Join(share.root, "alias")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, target := range []string{
+		"/../secret.txt",
+		"/..%2fsecret.txt",
+		"/%2e%2e/secret.txt",
+		"/%2e%2e%2fsecret.txt",
+		"/public.txt/../../secret.txt",
+		"/escape/secret.txt",
+		"/leak",
+		"/.hidden",
+		"/api/list?path=escape",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "http://share"+target, nil)
+		rec := serveShare(m, share, req)
+		body := rec.Body.String()
+		if rec.Code == http.StatusOK || strings.Contains(body, "secret") || strings.Contains(body, "hidden") {
+			t.Errorf("GET %s = %d %q; want it refused", target, rec.Code, body)
+		}
+	}
+
+	// Listings above the root are clamped to the root, which leaves out the
+	// hidden file and the links that lead outside
+	for _, target := range []string{"/api/list?path=../", "/api/list?path=%2e%2e%2f", "/api/list?path=/"} {
+		rec := serveShare(m, share, httptest.NewRequest(http.MethodGet, "http://share"+target, nil))
+		body := rec.Body.String()
+		if rec.Code != http.StatusOK || !strings.Contains(body, `"public.txt"`) || !strings.Contains(body, `"alias"`) {
+			t.Errorf("GET %s = %d %q; want the share root", target, rec.Code, body)
+		}
+		for _, name := range []string{"secret", "shared", "hidden", "escape", "leak"} {
+			if strings.Contains(body, name) {
+				t.Errorf("GET %s lists %s: %s", target, name, body)
+			}
+		}
+	}
+
+	for _, target := range []string{"/public.txt", "/alias"} {
+		rec := serveShare(m, share, httptest.NewRequest(http.MethodGet, "http://share"+target, nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != "public" {
+			t.Errorf("GET %s = %d %q", target, rec.Code, rec.Body)
+		}
+	}
+}
+
+func TestShareRequiresToken(t *testing.T) {
+	m, share, _ := newTestShare(t, ShareModeReadOnly, "right-token")
+
+	tests := []struct {
+		name   string
+		target string
+		header func(*http.Request)
+		want   int
+	}{
+		{"missing", "/public.txt", nil, http.StatusUnauthorized},
+		{"wrong query", "/public.txt?token=wrong-token", nil, http.StatusUnauthorized},
+		{"empty query", "/public.txt?token=", nil, http.StatusUnauthorized},
+		{"wrong cookie", "/public.txt", func(r *http.Request) {
+			r.AddCookie(&http.Cookie{Name: shareTokenCookie, Value: "wrong-token"})
+		}, http.StatusUnauthorized},
+		{"wrong bearer", "/public.txt", func(r *http.Request) { r.Header.Set("Authorization", "Bearer wrong") }, http.StatusUnauthorized},
+		{"query", "/public.txt?token=right-token", nil, http.StatusOK},
+		{"cookie", "/public.txt", func(r *http.Request) {
+			r.AddCookie(&http.Cookie{Name: shareTokenCookie, Value: "right-token"})
+		}, http.StatusOK},
+		{"bearer", "/public.txt", func(r *http.Request) { r.Header.Set("Authorization", "Bearer right-token") }, http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "http://share"+tt.target, nil)
+		if tt.header != nil {
+			tt.header(req)
+		}
+		rec := serveShare(m, share, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s token: %d, want %d", tt.name, rec.Code, tt.want)
+		}
+		if tt.want == http.StatusUnauthorized && strings.Contains(rec.Body.String(), "public") {
+			t.Errorf("%s token: content served without authorization", tt.name)
+		}
+	}
+}
+
+func uploadRequest(t *testing.T, target, name, content string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("file", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte(content))
+	mw.Close()
+	req := httptest.NewRequest(http.MethodPost, "http://share"+target, &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestShareUploadNeverOverwrites(t *testing.T) {
+	m, share, parent := newTestShare(t, ShareModeUpload, "")
+
+	rec := serveShare(m, share, uploadRequest(t, "/api/upload?path=/", "public.txt", "replaced"))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("upload over an existing file = %d, want 409", rec.Code)
+	}
+	if got, _ := os.ReadFile(filepath.Join(share.root, "public.txt")); string(got) != "public" {
+		t.Errorf("existing file now holds %q", got)
+	}
+
+	rec = serveShare(m, share, uploadRequest(t, "/api/upload?path=/", "new.txt", "fresh"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("upload = %d %s", rec.Code, rec.Body)
+	}
+	if got, _ := os.ReadFile(filepath.Join(share.root, "new.txt")); string(got) != "fresh" {
+		t.Errorf("uploaded file holds %q", got)
+	}
+
+	// Names are reduced to their base, so uploads cannot leave the target folder
+	serveShare(m, share, uploadRequest(t, "/api/upload?path=/", "../escaped.txt", "out"))
+	serveShare(m, share, uploadRequest(t, "/api/upload?path=../", "escaped.txt", "out"))
+	if _, err := os.Stat(filepath.Join(parent, "escaped.txt")); err == nil {
+		t.Error("an upload escaped the share")
+	}
+}
+
+func TestShareUploadRefusedOnReadOnlyShares(t *testing.T) {
+	m, share, _ := newTestShare(t, ShareModeReadOnly, "")
+	rec := serveShare(m, share, uploadRequest(t, "/api/upload?path=/", "new.txt", "data"))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("upload to a read-only share = %d, want 403", rec.Code)
+	}
+	if _, err := os.Stat(filepath.Join(share.root, "new.txt")); err == nil {
+		t.Error("a read-only share accepted an upload")
+	}
+}
+
+func TestShareBindsLoopbackByDefault(t *testing.T) {
+	m := NewShareManager(nil)
+	info, err := m.Start(t.TempDir(), ShareOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop(info.ID)
+
+	if len(info.URLs) != 1 || !strings.HasPrefix(info.URLs[0], "http://127.0.0.1:") {
+		t.Errorf("URLs = %v, want only the loopback address", info.URLs)
+	}
+	conn, err := net.Dial("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(info.Port)))
+	if err != nil {
+		t.Fatalf("share is not reachable on loopback: %v", err)
+	}
+	conn.Close()
+}
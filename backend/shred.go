@@ -0,0 +1,188 @@
+package backend
+
+import (
+	"context"
+	crand "crypto/rand"
+	"fmt"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const maxShredPasses = 35
+
+// validateShredPath rejects system roots, their protected top-level
+// directories and anything inside them. Symlinks in the parent are resolved so
+// a link cannot smuggle a system path past the check.
+func validateShredPath(path string, systemRoots []string) (string, error) {
+	if path == "" || !filepath.IsAbs(path) {
+		return "", fmt.Errorf("path must be absolute: %q", path)
+	}
+	clean := filepath.Clean(path)
+	if parent, err := filepath.EvalSymlinks(filepath.Dir(clean)); err == nil {
+		clean = filepath.Join(parent, filepath.Base(clean))
+	}
+
+	dirs, ok := protectedSystemDirs[runtime.GOOS]
+	if !ok {
+		dirs = protectedSystemDirs["default"]
+	}
+	sameOrInside := func(p, dir string) bool {
+		if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+			p, dir = strings.ToLower(p), strings.ToLower(dir)
+		}
+		rel, err := filepath.Rel(dir, p)
+		return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+	}
+
+	for _, root := range systemRoots {
+		root = filepath.Clean(root)
+		if strings.EqualFold(clean, root) {
+			return "", fmt.Errorf("refusing to shred system root %s", root)
+		}
+		for _, dir := range dirs {
+			if sameOrInside(clean, filepath.Join(root, dir)) {
+				return "", fmt.Errorf("refusing to shred inside system directory %s", filepath.Join(root, dir))
+			}
+		}
+	}
+	return clean, nil
+}
+
+// shredder overwrites and removes files for a single job
+type shredder struct {
+	ctx    context.Context
+	job    *Job
+	passes int
+	random *rand.ChaCha8
+}
+
+func newShredder(ctx context.Context, job *Job, passes int) (*shredder, error) {
+	var seed [32]byte
+	if _, err := crand.Read(seed[:]); err != nil {
+		return nil, err
+	}
+	return &shredder{ctx: ctx, job: job, passes: passes, random: rand.NewChaCha8(seed)}, nil
+}
+
+// shredPath shreds a file, or a directory tree depth-first
+func (s *shredder) shredPath(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return s.shredFile(path, info)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := s.shredPath(filepath.Join(path, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return s.removeObscured(path)
+}
+
+// shredFile overwrites a regular file pass by pass, syncing after each, then
+// truncates, renames and unlinks it. Symlinks and special files are only
+// unlinked; their targets are left alone.
+func (s *shredder) shredFile(path string, info fs.FileInfo) error {
+	if info.Mode().IsRegular() && info.Size() > 0 {
+		f, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+		if err := s.overwrite(f, info.Size()); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Truncate(0); err != nil {
+			f.Close()
+			return err
+		}
+		f.Sync()
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return s.removeObscured(path)
+}
+
+// overwrite writes random data on every pass except the last, which writes zeros
+func (s *shredder) overwrite(f *os.File, size int64) error {
+	buffer := bufferPool.Get().([]byte)
+	defer bufferPool.Put(buffer)
+
+	for pass := 1; pass <= s.passes; pass++ {
+		zeros := s.passes > 1 && pass == s.passes
+		if zeros {
+			clear(buffer)
+		}
+		if _, err := f.Seek(0, 0); err != nil {
+			return err
+		}
+
+		for written := int64(0); written < size; {
+			if err := s.ctx.Err(); err != nil {
+				return err
+			}
+			chunk := buffer
+			if remaining := size - written; remaining < int64(len(chunk)) {
+				chunk = chunk[:remaining]
+			}
+			if !zeros {
+				s.random.Read(chunk)
+			}
+			n, err := f.Write(chunk)
+			written += int64(n)
+			s.job.Add(int64(n))
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := f.Sync(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeObscured renames path to a random name in the same directory so the
+// original name does not linger in directory entries, then removes it
+func (s *shredder) removeObscured(path string) error {
+	target := path
+	if name, err := randomHex(8); err == nil {
+		candidate := filepath.Join(filepath.Dir(path), name)
+		if _, err := os.Lstat(candidate); os.IsNotExist(err) && os.Rename(path, candidate) == nil {
+			target = candidate
+		}
+	}
+	return os.Remove(target)
+}
+
+// shredSize returns the bytes that will be overwritten for path
+func shredSize(path string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			total += info.Size()
+		}
+		return nil
+	})
+	return total, err
+}
@@ -0,0 +1,129 @@
+package backend
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// trashScheme is the virtual location listing the platform trash / recycle bin
+const trashScheme = "trash://"
+
+// isTrashPath reports whether p addresses the trash view
+func isTrashPath(p string) bool {
+	return strings.HasPrefix(strings.ToLower(p), trashScheme)
+}
+
+// Each platform provides listTrashItems, restoreTrashItem(id, destDir) and
+// deleteTrashItem(id); the operations below are shared.
+
+// ListTrash returns every item in the trash, newest first
+func (fo *FileOperationsManager) ListTrash() ([]TrashItem, error) {
+	items, err := fo.listTrashItems()
+	if err != nil {
+		return nil, err
+	}
+	sortTrashItems(items)
+	return items, nil
+}
+
+// RestoreFromTrash moves trashed items back to their original location.
+// Items whose original path is occupied are left in the trash.
+func (fo *FileOperationsManager) RestoreFromTrash(ids []string) error {
+	return eachTrashItem("restore", ids, func(id string) error {
+		return fo.restoreTrashItem(id, "")
+	})
+}
+
+// RestoreFromTrashTo moves trashed items into destDir under their original names
+func (fo *FileOperationsManager) RestoreFromTrashTo(ids []string, destDir string) error {
+	info, err := os.Stat(destDir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("destination is not a directory: %s", destDir)
+	}
+	return eachTrashItem("restore", ids, func(id string) error {
+		return fo.restoreTrashItem(id, destDir)
+	})
+}
+
+// DeleteFromTrash permanently deletes the given trash items
+func (fo *FileOperationsManager) DeleteFromTrash(ids []string) error {
+	return eachTrashItem("delete", ids, fo.deleteTrashItem)
+}
+
+// EmptyTrash permanently deletes everything in the trash
+func (fo *FileOperationsManager) EmptyTrash() error {
+	_, err := fo.purgeTrash(func(TrashItem) bool { return true })
+	return err
+}
+
+// PurgeTrashOlderThan permanently deletes items trashed more than age ago
+func (fo *FileOperationsManager) PurgeTrashOlderThan(age time.Duration) (int, error) {
+	cutoff := time.Now().Add(-age).Unix()
+	return fo.purgeTrash(func(item TrashItem) bool { return item.DeletedAt < cutoff })
+}
+
+func (fo *FileOperationsManager) purgeTrash(match func(TrashItem) bool) (int, error) {
+	items, err := fo.listTrashItems()
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	var firstErr error
+	for _, item := range items {
+		if !match(item) {
+			continue
+		}
+		if err := fo.deleteTrashItem(item.ID); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		purged++
+	}
+
+	logPrintf("🧹 Purged %d trash items", purged)
+	return purged, firstErr
+}
+
+// eachTrashItem applies op to every ID and reports the failures together
+func eachTrashItem(verb string, ids []string, op func(id string) error) error {
+	var failed []string
+	for _, id := range ids {
+		if err := op(id); err != nil {
+			logPrintf("Failed to %s %s: %v", verb, id, err)
+			failed = append(failed, err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to %s %d of %d items: %s", verb, len(failed), len(ids), strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// moveOrCopy renames src to dst, copying across volumes when a rename is not possible
+func (fo *FileOperationsManager) moveOrCopy(src, dst string) error {
+	if err := os.Rename(src, dst); err != nil {
+		if copyErr := fo.copyAndDelete(src, dst); copyErr != nil {
+			return fmt.Errorf("%v (copy fallback: %w)", err, copyErr)
+		}
+	}
+	return nil
+}
+
+// sortTrashItems orders items newest first, then by name
+func sortTrashItems(items []TrashItem) {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].DeletedAt != items[j].DeletedAt {
+			return items[i].DeletedAt > items[j].DeletedAt
+		}
+		return items[i].Name < items[j].Name
+	})
+}
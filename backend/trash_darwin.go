@@ -0,0 +1,85 @@
+//go:build darwin
+
+package backend
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// macOS keeps no per-item metadata we can read: Finder stores "Put Back"
+// locations in ~/.Trash/.DS_Store. Items are listed with their trash time and
+// can be restored to a chosen folder, but not to an unknown original path.
+
+func macTrashDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".Trash")
+}
+
+func (fo *FileOperationsManager) listTrashItems() ([]TrashItem, error) {
+	dir := macTrashDir()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read trash (Full Disk Access may be required): %w", err)
+	}
+
+	items := make([]TrashItem, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Name() == ".DS_Store" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		info, err := os.Lstat(path)
+		if err != nil {
+			continue
+		}
+		item := TrashItem{
+			ID:        path,
+			Name:      entry.Name(),
+			DeletedAt: info.ModTime().Unix(),
+			IsDir:     info.IsDir(),
+		}
+		// Moving an item into the trash updates its change time
+		if st, ok := info.Sys().(*syscall.Stat_t); ok {
+			item.DeletedAt = st.Ctimespec.Sec
+		}
+		if !info.IsDir() {
+			item.Size = info.Size()
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func macTrashItemPath(id string) (string, error) {
+	id = filepath.Clean(id)
+	if filepath.Dir(id) != macTrashDir() || filepath.Base(id) == ".DS_Store" {
+		return "", fmt.Errorf("not a trash item: %s", id)
+	}
+	return id, nil
+}
+
+func (fo *FileOperationsManager) restoreTrashItem(id, destDir string) error {
+	path, err := macTrashItemPath(id)
+	if err != nil {
+		return err
+	}
+	if destDir == "" {
+		return fmt.Errorf("original location of %s is unknown; choose a folder to restore to", filepath.Base(path))
+	}
+	target := filepath.Join(destDir, filepath.Base(path))
+	if _, err := os.Lstat(target); err == nil {
+		return &os.PathError{Op: "restore", Path: target, Err: os.ErrExist}
+	}
+	return fo.moveOrCopy(path, target)
+}
+
+func (fo *FileOperationsManager) deleteTrashItem(id string) error {
+	path, err := macTrashItemPath(id)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(path)
+}
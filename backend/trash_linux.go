@@ -0,0 +1,499 @@
+//go:build linux
+
+package backend
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Freedesktop.org Trash specification 1.0:
+// https://specifications.freedesktop.org/trash-spec/trashspec-1.0.html
+
+const (
+	trashInfoExt        = ".trashinfo"
+	trashDeletionLayout = "2006-01-02T15:04:05"
+	trashDirSizesFile   = "directorysizes"
+)
+
+// xdgTrashDir is one trash location: the home trash or a per-volume trash
+type xdgTrashDir struct {
+	root   string // contains files/ and info/
+	topdir string // mount point for per-volume trashes, "" for the home trash
+}
+
+func (t xdgTrashDir) filesDir() string { return filepath.Join(t.root, "files") }
+func (t xdgTrashDir) infoDir() string  { return filepath.Join(t.root, "info") }
+
+func (t xdgTrashDir) ensure() error {
+	if err := os.MkdirAll(t.filesDir(), 0700); err != nil {
+		return err
+	}
+	return os.MkdirAll(t.infoDir(), 0700)
+}
+
+func homeTrash() xdgTrashDir {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, _ := os.UserHomeDir()
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return xdgTrashDir{root: filepath.Join(dataHome, "Trash")}
+}
+
+func deviceOf(path string) (uint64, error) {
+	var st syscall.Stat_t
+	if err := syscall.Lstat(path, &st); err != nil {
+		return 0, err
+	}
+	return uint64(st.Dev), nil
+}
+
+// mountTopdir walks up from path to the root of the filesystem containing it
+func mountTopdir(path string) string {
+	dev, err := deviceOf(path)
+	if err != nil {
+		return "/"
+	}
+	dir := path
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		if parentDev, err := deviceOf(parent); err != nil || parentDev != dev {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+// volumeTrash returns the per-volume trash under topdir, creating
+// $topdir/.Trash-$uid when the shared $topdir/.Trash is not usable
+func volumeTrash(topdir string, create bool) (xdgTrashDir, bool) {
+	uid := strconv.Itoa(os.Getuid())
+
+	// $topdir/.Trash must be a real sticky directory, never a symlink
+	shared := filepath.Join(topdir, ".Trash")
+	if info, err := os.Lstat(shared); err == nil && info.IsDir() && info.Mode()&os.ModeSticky != 0 {
+		t := xdgTrashDir{root: filepath.Join(shared, uid), topdir: topdir}
+		if _, err := os.Stat(t.root); err == nil || (create && t.ensure() == nil) {
+			return t, true
+		}
+	}
+
+	t := xdgTrashDir{root: filepath.Join(topdir, ".Trash-"+uid), topdir: topdir}
+	if info, err := os.Lstat(t.root); err == nil {
+		return t, info.IsDir()
+	}
+	if create && t.ensure() == nil {
+		return t, true
+	}
+	return xdgTrashDir{}, false
+}
+
+// trashFor picks the trash directory for path: the home trash when path is on
+// the same filesystem, the volume's own trash otherwise
+func trashFor(path string) xdgTrashDir {
+	home := homeTrash()
+	if err := home.ensure(); err == nil {
+		pathDev, err1 := deviceOf(path)
+		homeDev, err2 := deviceOf(home.root)
+		if err1 == nil && err2 == nil && pathDev == homeDev {
+			return home
+		}
+	}
+	if t, ok := volumeTrash(mountTopdir(path), true); ok {
+		return t
+	}
+	return home
+}
+
+// knownTrashDirs returns the home trash plus every per-volume trash that exists
+func knownTrashDirs() []xdgTrashDir {
+	dirs := []xdgTrashDir{homeTrash()}
+	seen := map[string]bool{dirs[0].root: true}
+
+	f, err := os.Open("/proc/self/mounts")
+	if err != nil {
+		return dirs
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		topdir := unescapeMountField(fields[1])
+		// A dead network mount must not hang every trash listing
+		t, err := withPathDeadline(context.Background(), topdir, func() (xdgTrashDir, error) {
+			if t, ok := volumeTrash(topdir, false); ok {
+				return t, nil
+			}
+			return xdgTrashDir{}, os.ErrNotExist
+		})
+		if err == nil && !seen[t.root] {
+			seen[t.root] = true
+			dirs = append(dirs, t)
+		}
+	}
+	return dirs
+}
+
+// unescapeMountField decodes the octal escapes (\040 for space) used in /proc/self/mounts
+func unescapeMountField(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// reserveTrashName creates info/<name>.trashinfo exclusively, adding a counter
+// to the name until it is unique, and writes the metadata into it
+func reserveTrashName(t xdgTrashDir, base, content string) (string, error) {
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	if stem == "" {
+		stem, ext = base, ""
+	}
+
+	for n := 1; n < 10000; n++ {
+		name := base
+		if n > 1 {
+			name = fmt.Sprintf("%s.%d%s", stem, n, ext)
+		}
+		if _, err := os.Lstat(filepath.Join(t.filesDir(), name)); err == nil {
+			continue
+		}
+		f, err := os.OpenFile(filepath.Join(t.infoDir(), name+trashInfoExt), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		_, err = f.WriteString(content)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(filepath.Join(t.infoDir(), name+trashInfoExt))
+			return "", err
+		}
+		return name, nil
+	}
+	return "", fmt.Errorf("no free trash name for %s", base)
+}
+
+// encodeTrashPath percent-encodes a path for the Path= key, keeping separators
+func encodeTrashPath(p string) string {
+	return (&url.URL{Path: p}).EscapedPath()
+}
+
+// moveToXDGTrash trashes filePath following the Trash specification
+func (fo *FileOperationsManager) moveToXDGTrash(filePath string) error {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Lstat(absPath); err != nil {
+		return err
+	}
+
+	t := trashFor(absPath)
+	if err := t.ensure(); err != nil {
+		return fmt.Errorf("cannot create trash directory %s: %w", t.root, err)
+	}
+
+	// Per-volume trashes store paths relative to the volume so it can be remounted elsewhere
+	infoPath := absPath
+	if t.topdir != "" {
+		if rel, err := filepath.Rel(t.topdir, absPath); err == nil {
+			infoPath = rel
+		}
+	}
+	content := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+		encodeTrashPath(infoPath), time.Now().Format(trashDeletionLayout))
+
+	name, err := reserveTrashName(t, filepath.Base(absPath), content)
+	if err != nil {
+		return err
+	}
+
+	target := filepath.Join(t.filesDir(), name)
+	if err := os.Rename(absPath, target); err != nil {
+		// Falling back to the home trash on another device means copying
+		if !errors.Is(err, syscall.EXDEV) {
+			os.Remove(filepath.Join(t.infoDir(), name+trashInfoExt))
+			return err
+		}
+		// copyAndDelete removes a partial copy itself. Once it has started
+		// removing the original, target is the only complete copy and stays
+		// in the trash with its info file so it can still be restored.
+		if err := fo.copyAndDelete(absPath, target); err != nil {
+			var removalErr *sourceRemovalError
+			if !errors.As(err, &removalErr) {
+				os.Remove(filepath.Join(t.infoDir(), name+trashInfoExt))
+			}
+			return err
+		}
+	}
+
+	logPrintf("🗑️ Trashed %s as %s", absPath, target)
+	return nil
+}
+
+// readTrashInfo parses an info file and returns the original path and deletion time
+func readTrashInfo(t xdgTrashDir, infoFile string) (string, time.Time, error) {
+	f, err := os.Open(infoFile)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer f.Close()
+
+	var original string
+	var deleted time.Time
+	inSection := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inSection = line == "[Trash Info]"
+			continue
+		}
+		if !inSection {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Path":
+			if decoded, err := url.PathUnescape(value); err == nil {
+				original = decoded
+			}
+		case "DeletionDate":
+			deleted, _ = time.ParseInLocation(trashDeletionLayout, value, time.Local)
+		}
+	}
+	if original == "" {
+		return "", time.Time{}, fmt.Errorf("invalid trash info file: %s", infoFile)
+	}
+	if !filepath.IsAbs(original) {
+		original = filepath.Join(t.topdir, original)
+	}
+	return filepath.Clean(original), deleted, nil
+}
+
+func trashItemFor(t xdgTrashDir, infoFile string) (TrashItem, error) {
+	name := strings.TrimSuffix(filepath.Base(infoFile), trashInfoExt)
+	trashed := filepath.Join(t.filesDir(), name)
+
+	info, err := os.Lstat(trashed)
+	if err != nil {
+		return TrashItem{}, err
+	}
+	original, deleted, err := readTrashInfo(t, infoFile)
+	if err != nil {
+		return TrashItem{}, err
+	}
+
+	item := TrashItem{
+		ID:           trashed,
+		Name:         filepath.Base(original),
+		OriginalPath: original,
+		DeletedAt:    deleted.Unix(),
+		IsDir:        info.IsDir(),
+	}
+	if !info.IsDir() {
+		item.Size = info.Size()
+	}
+	return item, nil
+}
+
+func (fo *FileOperationsManager) listTrashItems() ([]TrashItem, error) {
+	var items []TrashItem
+	for _, t := range knownTrashDirs() {
+		infos, err := filepath.Glob(filepath.Join(t.infoDir(), "*"+trashInfoExt))
+		if err != nil {
+			continue
+		}
+		cached := readTrashDirSizes(t)
+		fresh := make(map[string]trashDirSize)
+		for _, infoFile := range infos {
+			item, err := trashItemFor(t, infoFile)
+			if err != nil {
+				continue
+			}
+			if item.IsDir {
+				item.Size = trashedDirSize(item, infoFile, cached, fresh)
+			}
+			items = append(items, item)
+		}
+		if !maps.Equal(cached, fresh) {
+			if err := writeTrashDirSizes(t, fresh); err != nil {
+				logPrintf("Cannot update %s: %v", filepath.Join(t.root, trashDirSizesFile), err)
+			}
+		}
+	}
+	return items, nil
+}
+
+// trashDirSize is one entry of a trash's directorysizes cache: the size of a
+// trashed directory and the mtime of its info file when it was measured
+type trashDirSize struct {
+	size  int64
+	mtime int64
+}
+
+// trashedDirSize returns the size of a trashed directory from the cache when
+// the entry is as new as its info file, and measures it otherwise. Directories
+// that cannot be measured in time report -1.
+func trashedDirSize(item TrashItem, infoFile string, cached, fresh map[string]trashDirSize) int64 {
+	info, err := os.Stat(infoFile)
+	if err != nil {
+		return -1
+	}
+	name, mtime := filepath.Base(item.ID), info.ModTime().Unix()
+	if entry, ok := cached[name]; ok && entry.mtime == mtime {
+		fresh[name] = entry
+		return entry.size
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pathCallTimeout)
+	defer cancel()
+	size, err := diskUsage(ctx, item.ID)
+	if err != nil {
+		return -1
+	}
+	fresh[name] = trashDirSize{size: size, mtime: mtime}
+	return size
+}
+
+// readTrashDirSizes parses $trash/directorysizes, whose lines are
+// "size mtime percent-encoded-name". Malformed lines are skipped.
+func readTrashDirSizes(t xdgTrashDir) map[string]trashDirSize {
+	sizes := make(map[string]trashDirSize)
+	f, err := os.Open(filepath.Join(t.root, trashDirSizesFile))
+	if err != nil {
+		return sizes
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		size, err1 := strconv.ParseInt(fields[0], 10, 64)
+		mtime, err2 := strconv.ParseInt(fields[1], 10, 64)
+		name, err3 := url.PathUnescape(fields[2])
+		if err1 != nil || err2 != nil || err3 != nil {
+			continue
+		}
+		sizes[name] = trashDirSize{size: size, mtime: mtime}
+	}
+	return sizes
+}
+
+// writeTrashDirSizes replaces $trash/directorysizes atomically, as the
+// specification requires
+func writeTrashDirSizes(t xdgTrashDir, sizes map[string]trashDirSize) error {
+	f, err := os.CreateTemp(t.root, "."+trashDirSizesFile+".*")
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for name, entry := range sizes {
+		fmt.Fprintf(w, "%d %d %s\n", entry.size, entry.mtime, url.PathEscape(name))
+	}
+	err = w.Flush()
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), filepath.Join(t.root, trashDirSizesFile))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// lookupTrashItem resolves an ID from listTrashItems, refusing anything outside a trash
+func lookupTrashItem(id string) (xdgTrashDir, TrashItem, error) {
+	id = filepath.Clean(id)
+	for _, t := range knownTrashDirs() {
+		if filepath.Dir(id) != t.filesDir() {
+			continue
+		}
+		item, err := trashItemFor(t, filepath.Join(t.infoDir(), filepath.Base(id)+trashInfoExt))
+		return t, item, err
+	}
+	return xdgTrashDir{}, TrashItem{}, fmt.Errorf("not a trash item: %s", id)
+}
+
+// restoreTrashItem restores id to its original path, or into destDir when set
+func (fo *FileOperationsManager) restoreTrashItem(id, destDir string) error {
+	t, item, err := lookupTrashItem(id)
+	if err != nil {
+		return err
+	}
+
+	target := item.OriginalPath
+	if destDir != "" {
+		target = filepath.Join(destDir, item.Name)
+	}
+	if _, err := os.Lstat(target); err == nil {
+		return &os.PathError{Op: "restore", Path: target, Err: os.ErrExist}
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	if err := fo.moveOrCopy(item.ID, target); err != nil {
+		return err
+	}
+	os.Remove(filepath.Join(t.infoDir(), filepath.Base(item.ID)+trashInfoExt))
+
+	logPrintf("♻️ Restored %s", target)
+	return nil
+}
+
+// deleteTrashItem permanently deletes a trashed item and its metadata
+func (fo *FileOperationsManager) deleteTrashItem(id string) error {
+	t, item, err := lookupTrashItem(id)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(item.ID); err != nil {
+		return err
+	}
+	// The info file goes last so a failed delete stays listed
+	return os.Remove(filepath.Join(t.infoDir(), filepath.Base(item.ID)+trashInfoExt))
+}
@@ -0,0 +1,111 @@
+//go:build linux
+
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// homeTrashItems lists the trash and keeps the items of the home trash, leaving
+// out any per-volume trashes the machine happens to have
+func homeTrashItems(t *testing.T, fo *FileOperationsManager) []TrashItem {
+	t.Helper()
+	items, err := fo.ListTrash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var home []TrashItem
+	for _, item := range items {
+		if strings.HasPrefix(item.ID, homeTrash().filesDir()+string(filepath.Separator)) {
+			home = append(home, item)
+		}
+	}
+	return home
+}
+
+func TestTrashedDirectoryReportsCachedSize(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	dir := filepath.Join(t.TempDir(), "folder")
+	writeFile(t, filepath.Join(dir, "a"), strings.Repeat("a", 10000))
+	writeFile(t, filepath.Join(dir, "sub", "b"), strings.Repeat("b", 5000))
+
+	fo := &FileOperationsManager{}
+	if err := fo.moveToXDGTrash(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	items := homeTrashItems(t, fo)
+	if len(items) != 1 || !items[0].IsDir {
+		t.Fatalf("trash holds %+v, want the one folder", items)
+	}
+	if items[0].Size <= 0 {
+		t.Fatalf("trashed folder size = %d, want it measured", items[0].Size)
+	}
+
+	// Later listings come from directorysizes while the info file is unchanged
+	cache := filepath.Join(homeTrash().root, trashDirSizesFile)
+	content, err := os.ReadFile(cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := strings.Fields(string(content))
+	if len(fields) != 3 || fields[2] != filepath.Base(items[0].ID) {
+		t.Fatalf("directorysizes = %q", content)
+	}
+	fields[0] = "12345"
+	if err := os.WriteFile(cache, []byte(strings.Join(fields, " ")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if items := homeTrashItems(t, fo); len(items) != 1 || items[0].Size != 12345 {
+		t.Errorf("listing after caching = %+v, want the cached size 12345", items)
+	}
+
+	// Entries of items that left the trash are dropped
+	if err := fo.deleteTrashItem(items[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	homeTrashItems(t, fo)
+	if content, err := os.ReadFile(cache); err != nil || len(content) != 0 {
+		t.Errorf("directorysizes after delete = %q, %v; want it empty", content, err)
+	}
+}
+
+// Policy protects trashed items by where they came from; their IDs inside the
+// trash never match a rule
+func TestPermanentTrashDeletionChecksOriginalPaths(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	root := t.TempDir()
+	protected := filepath.Join(root, "protected")
+	writeFile(t, filepath.Join(protected, "keep.txt"), "keep")
+	writeFile(t, filepath.Join(root, "scratch.txt"), "scratch")
+
+	fo := &FileOperationsManager{}
+	for _, p := range []string{filepath.Join(protected, "keep.txt"), filepath.Join(root, "scratch.txt")} {
+		if err := fo.moveToXDGTrash(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ids := make(map[string]string)
+	for _, item := range homeTrashItems(t, fo) {
+		ids[item.Name] = item.ID
+	}
+
+	a := &App{fileOps: fo, policy: newTestPolicy(ProtectedPathRule{Path: protected, Action: PolicyDeny, IncludeChildren: true})}
+	if a.DeleteFromTrash([]string{ids["keep.txt"]}) {
+		t.Error("deleted an item trashed from a protected folder")
+	}
+	if a.EmptyTrash() {
+		t.Error("emptied a trash holding an item from a protected folder")
+	}
+	if !a.DeleteFromTrash([]string{ids["scratch.txt"]}) {
+		t.Error("refused to delete an unprotected item")
+	}
+
+	items := homeTrashItems(t, fo)
+	if len(items) != 1 || items[0].Name != "keep.txt" {
+		t.Errorf("trash holds %+v, want only keep.txt", items)
+	}
+}
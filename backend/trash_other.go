@@ -0,0 +1,12 @@
+//go:build !linux
+
+package backend
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func (fo *FileOperationsManager) moveToXDGTrash(filePath string) error {
+	return fmt.Errorf("freedesktop.org trash is not used on %s", runtime.GOOS)
+}
@@ -0,0 +1,22 @@
+//go:build !linux && !windows && !darwin
+
+package backend
+
+import (
+	"fmt"
+	"runtime"
+)
+
+var errTrashUnsupported = fmt.Errorf("trash management is not supported on %s", runtime.GOOS)
+
+func (fo *FileOperationsManager) listTrashItems() ([]TrashItem, error) {
+	return nil, errTrashUnsupported
+}
+
+func (fo *FileOperationsManager) restoreTrashItem(id, destDir string) error {
+	return errTrashUnsupported
+}
+
+func (fo *FileOperationsManager) deleteTrashItem(id string) error {
+	return errTrashUnsupported
+}
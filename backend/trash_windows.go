@@ -0,0 +1,167 @@
+//go:build windows
+
+package backend
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf16"
+)
+
+// The Recycle Bin keeps each deleted item as a pair in
+// <drive>\$Recycle.Bin\<user SID>\: $R<id> holds the data and $I<id> the
+// metadata (original path, size, deletion time).
+
+func recycleBinDirs() []string {
+	platform := &PlatformManager{}
+	sid, err := platform.GetCurrentUserSIDNative()
+	if err != nil {
+		logPrintf("Cannot resolve user SID for recycle bin: %v", err)
+		return nil
+	}
+
+	var dirs []string
+	for _, root := range platform.GetSystemRootsWindows() {
+		dir := filepath.Join(root, "$Recycle.Bin", sid)
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// parseRecycleInfo decodes a $I file (Vista format version 1 or Windows 10 version 2)
+func parseRecycleInfo(infoPath string) (original string, size int64, deleted time.Time, err error) {
+	data, err := os.ReadFile(infoPath)
+	if err != nil {
+		return "", 0, time.Time{}, err
+	}
+	if len(data) < 24 {
+		return "", 0, time.Time{}, fmt.Errorf("recycle bin record too short: %s", infoPath)
+	}
+
+	version := binary.LittleEndian.Uint64(data[0:8])
+	size = int64(binary.LittleEndian.Uint64(data[8:16]))
+	ft := syscall.Filetime{
+		LowDateTime:  binary.LittleEndian.Uint32(data[16:20]),
+		HighDateTime: binary.LittleEndian.Uint32(data[20:24]),
+	}
+	deleted = time.Unix(0, ft.Nanoseconds())
+
+	var raw []byte
+	switch version {
+	case 1:
+		raw = data[24:]
+	case 2:
+		if len(data) < 28 {
+			return "", 0, time.Time{}, fmt.Errorf("recycle bin record too short: %s", infoPath)
+		}
+		n := int(binary.LittleEndian.Uint32(data[24:28])) * 2
+		if 28+n > len(data) {
+			return "", 0, time.Time{}, fmt.Errorf("recycle bin record truncated: %s", infoPath)
+		}
+		raw = data[28 : 28+n]
+	default:
+		return "", 0, time.Time{}, fmt.Errorf("unknown recycle bin record version %d", version)
+	}
+
+	chars := make([]uint16, 0, len(raw)/2)
+	for i := 0; i+1 < len(raw); i += 2 {
+		c := binary.LittleEndian.Uint16(raw[i:])
+		if c == 0 {
+			break
+		}
+		chars = append(chars, c)
+	}
+	return string(utf16.Decode(chars)), size, deleted, nil
+}
+
+func (fo *FileOperationsManager) listTrashItems() ([]TrashItem, error) {
+	var items []TrashItem
+	for _, dir := range recycleBinDirs() {
+		infos, err := filepath.Glob(filepath.Join(dir, "$I*"))
+		if err != nil {
+			continue
+		}
+		for _, infoPath := range infos {
+			dataPath := filepath.Join(dir, "$R"+filepath.Base(infoPath)[2:])
+			info, err := os.Lstat(dataPath)
+			if err != nil {
+				continue
+			}
+			original, size, deleted, err := parseRecycleInfo(infoPath)
+			if err != nil {
+				continue
+			}
+			items = append(items, TrashItem{
+				ID:           dataPath,
+				Name:         filepath.Base(original),
+				OriginalPath: original,
+				DeletedAt:    deleted.Unix(),
+				Size:         size,
+				IsDir:        info.IsDir(),
+			})
+		}
+	}
+	return items, nil
+}
+
+// recycleItemPaths validates an ID from listTrashItems and returns its $R and $I paths
+func recycleItemPaths(id string) (dataPath, infoPath string, err error) {
+	id = filepath.Clean(id)
+	base := filepath.Base(id)
+	if !strings.HasPrefix(base, "$R") {
+		return "", "", fmt.Errorf("not a recycle bin item: %s", id)
+	}
+	for _, dir := range recycleBinDirs() {
+		if strings.EqualFold(filepath.Dir(id), dir) {
+			return id, filepath.Join(dir, "$I"+base[2:]), nil
+		}
+	}
+	return "", "", fmt.Errorf("not a recycle bin item: %s", id)
+}
+
+func (fo *FileOperationsManager) restoreTrashItem(id, destDir string) error {
+	dataPath, infoPath, err := recycleItemPaths(id)
+	if err != nil {
+		return err
+	}
+	original, _, _, err := parseRecycleInfo(infoPath)
+	if err != nil {
+		return err
+	}
+
+	target := original
+	if destDir != "" {
+		target = filepath.Join(destDir, filepath.Base(original))
+	}
+	if _, err := os.Lstat(target); err == nil {
+		return &os.PathError{Op: "restore", Path: target, Err: os.ErrExist}
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	if err := fo.moveOrCopy(dataPath, target); err != nil {
+		return err
+	}
+	os.Remove(infoPath)
+
+	logPrintf("♻️ Restored %s", target)
+	return nil
+}
+
+func (fo *FileOperationsManager) deleteTrashItem(id string) error {
+	dataPath, infoPath, err := recycleItemPaths(id)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dataPath); err != nil {
+		return err
+	}
+	return os.Remove(infoPath)
+}
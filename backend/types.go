@@ -31,9 +31,10 @@ type DirectoryContents struct {
 
 // NavigationResponse represents navigation result
 type NavigationResponse struct {
-	Success bool              `json:"success" msgpack:"success"`
-	Message string            `json:"message" msgpack:"message"`
-	Data    DirectoryContents `json:"data" msgpack:"data"`
+	Success   bool              `json:"success" msgpack:"success"`
+	Message   string            `json:"message" msgpack:"message"`
+	ErrorCode string            `json:"errorCode,omitempty" msgpack:"errorCode,omitempty"`
+	Data      DirectoryContents `json:"data" msgpack:"data"`
 }
 
 // DriveInfo represents information about a system drive
@@ -41,6 +42,233 @@ type DriveInfo struct {
 	Path   string `json:"path" msgpack:"path"`
 	Letter string `json:"letter" msgpack:"letter"`
 	Name   string `json:"name" msgpack:"name"`
+
+	// Mount details; filled in where the platform enumerates real mounts
+	Label          string `json:"label,omitempty" msgpack:"label,omitempty"`
+	FSType         string `json:"fsType,omitempty" msgpack:"fsType,omitempty"`
+	Device         string `json:"device,omitempty" msgpack:"device,omitempty"`
+	Removable      bool   `json:"removable,omitempty" msgpack:"removable,omitempty"`
+	ReadOnly       bool   `json:"readOnly,omitempty" msgpack:"readOnly,omitempty"`
+	TotalBytes     int64  `json:"totalBytes,omitempty" msgpack:"totalBytes,omitempty"`
+	FreeBytes      int64  `json:"freeBytes,omitempty" msgpack:"freeBytes,omitempty"`
+	AvailableBytes int64  `json:"availableBytes,omitempty" msgpack:"availableBytes,omitempty"`
+
+	// Set on entries for saved network locations
+	LocationID string `json:"locationId,omitempty" msgpack:"locationId,omitempty"`
+	Status     string `json:"status,omitempty" msgpack:"status,omitempty"` // see NetworkStatusConnected
+
+	// Where a quick access entry comes from; see QuickAccessSourceHome
+	Source string `json:"source,omitempty" msgpack:"source,omitempty"`
+}
+
+// Sources of quick access entries, in the order they are listed
+const (
+	QuickAccessSourceHome     = "home"
+	QuickAccessSourceUserDir  = "user-dir" // XDG user directories and their equivalents elsewhere
+	QuickAccessSourceBookmark = "bookmark" // GTK bookmarks
+	QuickAccessSourcePinned   = "pinned"   // Settings.PinnedFolders
+	QuickAccessSourceSystem   = "system"
+	QuickAccessSourceNetwork  = "network"
+)
+
+// VolumeStats describes the filesystem that holds a path
+type VolumeStats struct {
+	Path           string `json:"path" msgpack:"path"`
+	MountPoint     string `json:"mountPoint" msgpack:"mountPoint"`
+	FSType         string `json:"fsType" msgpack:"fsType"`
+	ReadOnly       bool   `json:"readOnly" msgpack:"readOnly"`
+	TotalBytes     int64  `json:"totalBytes" msgpack:"totalBytes"`
+	FreeBytes      int64  `json:"freeBytes" msgpack:"freeBytes"`
+	AvailableBytes int64  `json:"availableBytes" msgpack:"availableBytes"` // free space usable without privileges
+}
+
+// Reasons reported by EjectResult when a volume could not be ejected
+const (
+	EjectReasonBusy         = "busy"
+	EjectReasonPermission   = "permission"
+	EjectReasonNotMounted   = "not-mounted"
+	EjectReasonNotSupported = "not-supported"
+	EjectReasonFailed       = "failed"
+)
+
+// EjectResult is the outcome of unmounting and optionally powering off a volume.
+// When the volume is busy, Holders lists the processes keeping it open.
+type EjectResult struct {
+	Success    bool          `json:"success" msgpack:"success"`
+	Path       string        `json:"path" msgpack:"path"`
+	Method     string        `json:"method,omitempty" msgpack:"method,omitempty"` // "umount2", "udisks2" or "windows"
+	PoweredOff bool          `json:"poweredOff" msgpack:"poweredOff"`
+	Reason     string        `json:"reason,omitempty" msgpack:"reason,omitempty"`
+	Message    string        `json:"message,omitempty" msgpack:"message,omitempty"`
+	Holders    []MountHolder `json:"holders,omitempty" msgpack:"holders,omitempty"`
+}
+
+// MountHolder is a process with open files, or its working directory, on a mount
+type MountHolder struct {
+	PID     int      `json:"pid" msgpack:"pid"`
+	Command string   `json:"command" msgpack:"command"`
+	Paths   []string `json:"paths" msgpack:"paths"`
+}
+
+// BlockVolume is a block device holding a filesystem that is not mounted
+type BlockVolume struct {
+	Device    string `json:"device" msgpack:"device"`
+	Name      string `json:"name" msgpack:"name"`
+	Label     string `json:"label,omitempty" msgpack:"label,omitempty"`
+	FSType    string `json:"fsType" msgpack:"fsType"`
+	UUID      string `json:"uuid,omitempty" msgpack:"uuid,omitempty"`
+	Size      int64  `json:"size" msgpack:"size"`
+	Removable bool   `json:"removable" msgpack:"removable"`
+	System    bool   `json:"system" msgpack:"system"` // internal disk that needs administrator rights to mount
+}
+
+// MountResult is the outcome of MountVolume. An image with a partition table
+// may yield several mount points.
+type MountResult struct {
+	Success     bool     `json:"success" msgpack:"success"`
+	Source      string   `json:"source" msgpack:"source"`
+	LoopDevice  string   `json:"loopDevice,omitempty" msgpack:"loopDevice,omitempty"`
+	MountPoints []string `json:"mountPoints" msgpack:"mountPoints"`
+	Message     string   `json:"message,omitempty" msgpack:"message,omitempty"`
+}
+
+// DriveProperties is the data behind a drive properties dialog. Hardware
+// fields are empty where the platform does not expose them.
+type DriveProperties struct {
+	Path           string   `json:"path" msgpack:"path"`
+	MountPoint     string   `json:"mountPoint" msgpack:"mountPoint"`
+	Device         string   `json:"device,omitempty" msgpack:"device,omitempty"`
+	Label          string   `json:"label" msgpack:"label"`
+	UUID           string   `json:"uuid,omitempty" msgpack:"uuid,omitempty"`
+	FSType         string   `json:"fsType" msgpack:"fsType"`
+	MountOptions   []string `json:"mountOptions" msgpack:"mountOptions"`
+	TotalBytes     int64    `json:"totalBytes" msgpack:"totalBytes"`
+	UsedBytes      int64    `json:"usedBytes" msgpack:"usedBytes"`
+	FreeBytes      int64    `json:"freeBytes" msgpack:"freeBytes"`
+	AvailableBytes int64    `json:"availableBytes" msgpack:"availableBytes"`
+	ReadOnly       bool     `json:"readOnly" msgpack:"readOnly"`
+	Removable      bool     `json:"removable" msgpack:"removable"`
+	Vendor         string   `json:"vendor,omitempty" msgpack:"vendor,omitempty"`
+	Model          string   `json:"model,omitempty" msgpack:"model,omitempty"`
+	Serial         string   `json:"serial,omitempty" msgpack:"serial,omitempty"`
+	CanSetLabel    bool     `json:"canSetLabel" msgpack:"canSetLabel"`
+}
+
+// NavigateRequest asks the frontend to navigate to a path on behalf of an
+// external caller (automation API, command line, ...)
+type NavigateRequest struct {
+	Path      string `json:"path" msgpack:"path"`
+	Select    string `json:"select,omitempty" msgpack:"select,omitempty"`
+	NewWindow bool   `json:"newWindow,omitempty" msgpack:"newWindow,omitempty"`
+	Source    string `json:"source" msgpack:"source"`
+}
+
+// RecycleResult reports the outcome of a safe delete. When TrashUnavailable is
+// set, the Failed paths are still in place and ConfirmRequestID can be passed to
+// ConfirmPermanentDelete to delete them permanently instead.
+type RecycleResult struct {
+	Success          bool     `json:"success" msgpack:"success"`
+	Trashed          []string `json:"trashed" msgpack:"trashed"`
+	Failed           []string `json:"failed" msgpack:"failed"`
+	TrashUnavailable bool     `json:"trashUnavailable" msgpack:"trashUnavailable"`
+	Message          string   `json:"message,omitempty" msgpack:"message,omitempty"`
+	ConfirmRequestID string   `json:"confirmRequestId,omitempty" msgpack:"confirmRequestId,omitempty"`
+}
+
+// PermanentDeleteRequest asks the user to confirm deleting paths that could not be trashed
+type PermanentDeleteRequest struct {
+	RequestID string   `json:"requestId" msgpack:"requestId"`
+	Paths     []string `json:"paths" msgpack:"paths"`
+	Reason    string   `json:"reason" msgpack:"reason"`
+	ExpiresAt int64    `json:"expiresAt" msgpack:"expiresAt"`
+}
+
+// CopyOptions controls which metadata copy and move operations carry over
+// beyond file contents, permissions and modification time. They apply to the
+// Unix copy path; the Windows shell copy keeps its own semantics.
+type CopyOptions struct {
+	PreserveTimes     bool `json:"preserveTimes" msgpack:"preserveTimes"`         // access times and directory mtimes
+	PreserveOwnership bool `json:"preserveOwnership" msgpack:"preserveOwnership"` // owner and group, where permitted
+	PreserveXattrs    bool `json:"preserveXattrs" msgpack:"preserveXattrs"`
+	PreserveACLs      bool `json:"preserveAcls" msgpack:"preserveAcls"`
+	PreserveSymlinks  bool `json:"preserveSymlinks" msgpack:"preserveSymlinks"`   // copy links as links instead of their targets
+	PreserveHardlinks bool `json:"preserveHardlinks" msgpack:"preserveHardlinks"` // relink files that share an inode within the set
+	PreserveSparse    bool `json:"preserveSparse" msgpack:"preserveSparse"`       // keep holes in sparse files
+
+	// Strategy forces how file contents are copied (see CopyStrategyAuto); empty means auto
+	Strategy string `json:"strategy,omitempty" msgpack:"strategy,omitempty"`
+	// SkipSpaceCheck starts copy jobs without first checking the destination has room
+	SkipSpaceCheck bool `json:"skipSpaceCheck,omitempty" msgpack:"skipSpaceCheck,omitempty"`
+	// Stats, when set, records which strategies did the copying
+	Stats *CopyStats `json:"-" msgpack:"-"`
+	// Progress, when set, is told the bytes copied as each chunk of a file lands
+	Progress func(bytes int64) `json:"-" msgpack:"-"`
+}
+
+// ExtendedAttribute is a named extended attribute. Values that are not
+// printable UTF-8 are base64-encoded and have Encoding set to "base64".
+type ExtendedAttribute struct {
+	Name     string `json:"name" msgpack:"name"`
+	Value    string `json:"value" msgpack:"value"`
+	Encoding string `json:"encoding,omitempty" msgpack:"encoding,omitempty"`
+}
+
+// ACLEntry is one POSIX ACL entry in getfacl terms: Tag is "user", "group",
+// "mask" or "other", Qualifier names the user or group (empty for the owner
+// entries) and Perms is an "rwx"-style string
+type ACLEntry struct {
+	Tag       string `json:"tag" msgpack:"tag"`
+	Qualifier string `json:"qualifier,omitempty" msgpack:"qualifier,omitempty"`
+	Perms     string `json:"perms" msgpack:"perms"`
+}
+
+// ACL is the access ACL of a path and, for directories, the default ACL new
+// children inherit. Extended is false when the ACL only mirrors the mode bits.
+type ACL struct {
+	Access   []ACLEntry `json:"access" msgpack:"access"`
+	Default  []ACLEntry `json:"default" msgpack:"default"`
+	Extended bool       `json:"extended" msgpack:"extended"`
+}
+
+// AttributeChanges describes edits made by SetAttributes; empty fields are left
+// alone. Mode accepts octal ("755") or symbolic ("u+x,go-w") input and, like
+// Owner and Group, applies to Unix only. The flag fields apply to Windows only.
+type AttributeChanges struct {
+	Mode       string `json:"mode,omitempty" msgpack:"mode,omitempty"`
+	Owner      string `json:"owner,omitempty" msgpack:"owner,omitempty"`
+	Group      string `json:"group,omitempty" msgpack:"group,omitempty"`
+	ModTime    int64  `json:"modTime,omitempty" msgpack:"modTime,omitempty"`
+	AccessTime int64  `json:"accessTime,omitempty" msgpack:"accessTime,omitempty"`
+	Touch      bool   `json:"touch,omitempty" msgpack:"touch,omitempty"`
+	ReadOnly   *bool  `json:"readOnly,omitempty" msgpack:"readOnly,omitempty"`
+	Hidden     *bool  `json:"hidden,omitempty" msgpack:"hidden,omitempty"`
+	System     *bool  `json:"system,omitempty" msgpack:"system,omitempty"`
+	Archive    *bool  `json:"archive,omitempty" msgpack:"archive,omitempty"`
+}
+
+// AttributeFailure records a path SetAttributes could not update
+type AttributeFailure struct {
+	Path  string `json:"path" msgpack:"path"`
+	Error string `json:"error" msgpack:"error"`
+}
+
+// AttributeResult reports the outcome of SetAttributes
+type AttributeResult struct {
+	Success bool               `json:"success" msgpack:"success"`
+	Updated int                `json:"updated" msgpack:"updated"`
+	Failed  []AttributeFailure `json:"failed" msgpack:"failed"`
+	Message string             `json:"message,omitempty" msgpack:"message,omitempty"`
+}
+
+// TrashItem represents a file or folder in the trash. ID identifies it for
+// restore and purge operations.
+type TrashItem struct {
+	ID           string `json:"id" msgpack:"id"`
+	Name         string `json:"name" msgpack:"name"`
+	OriginalPath string `json:"originalPath" msgpack:"originalPath"`
+	DeletedAt    int64  `json:"deletedAt" msgpack:"deletedAt"`
+	Size         int64  `json:"size" msgpack:"size"` // -1 for a directory that could not be measured
+	IsDir        bool   `json:"isDir" msgpack:"isDir"`
 }
 
 // WarmState represents cached warm-start data sent to the frontend.
@@ -56,6 +284,77 @@ type Settings struct {
 	Theme             string   `json:"theme" msgpack:"theme"`
 	ShowHiddenFiles   bool     `json:"showHiddenFiles" msgpack:"showHiddenFiles"`
 	PinnedFolders     []string `json:"pinnedFolders,omitempty" msgpack:"pinnedFolders"`
+
+	// PinDetails holds the optional presentation of pinned folders, keyed by
+	// their path in PinnedFolders, which alone decides what is pinned and in what order
+	PinDetails map[string]PinDetails `json:"pinDetails,omitempty" msgpack:"pinDetails"`
+
+	S3Connections    []S3Connection    `json:"s3Connections,omitempty" msgpack:"s3Connections"`
+	NetworkLocations []NetworkLocation `json:"networkLocations,omitempty" msgpack:"networkLocations"`
+
+	AutomationAPIEnabled bool `json:"automationApiEnabled" msgpack:"automationApiEnabled"`
+	AutomationAPIPort    int  `json:"automationApiPort,omitempty" msgpack:"automationApiPort"`
+
+	ProtectedPaths []ProtectedPathRule `json:"protectedPaths,omitempty" msgpack:"protectedPaths"`
+}
+
+// PinDetails is how a pinned folder is shown in the sidebar. An empty Name
+// shows the folder's own name; Icon names an icon of the frontend's icon set.
+type PinDetails struct {
+	Name  string `json:"name,omitempty" msgpack:"name,omitempty"`
+	Icon  string `json:"icon,omitempty" msgpack:"icon,omitempty"`
+	Color string `json:"color,omitempty" msgpack:"color,omitempty"`
+	Group string `json:"group,omitempty" msgpack:"group,omitempty"`
+}
+
+// PinnedFolder is a pinned folder with its details. Missing is set when the
+// path no longer exists or is no longer a folder.
+type PinnedFolder struct {
+	Path string `json:"path" msgpack:"path"`
+	PinDetails
+	Missing bool `json:"missing,omitempty" msgpack:"missing,omitempty"`
+}
+
+// ProtectedPathRule guards a path against mutating operations. Action is
+// "deny" or "confirm"; IncludeChildren extends the rule to everything inside.
+type ProtectedPathRule struct {
+	Path            string `json:"path" msgpack:"path"`
+	Action          string `json:"action" msgpack:"action"`
+	IncludeChildren bool   `json:"includeChildren" msgpack:"includeChildren"`
+	Builtin         bool   `json:"builtin,omitempty" msgpack:"builtin,omitempty"`
+}
+
+// S3Connection describes an S3-compatible endpoint (AWS, MinIO, ...).
+// Secrets are never stored here; they come from the named credentials profile.
+type S3Connection struct {
+	ID        string `json:"id" msgpack:"id"`
+	Name      string `json:"name" msgpack:"name"`
+	Endpoint  string `json:"endpoint" msgpack:"endpoint"`
+	Region    string `json:"region" msgpack:"region"`
+	PathStyle bool   `json:"pathStyle" msgpack:"pathStyle"`
+	Profile   string `json:"profile" msgpack:"profile"`
+}
+
+// NetworkLocation is a saved SMB, NFS or SFTP share. Passwords are never
+// stored; they are entered when connecting or come from the OS credential store.
+type NetworkLocation struct {
+	ID       string `json:"id" msgpack:"id"`
+	Name     string `json:"name" msgpack:"name"`
+	Protocol string `json:"protocol" msgpack:"protocol"` // "smb", "nfs" or "sftp"
+	Host     string `json:"host" msgpack:"host"`
+	Port     int    `json:"port,omitempty" msgpack:"port,omitempty"`
+	Share    string `json:"share" msgpack:"share"` // SMB share, NFS export or SFTP directory
+	Username string `json:"username,omitempty" msgpack:"username,omitempty"`
+	Domain   string `json:"domain,omitempty" msgpack:"domain,omitempty"` // SMB workgroup or domain
+}
+
+// NetworkLocationStatus is a saved location with its current connection state
+type NetworkLocationStatus struct {
+	NetworkLocation
+	URI        string `json:"uri" msgpack:"uri"`
+	Status     string `json:"status" msgpack:"status"`
+	MountPoint string `json:"mountPoint,omitempty" msgpack:"mountPoint,omitempty"`
+	Error      string `json:"error,omitempty" msgpack:"error,omitempty"`
 }
 
 // FileSystemManagerInterface defines the file system operations contract
@@ -70,17 +369,29 @@ type FileSystemManagerInterface interface {
 	FileExists(path string) bool
 	StreamDirectory(dir string)
 	SetShowHidden(includeHidden bool)
+	SearchFiles(root, query string, limit int) ([]FileInfo, error)
 }
 
 // FileOperationsManagerInterface defines file operations contract
 type FileOperationsManagerInterface interface {
 	CopyFiles(sourcePaths []string, destDir string) bool
 	MoveFiles(sourcePaths []string, destDir string) bool
+	CopyFilesWithOptions(ctx context.Context, sourcePaths []string, destDir string, opts CopyOptions) bool
+	MoveFilesWithOptions(ctx context.Context, sourcePaths []string, destDir string, opts CopyOptions) bool
 	DeleteFiles(filePaths []string) bool
 	MoveFilesToRecycleBin(filePaths []string) bool
+	RecycleFiles(filePaths []string) RecycleResult
 	RenameFile(oldPath, newName string) bool
 	HideFiles(filePaths []string) bool
+	UnhideFiles(filePaths []string) bool
+	SetAttributes(paths []string, changes AttributeChanges, recursive bool) AttributeResult
 	OpenFile(filePath string) bool
+	ListTrash() ([]TrashItem, error)
+	RestoreFromTrash(ids []string) error
+	RestoreFromTrashTo(ids []string, destDir string) error
+	DeleteFromTrash(ids []string) error
+	EmptyTrash() error
+	PurgeTrashOlderThan(age time.Duration) (int, error)
 }
 
 // PlatformManagerInterface defines OS-specific operations contract
@@ -93,6 +404,7 @@ type PlatformManagerInterface interface {
 	IsHidden(filePath string) bool
 	GetExtension(name string) string
 	HideFile(filePath string) bool
+	UnhideFile(filePath string) bool
 	OpenFile(filePath string) bool
 	FormatFileSize(size int64) string
 	SetClipboardFilePaths(paths []string) bool
@@ -102,8 +414,8 @@ type PlatformManagerInterface interface {
 
 // DriveManagerInterface defines drive management contract
 type DriveManagerInterface interface {
-	GetDriveInfo() []DriveInfo
-	GetQuickAccessPaths() []DriveInfo
+	GetDriveInfo(ctx context.Context) []DriveInfo
+	GetQuickAccessPaths(ctx context.Context) []DriveInfo
 	InvalidateCaches()
 }
 
@@ -123,6 +435,13 @@ type App struct {
 	platform   PlatformManagerInterface
 	drives     DriveManagerInterface
 	terminal   TerminalManagerInterface
+	jobs       *JobManager
+	s3         *S3Manager
+	shares     *ShareManager
+	automation *AutomationServer
+	policy     *PathPolicy
+	renamer    *BatchRenamer
+	network    *NetworkManager
 
 	drivesOnce   sync.Once
 	terminalOnce sync.Once
@@ -132,8 +451,16 @@ type App struct {
 	warmReady    bool
 	warmOnce     sync.Once
 
-	settings     Settings
-	settingsOnce sync.Once
+	settings      Settings
+	settingsStamp settingsStamp
+	settingsOnce  sync.Once
+	settingsMu    sync.Mutex
+
+	launchRequest *NavigateRequest
+	launchMu      sync.Mutex
+
+	pendingDeletes   map[string]PermanentDeleteRequest
+	pendingDeletesMu sync.Mutex
 }
 
 // FileSystemManager implementation
@@ -149,6 +476,7 @@ type FileSystemManager struct {
 // FileOperationsManager implementation
 type FileOperationsManager struct {
 	platform PlatformManagerInterface
+	policy   *PathPolicy
 }
 
 // PlatformManager implementation
@@ -0,0 +1,173 @@
+//go:build linux
+
+package backend
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	udisksService     = "org.freedesktop.UDisks2"
+	udisksRoot        = "/org/freedesktop/UDisks2"
+	udisksBlock       = "org.freedesktop.UDisks2.Block"
+	udisksFilesystem  = "org.freedesktop.UDisks2.Filesystem"
+	udisksDrive       = "org.freedesktop.UDisks2.Drive"
+	udisksPartition   = "org.freedesktop.UDisks2.Partition"
+	udisksLoop        = "org.freedesktop.UDisks2.Loop"
+	udisksManager     = "org.freedesktop.UDisks2.Manager"
+	udisksManagerPath = "/org/freedesktop/UDisks2/Manager"
+	udisksCallTimeout = 30 * time.Second
+)
+
+type udisksObjects map[dbus.ObjectPath]map[string]map[string]dbus.Variant
+
+// udisksClient is a short-lived connection to the UDisks2 daemon on the system
+// bus with a snapshot of the objects it manages. UDisks asks polkit on the
+// caller's behalf, so unprivileged users can unmount their removable media.
+type udisksClient struct {
+	conn    *dbus.Conn
+	objects udisksObjects
+}
+
+func connectUDisks() (*udisksClient, error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return nil, err
+	}
+	c := &udisksClient{conn: conn}
+	if err := c.refresh(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *udisksClient) Close() {
+	c.conn.Close()
+}
+
+func (c *udisksClient) refresh() error {
+	var objects udisksObjects
+	err := c.conn.Object(udisksService, udisksRoot).
+		Call("org.freedesktop.DBus.ObjectManager.GetManagedObjects", 0).Store(&objects)
+	if err != nil {
+		return err
+	}
+	c.objects = objects
+	return nil
+}
+
+// call invokes a UDisks method with an empty options dictionary appended
+func (c *udisksClient) call(path dbus.ObjectPath, method string, args ...interface{}) *dbus.Call {
+	return c.callWithOptions(path, method, map[string]dbus.Variant{}, args...)
+}
+
+// callWithOptions invokes a UDisks method; options is the trailing a{sv} every method takes
+func (c *udisksClient) callWithOptions(path dbus.ObjectPath, method string, options map[string]dbus.Variant, args ...interface{}) *dbus.Call {
+	ctx, cancel := context.WithTimeout(context.Background(), udisksCallTimeout)
+	defer cancel()
+	args = append(args, options)
+	return c.conn.Object(udisksService, path).CallWithContext(ctx, method, 0, args...)
+}
+
+// prop returns a cached property of a managed object
+func (c *udisksClient) prop(path dbus.ObjectPath, iface, name string) (interface{}, bool) {
+	v, ok := c.objects[path][iface][name]
+	if !ok {
+		return nil, false
+	}
+	return v.Value(), true
+}
+
+func (c *udisksClient) stringProp(path dbus.ObjectPath, iface, name string) string {
+	v, _ := c.prop(path, iface, name)
+	s, _ := v.(string)
+	return s
+}
+
+func (c *udisksClient) boolProp(path dbus.ObjectPath, iface, name string) bool {
+	v, _ := c.prop(path, iface, name)
+	b, _ := v.(bool)
+	return b
+}
+
+// bytesProp decodes the NUL-terminated byte strings UDisks uses for paths
+func (c *udisksClient) bytesProp(path dbus.ObjectPath, iface, name string) string {
+	v, _ := c.prop(path, iface, name)
+	b, _ := v.([]byte)
+	return strings.TrimRight(string(b), "\x00")
+}
+
+func (c *udisksClient) uint64Prop(path dbus.ObjectPath, iface, name string) uint64 {
+	v, _ := c.prop(path, iface, name)
+	n, _ := v.(uint64)
+	return n
+}
+
+// mountPoints returns where a filesystem object is mounted
+func (c *udisksClient) mountPoints(path dbus.ObjectPath) []string {
+	v, _ := c.prop(path, udisksFilesystem, "MountPoints")
+	raw, _ := v.([][]byte)
+	points := make([]string, 0, len(raw))
+	for _, p := range raw {
+		points = append(points, strings.TrimRight(string(p), "\x00"))
+	}
+	return points
+}
+
+// has reports whether a managed object implements iface
+func (c *udisksClient) has(path dbus.ObjectPath, iface string) bool {
+	_, ok := c.objects[path][iface]
+	return ok
+}
+
+// blockForDevice finds the block object of a device node such as /dev/sdb1,
+// following symlinks like /dev/mapper/* or /dev/disk/by-label/*
+func (c *udisksClient) blockForDevice(device string) (dbus.ObjectPath, bool) {
+	if resolved, err := filepath.EvalSymlinks(device); err == nil {
+		device = resolved
+	}
+	for path, ifaces := range c.objects {
+		if _, ok := ifaces[udisksBlock]; !ok {
+			continue
+		}
+		if c.bytesProp(path, udisksBlock, "Device") == device {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// driveOf returns the drive object a block device belongs to
+func (c *udisksClient) driveOf(block dbus.ObjectPath) (dbus.ObjectPath, bool) {
+	v, _ := c.prop(block, udisksBlock, "Drive")
+	drive, ok := v.(dbus.ObjectPath)
+	if !ok || drive == "/" {
+		return "", false
+	}
+	return drive, true
+}
+
+// udisksReason maps a UDisks D-Bus error to an eject reason
+func udisksReason(err error) string {
+	var dbusErr dbus.Error
+	if !errors.As(err, &dbusErr) {
+		return EjectReasonFailed
+	}
+	switch {
+	case dbusErr.Name == "org.freedesktop.UDisks2.Error.DeviceBusy":
+		return EjectReasonBusy
+	case dbusErr.Name == "org.freedesktop.UDisks2.Error.NotMounted":
+		return EjectReasonNotMounted
+	case strings.HasPrefix(dbusErr.Name, "org.freedesktop.UDisks2.Error.NotAuthorized"),
+		dbusErr.Name == "org.freedesktop.DBus.Error.AccessDenied":
+		return EjectReasonPermission
+	}
+	return EjectReasonFailed
+}
@@ -0,0 +1,105 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// GetVolumeStats reports capacity, free space and type of the filesystem
+// holding path. path need not be a mount point. A filesystem that does not
+// answer within pathCallTimeout fails with a PathTimeoutError.
+func GetVolumeStats(ctx context.Context, path string) (VolumeStats, error) {
+	if path == "" {
+		return VolumeStats{}, fmt.Errorf("no path given")
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return VolumeStats{}, err
+	}
+	return withPathDeadline(ctx, abs, func() (VolumeStats, error) { return volumeStats(abs) })
+}
+
+// errInsufficientSpace reports that a transfer does not fit on its destination
+type errInsufficientSpace struct {
+	dest      string
+	required  int64
+	available int64
+}
+
+func (e *errInsufficientSpace) Error() string {
+	return fmt.Sprintf("not enough space on %s: %d bytes needed, %d bytes available", e.dest, e.required, e.available)
+}
+
+// checkDestinationSpace fails when the sources would not fit in the free space
+// of destDir. Moves within one filesystem are renames and need no space. When
+// the destination cannot be queried the check is skipped and the copy itself
+// reports any shortage.
+func checkDestinationSpace(ctx context.Context, sourcePaths []string, destDir string, move bool) error {
+	dest, err := GetVolumeStats(ctx, destDir)
+	if err != nil {
+		return nil
+	}
+
+	var required int64
+	for _, src := range sourcePaths {
+		if move {
+			if srcStats, err := GetVolumeStats(ctx, src); err == nil && srcStats.MountPoint == dest.MountPoint {
+				continue
+			}
+		}
+		size, err := diskUsage(ctx, src)
+		if err != nil {
+			return err
+		}
+		required += size
+	}
+
+	if required > dest.AvailableBytes {
+		return &errInsufficientSpace{dest: dest.MountPoint, required: required, available: dest.AvailableBytes}
+	}
+	return nil
+}
+
+// transferSize sums the apparent sizes of the regular files under path, the
+// bytes a copy reads. Unreadable entries are skipped; they will fail the copy
+// on their own.
+func transferSize(ctx context.Context, path string) (int64, error) {
+	return sumFileSizes(ctx, path, os.FileInfo.Size)
+}
+
+// diskUsage sums the space allocated to the regular files under path, like du.
+// Small files take whole blocks and sparse files less than their size; where
+// the allocation is unknown the apparent size stands in.
+func diskUsage(ctx context.Context, path string) (int64, error) {
+	return sumFileSizes(ctx, path, func(info os.FileInfo) int64 {
+		if allocated, ok := allocatedSize(info); ok {
+			return allocated
+		}
+		return info.Size()
+	})
+}
+
+func sumFileSizes(ctx context.Context, path string, size func(os.FileInfo) int64) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += size(info)
+			}
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	return total, err
+}
@@ -0,0 +1,22 @@
+//go:build darwin || freebsd
+
+package backend
+
+import "golang.org/x/sys/unix"
+
+func volumeStats(path string) (VolumeStats, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return VolumeStats{Path: path}, err
+	}
+	blockSize := int64(st.Bsize)
+	return VolumeStats{
+		Path:           path,
+		MountPoint:     unix.ByteSliceToString(st.Mntonname[:]),
+		FSType:         unix.ByteSliceToString(st.Fstypename[:]),
+		ReadOnly:       st.Flags&unix.MNT_RDONLY != 0,
+		TotalBytes:     int64(st.Blocks) * blockSize,
+		FreeBytes:      int64(st.Bfree) * blockSize,
+		AvailableBytes: int64(st.Bavail) * blockSize,
+	}, nil
+}
@@ -0,0 +1,44 @@
+//go:build linux
+
+package backend
+
+import (
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+func volumeStats(path string) (VolumeStats, error) {
+	stats := VolumeStats{Path: path}
+	if err := statVolume(path, &stats); err != nil {
+		return stats, err
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		resolved = path
+	}
+	if entries, err := readMountInfo(); err == nil {
+		if m, ok := mountForPath(entries, resolved); ok {
+			stats.MountPoint = m.MountPoint
+			stats.FSType = m.FSType
+		}
+	}
+	return stats, nil
+}
+
+// statVolume fills the capacity and readonly fields of stats from statfs
+func statVolume(path string, stats *VolumeStats) error {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return err
+	}
+	blockSize := int64(st.Frsize)
+	if blockSize == 0 {
+		blockSize = int64(st.Bsize)
+	}
+	stats.TotalBytes = int64(st.Blocks) * blockSize
+	stats.FreeBytes = int64(st.Bfree) * blockSize
+	stats.AvailableBytes = int64(st.Bavail) * blockSize
+	stats.ReadOnly = st.Flags&unix.ST_RDONLY != 0
+	return nil
+}
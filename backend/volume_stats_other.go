@@ -0,0 +1,12 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package backend
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func volumeStats(path string) (VolumeStats, error) {
+	return VolumeStats{Path: path}, fmt.Errorf("volume statistics are not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,62 @@
+//go:build !windows
+
+package backend
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiskUsageCountsAllocatedBlocks(t *testing.T) {
+	dir := t.TempDir()
+	sparse := filepath.Join(dir, "sparse")
+	f, err := os.Create(sparse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("data")); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Truncate(64 << 20); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	info, err := os.Stat(sparse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isSparseFile(sparse, info) {
+		t.Skip("the temp filesystem does not support sparse files")
+	}
+
+	apparent, err := transferSize(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	used, err := diskUsage(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allocated, _ := fileAllocatedSize(sparse)
+	if apparent != 64<<20 {
+		t.Errorf("transferSize = %d, want the apparent size %d", apparent, 64<<20)
+	}
+	if used != allocated || used >= apparent {
+		t.Errorf("diskUsage = %d, want the %d allocated bytes", used, allocated)
+	}
+}
+
+func TestGetVolumeStatsFailsFastOnUnresponsivePath(t *testing.T) {
+	dir := t.TempDir()
+	unavailablePaths.mark(dir)
+	t.Cleanup(unavailablePaths.reset)
+
+	if _, err := GetVolumeStats(context.Background(), filepath.Join(dir, "sub")); errorCode(err) != DirectoryErrorTimeout {
+		t.Errorf("GetVolumeStats on a backed-off path = %v, want a timeout error", err)
+	}
+	if err := checkDestinationSpace(context.Background(), []string{dir}, dir, false); err != nil {
+		t.Errorf("checkDestinationSpace = %v, want the check skipped", err)
+	}
+}
@@ -0,0 +1,35 @@
+//go:build windows
+
+package backend
+
+import "golang.org/x/sys/windows"
+
+func volumeStats(path string) (VolumeStats, error) {
+	stats := VolumeStats{Path: path}
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return stats, err
+	}
+
+	var available, total, free uint64
+	if err := windows.GetDiskFreeSpaceEx(pathPtr, &available, &total, &free); err != nil {
+		return stats, err
+	}
+	stats.TotalBytes = int64(total)
+	stats.FreeBytes = int64(free)
+	stats.AvailableBytes = int64(available)
+
+	root := make([]uint16, windows.MAX_PATH+1)
+	if err := windows.GetVolumePathName(pathPtr, &root[0], uint32(len(root))); err != nil {
+		return stats, nil
+	}
+	stats.MountPoint = windows.UTF16ToString(root)
+
+	var flags uint32
+	fsName := make([]uint16, windows.MAX_PATH+1)
+	if err := windows.GetVolumeInformation(&root[0], nil, 0, nil, nil, &flags, &fsName[0], uint32(len(fsName))); err == nil {
+		stats.FSType = windows.UTF16ToString(fsName)
+		stats.ReadOnly = flags&windows.FILE_READ_ONLY_VOLUME != 0
+	}
+	return stats, nil
+}
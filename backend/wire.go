@@ -9,6 +9,11 @@ type WireEntry struct {
 	S int64  `msgpack:"s,omitempty"`
 	M int64  `msgpack:"m"`
 	H bool   `msgpack:"h,omitempty"`
+
+	// Trash view only: item ID, original path and deletion time
+	I string `msgpack:"i,omitempty"`
+	O string `msgpack:"o,omitempty"`
+	X int64  `msgpack:"x,omitempty"`
 }
 
 func toWireEntries(in []FileInfo) []WireEntry {
@@ -0,0 +1,352 @@
+package backend
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"os/user"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Extended attributes holding POSIX ACLs on Linux
+const (
+	xattrACLAccess  = "system.posix_acl_access"
+	xattrACLDefault = "system.posix_acl_default"
+	xattrACLPrefix  = "system.posix_acl_"
+)
+
+// POSIX ACL xattr layout (linux/posix_acl_xattr.h)
+const (
+	aclXattrVersion = 2
+	aclUndefinedID  = 0xFFFFFFFF
+
+	aclTagUserObj  = 0x01
+	aclTagUser     = 0x02
+	aclTagGroupObj = 0x04
+	aclTagGroup    = 0x08
+	aclTagMask     = 0x10
+	aclTagOther    = 0x20
+)
+
+// GetExtendedAttributes lists the extended attributes of path without following symlinks
+func GetExtendedAttributes(path string) ([]ExtendedAttribute, error) {
+	names, err := listXattrs(path)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	attrs := make([]ExtendedAttribute, 0, len(names))
+	for _, name := range names {
+		value, err := getXattr(path, name)
+		if err != nil {
+			if isNoXattr(err) {
+				continue
+			}
+			return nil, fmt.Errorf("read %s: %w", name, err)
+		}
+		attrs = append(attrs, encodeXattrValue(name, value))
+	}
+	return attrs, nil
+}
+
+// SetExtendedAttribute creates or replaces an extended attribute
+func SetExtendedAttribute(path string, attr ExtendedAttribute) error {
+	if attr.Name == "" {
+		return fmt.Errorf("attribute name cannot be empty")
+	}
+	value, err := decodeXattrValue(attr)
+	if err != nil {
+		return err
+	}
+	return setXattr(path, attr.Name, value)
+}
+
+// RemoveExtendedAttribute deletes an extended attribute
+func RemoveExtendedAttribute(path, name string) error {
+	if name == "" {
+		return fmt.Errorf("attribute name cannot be empty")
+	}
+	return removeXattr(path, name)
+}
+
+// GetACL returns the POSIX ACL of path. Without an ACL xattr, the access ACL
+// is derived from the mode bits as getfacl does.
+func GetACL(path string) (ACL, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return ACL{}, err
+	}
+	acl := ACL{Access: aclFromMode(info.Mode()), Default: []ACLEntry{}}
+
+	if data, err := getXattr(path, xattrACLAccess); err == nil {
+		entries, err := parsePosixACL(data)
+		if err != nil {
+			return ACL{}, err
+		}
+		acl.Access, acl.Extended = entries, true
+	} else if !isNoXattr(err) && !isXattrUnsupported(err) {
+		return ACL{}, err
+	}
+
+	if info.IsDir() {
+		if data, err := getXattr(path, xattrACLDefault); err == nil {
+			entries, err := parsePosixACL(data)
+			if err != nil {
+				return ACL{}, err
+			}
+			acl.Default, acl.Extended = entries, true
+		}
+	}
+	return acl, nil
+}
+
+// SetACL replaces the access ACL of path and, for directories, its default ACL.
+// An empty Default removes the default ACL. A mask entry is computed when
+// named entries are present without one, as setfacl does.
+func SetACL(path string, acl ACL) error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("POSIX ACLs are only supported on Linux")
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+
+	access, err := encodePosixACL(acl.Access)
+	if err != nil {
+		return fmt.Errorf("access ACL: %w", err)
+	}
+	if err := setXattr(path, xattrACLAccess, access); err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return nil
+	}
+	if len(acl.Default) == 0 {
+		if err := removeXattr(path, xattrACLDefault); err != nil && !isNoXattr(err) {
+			return err
+		}
+		return nil
+	}
+	defaults, err := encodePosixACL(acl.Default)
+	if err != nil {
+		return fmt.Errorf("default ACL: %w", err)
+	}
+	return setXattr(path, xattrACLDefault, defaults)
+}
+
+func encodeXattrValue(name string, value []byte) ExtendedAttribute {
+	printable := utf8.Valid(value)
+	if printable {
+		for _, r := range string(value) {
+			if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+				printable = false
+				break
+			}
+		}
+	}
+	if printable {
+		return ExtendedAttribute{Name: name, Value: string(value)}
+	}
+	return ExtendedAttribute{Name: name, Value: base64.StdEncoding.EncodeToString(value), Encoding: "base64"}
+}
+
+func decodeXattrValue(attr ExtendedAttribute) ([]byte, error) {
+	switch attr.Encoding {
+	case "":
+		return []byte(attr.Value), nil
+	case "base64":
+		return base64.StdEncoding.DecodeString(attr.Value)
+	default:
+		return nil, fmt.Errorf("unknown attribute encoding: %s", attr.Encoding)
+	}
+}
+
+// aclFromMode builds the minimal ACL equivalent to the mode bits
+func aclFromMode(mode os.FileMode) []ACLEntry {
+	perm := uint16(mode.Perm())
+	return []ACLEntry{
+		{Tag: "user", Perms: aclPermString(perm >> 6)},
+		{Tag: "group", Perms: aclPermString(perm >> 3)},
+		{Tag: "other", Perms: aclPermString(perm)},
+	}
+}
+
+func parsePosixACL(data []byte) ([]ACLEntry, error) {
+	if len(data) < 4 || (len(data)-4)%8 != 0 {
+		return nil, fmt.Errorf("malformed ACL attribute")
+	}
+	if version := binary.LittleEndian.Uint32(data); version != aclXattrVersion {
+		return nil, fmt.Errorf("unsupported ACL version %d", version)
+	}
+
+	entries := make([]ACLEntry, 0, (len(data)-4)/8)
+	for off := 4; off < len(data); off += 8 {
+		tag := binary.LittleEndian.Uint16(data[off:])
+		perm := binary.LittleEndian.Uint16(data[off+2:])
+		id := binary.LittleEndian.Uint32(data[off+4:])
+
+		entry := ACLEntry{Perms: aclPermString(perm)}
+		switch tag {
+		case aclTagUserObj:
+			entry.Tag = "user"
+		case aclTagUser:
+			entry.Tag, entry.Qualifier = "user", aclUserName(id)
+		case aclTagGroupObj:
+			entry.Tag = "group"
+		case aclTagGroup:
+			entry.Tag, entry.Qualifier = "group", aclGroupName(id)
+		case aclTagMask:
+			entry.Tag = "mask"
+		case aclTagOther:
+			entry.Tag = "other"
+		default:
+			return nil, fmt.Errorf("unknown ACL tag %#x", tag)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+type rawACLEntry struct {
+	tag  uint16
+	perm uint16
+	id   uint32
+}
+
+// encodePosixACL validates entries and encodes them in the kernel's sorted xattr layout
+func encodePosixACL(entries []ACLEntry) ([]byte, error) {
+	raw := make([]rawACLEntry, 0, len(entries)+1)
+	counts := make(map[uint16]int)
+	seen := make(map[[2]uint32]bool)
+	var groupClass uint16
+	named := false
+
+	for _, e := range entries {
+		perm, err := parseACLPerms(e.Perms)
+		if err != nil {
+			return nil, err
+		}
+		r := rawACLEntry{perm: perm, id: aclUndefinedID}
+		switch {
+		case e.Tag == "user" && e.Qualifier == "":
+			r.tag = aclTagUserObj
+		case e.Tag == "user":
+			id, err := lookupUserID(e.Qualifier)
+			if err != nil {
+				return nil, fmt.Errorf("unknown user %s", e.Qualifier)
+			}
+			r.tag, r.id = aclTagUser, uint32(id)
+		case e.Tag == "group" && e.Qualifier == "":
+			r.tag = aclTagGroupObj
+		case e.Tag == "group":
+			id, err := lookupGroupID(e.Qualifier)
+			if err != nil {
+				return nil, fmt.Errorf("unknown group %s", e.Qualifier)
+			}
+			r.tag, r.id = aclTagGroup, uint32(id)
+		case e.Tag == "mask":
+			r.tag = aclTagMask
+		case e.Tag == "other":
+			r.tag = aclTagOther
+		default:
+			return nil, fmt.Errorf("unknown ACL tag %q", e.Tag)
+		}
+
+		key := [2]uint32{uint32(r.tag), r.id}
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate ACL entry %s:%s", e.Tag, e.Qualifier)
+		}
+		seen[key] = true
+		counts[r.tag]++
+		if r.tag == aclTagUser || r.tag == aclTagGroup {
+			named = true
+		}
+		if r.tag == aclTagUser || r.tag == aclTagGroupObj || r.tag == aclTagGroup {
+			groupClass |= perm
+		}
+		raw = append(raw, r)
+	}
+
+	for _, tag := range []uint16{aclTagUserObj, aclTagGroupObj, aclTagOther} {
+		if counts[tag] != 1 {
+			return nil, fmt.Errorf("ACL needs exactly one owner user, owner group and other entry")
+		}
+	}
+	if named && counts[aclTagMask] == 0 {
+		raw = append(raw, rawACLEntry{tag: aclTagMask, perm: groupClass, id: aclUndefinedID})
+	}
+
+	sort.Slice(raw, func(i, j int) bool {
+		if raw[i].tag != raw[j].tag {
+			return raw[i].tag < raw[j].tag
+		}
+		return raw[i].id < raw[j].id
+	})
+
+	data := make([]byte, 4, 4+8*len(raw))
+	binary.LittleEndian.PutUint32(data, aclXattrVersion)
+	for _, r := range raw {
+		data = binary.LittleEndian.AppendUint16(data, r.tag)
+		data = binary.LittleEndian.AppendUint16(data, r.perm)
+		data = binary.LittleEndian.AppendUint32(data, r.id)
+	}
+	return data, nil
+}
+
+func aclPermString(perm uint16) string {
+	b := []byte("---")
+	if perm&4 != 0 {
+		b[0] = 'r'
+	}
+	if perm&2 != 0 {
+		b[1] = 'w'
+	}
+	if perm&1 != 0 {
+		b[2] = 'x'
+	}
+	return string(b)
+}
+
+// parseACLPerms accepts "rwx"-style strings (dashes optional) or an octal digit
+func parseACLPerms(s string) (uint16, error) {
+	if n, err := strconv.ParseUint(s, 8, 8); err == nil && n <= 7 {
+		return uint16(n), nil
+	}
+	var perm uint16
+	for _, c := range strings.ReplaceAll(s, "-", "") {
+		switch c {
+		case 'r':
+			perm |= 4
+		case 'w':
+			perm |= 2
+		case 'x':
+			perm |= 1
+		default:
+			return 0, fmt.Errorf("invalid ACL permissions %q", s)
+		}
+	}
+	return perm, nil
+}
+
+func aclUserName(id uint32) string {
+	if u, err := user.LookupId(strconv.FormatUint(uint64(id), 10)); err == nil {
+		return u.Username
+	}
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+func aclGroupName(id uint32) string {
+	if g, err := user.LookupGroupId(strconv.FormatUint(uint64(id), 10)); err == nil {
+		return g.Name
+	}
+	return strconv.FormatUint(uint64(id), 10)
+}
@@ -0,0 +1,39 @@
+//go:build !linux && !darwin
+
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+var errXattrUnsupported = fmt.Errorf("extended attributes are not supported on %s", runtime.GOOS)
+
+func listXattrs(path string) ([]string, error) {
+	return nil, errXattrUnsupported
+}
+
+func getXattr(path, name string) ([]byte, error) {
+	return nil, errXattrUnsupported
+}
+
+func setXattr(path, name string, value []byte) error {
+	return errXattrUnsupported
+}
+
+func removeXattr(path, name string) error {
+	return errXattrUnsupported
+}
+
+func isNoXattr(err error) bool {
+	return false
+}
+
+func isXattrUnsupported(err error) bool {
+	return errors.Is(err, errXattrUnsupported)
+}
+
+func copyExtendedAttributes(src, dst string, opts CopyOptions) error {
+	return nil
+}
@@ -0,0 +1,160 @@
+package backend
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"testing"
+)
+
+// The names getfacl shows for the qualifiers in aclBlob, or the bare ids
+var (
+	aclNamedUser  = aclUserName(54321)
+	aclNamedGroup = aclGroupName(54322)
+)
+
+// aclBlob is system.posix_acl_access as the kernel stores it for
+// "u::rw-,u:54321:rw-,g::r--,g:54322:--x,m::rwx,o::r--": a version word, then
+// tag, perm and id per entry, little-endian and sorted by tag and id
+const aclBlob = "\x02\x00\x00\x00" +
+	"\x01\x00\x06\x00\xff\xff\xff\xff" +
+	"\x02\x00\x06\x00\x31\xd4\x00\x00" +
+	"\x04\x00\x04\x00\xff\xff\xff\xff" +
+	"\x08\x00\x01\x00\x32\xd4\x00\x00" +
+	"\x10\x00\x07\x00\xff\xff\xff\xff" +
+	"\x20\x00\x04\x00\xff\xff\xff\xff"
+
+func aclBlobEntries() []ACLEntry {
+	return []ACLEntry{
+		{Tag: "user", Perms: "rw-"},
+		{Tag: "user", Qualifier: aclNamedUser, Perms: "rw-"},
+		{Tag: "group", Perms: "r--"},
+		{Tag: "group", Qualifier: aclNamedGroup, Perms: "--x"},
+		{Tag: "mask", Perms: "rwx"},
+		{Tag: "other", Perms: "r--"},
+	}
+}
+
+func TestParsePosixACL(t *testing.T) {
+	entries, err := parsePosixACL([]byte(aclBlob))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(entries, aclBlobEntries()) {
+		t.Errorf("parsed %+v, want %+v", entries, aclBlobEntries())
+	}
+
+	for name, blob := range map[string]string{
+		"empty":         "",
+		"partial entry": aclBlob[:len(aclBlob)-3],
+		"old version":   "\x01\x00\x00\x00" + aclBlob[4:],
+		"unknown tag":   "\x02\x00\x00\x00\x40\x00\x07\x00\xff\xff\xff\xff",
+	} {
+		if entries, err := parsePosixACL([]byte(blob)); err == nil {
+			t.Errorf("%s: parsed %+v, want an error", name, entries)
+		}
+	}
+}
+
+func TestEncodePosixACLRoundTrip(t *testing.T) {
+	// Shuffled entries with numeric qualifiers and octal or short perms still encode sorted
+	in := []ACLEntry{
+		{Tag: "other", Perms: "r"},
+		{Tag: "mask", Perms: "7"},
+		{Tag: "group", Qualifier: "54322", Perms: "x"},
+		{Tag: "user", Perms: "rw-"},
+		{Tag: "group", Perms: "r--"},
+		{Tag: "user", Qualifier: "54321", Perms: "6"},
+	}
+	data, err := encodePosixACL(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, []byte(aclBlob)) {
+		t.Fatalf("encoded % x\nwant    % x", data, aclBlob)
+	}
+
+	again, err := encodePosixACL(aclBlobEntries())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, data) {
+		t.Errorf("re-encoding the parsed entries gave % x", again)
+	}
+}
+
+func TestEncodePosixACLMask(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []ACLEntry
+		mask    string // "" when no mask entry should be present
+	}{
+		{
+			name:    "minimal ACL needs no mask",
+			entries: []ACLEntry{{Tag: "user", Perms: "rwx"}, {Tag: "group", Perms: "r-x"}, {Tag: "other", Perms: "---"}},
+		},
+		{
+			name: "mask is the union of the group class",
+			entries: []ACLEntry{{Tag: "user", Perms: "rwx"}, {Tag: "group", Perms: "r--"},
+				{Tag: "group", Qualifier: "54322", Perms: "--x"}, {Tag: "other", Perms: "rwx"}},
+			mask: "r-x",
+		},
+		{
+			name: "owner and other do not widen the mask",
+			entries: []ACLEntry{{Tag: "user", Perms: "rwx"}, {Tag: "user", Qualifier: "54321", Perms: "-w-"},
+				{Tag: "group", Perms: "---"}, {Tag: "other", Perms: "rwx"}},
+			mask: "-w-",
+		},
+		{
+			name: "an explicit mask is kept",
+			entries: []ACLEntry{{Tag: "user", Perms: "rwx"}, {Tag: "user", Qualifier: "54321", Perms: "rwx"},
+				{Tag: "group", Perms: "rwx"}, {Tag: "mask", Perms: "r--"}, {Tag: "other", Perms: "---"}},
+			mask: "r--",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := encodePosixACL(tt.entries)
+			if err != nil {
+				t.Fatal(err)
+			}
+			entries, err := parsePosixACL(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var mask string
+			for _, e := range entries {
+				if e.Tag == "mask" {
+					mask = e.Perms
+				}
+			}
+			if mask != tt.mask {
+				t.Errorf("mask = %q, want %q", mask, tt.mask)
+			}
+		})
+	}
+}
+
+func TestEncodePosixACLRejectsInvalid(t *testing.T) {
+	base := []ACLEntry{{Tag: "user", Perms: "rw"}, {Tag: "group", Perms: "r"}, {Tag: "other", Perms: "r"}}
+	tests := map[string][]ACLEntry{
+		"missing other":      base[:2],
+		"two owner entries":  append(slices.Clone(base), ACLEntry{Tag: "user", Perms: "r"}),
+		"duplicate named":    append(slices.Clone(base), ACLEntry{Tag: "user", Qualifier: "54321", Perms: "r"}, ACLEntry{Tag: "user", Qualifier: "54321", Perms: "w"}),
+		"bad permissions":    append(slices.Clone(base), ACLEntry{Tag: "mask", Perms: "rwz"}),
+		"octal out of range": {{Tag: "user", Perms: "8"}, {Tag: "group", Perms: "r"}, {Tag: "other", Perms: "r"}},
+		"unknown tag":        append(slices.Clone(base), ACLEntry{Tag: "everyone", Perms: "r"}),
+	}
+	for name, entries := range tests {
+		if data, err := encodePosixACL(entries); err == nil {
+			t.Errorf("%s: encoded % x, want an error", name, data)
+		}
+	}
+}
+
+func TestACLFromMode(t *testing.T) {
+	want := []ACLEntry{{Tag: "user", Perms: "rwx"}, {Tag: "group", Perms: "r-x"}, {Tag: "other", Perms: "r--"}}
+	if got := aclFromMode(os.FileMode(0o754)); !slices.Equal(got, want) {
+		t.Errorf("aclFromMode(0754) = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,97 @@
+//go:build linux || darwin
+
+package backend
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+func listXattrs(path string) ([]string, error) {
+	size, err := unix.Llistxattr(path, nil)
+	if err != nil || size == 0 {
+		return []string{}, err
+	}
+	buf := make([]byte, size)
+	size, err = unix.Llistxattr(path, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, name := range strings.Split(string(buf[:size]), "\x00") {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+func getXattr(path, name string) ([]byte, error) {
+	size, err := unix.Lgetxattr(path, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	size, err = unix.Lgetxattr(path, name, buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:size], nil
+}
+
+func setXattr(path, name string, value []byte) error {
+	return unix.Lsetxattr(path, name, value, 0)
+}
+
+func removeXattr(path, name string) error {
+	return unix.Lremovexattr(path, name)
+}
+
+func isXattrUnsupported(err error) bool {
+	return errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EOPNOTSUPP)
+}
+
+// copyExtendedAttributes copies xattrs and/or ACLs from src to dst as selected
+// by opts. A destination filesystem without xattr support is logged and skipped,
+// as are privileged namespaces the current user may not write.
+func copyExtendedAttributes(src, dst string, opts CopyOptions) error {
+	if !opts.PreserveXattrs && !opts.PreserveACLs {
+		return nil
+	}
+	names, err := listXattrs(src)
+	if err != nil {
+		if isXattrUnsupported(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, name := range names {
+		isACL := strings.HasPrefix(name, xattrACLPrefix)
+		if (isACL && !opts.PreserveACLs) || (!isACL && !opts.PreserveXattrs) {
+			continue
+		}
+		value, err := getXattr(src, name)
+		if err != nil {
+			if isNoXattr(err) {
+				continue
+			}
+			return err
+		}
+		if err := setXattr(dst, name, value); err != nil {
+			switch {
+			case isXattrUnsupported(err):
+				logPrintf("Extended attributes not supported at %s; skipping", dst)
+				return nil
+			case errors.Is(err, unix.EPERM) && !strings.HasPrefix(name, "user."):
+				logPrintf("Skipping attribute %s on %s: %v", name, dst, err)
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package backend
+
+import (
+	"bufio"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// xdgUserDirKeys are the user-dirs.dirs entries offered in quick access, in
+// sidebar order, with the directory xdg-user-dirs uses when a key is missing
+var xdgUserDirKeys = []struct {
+	key      string
+	fallback string
+}{
+	{"XDG_DESKTOP_DIR", "Desktop"},
+	{"XDG_DOCUMENTS_DIR", "Documents"},
+	{"XDG_DOWNLOAD_DIR", "Downloads"},
+	{"XDG_MUSIC_DIR", "Music"},
+	{"XDG_PICTURES_DIR", "Pictures"},
+	{"XDG_VIDEOS_DIR", "Videos"},
+}
+
+// xdgConfigHome returns $XDG_CONFIG_HOME, or ~/.config when it is unset or
+// relative as the base directory spec requires
+func xdgConfigHome(home string) string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(home, ".config")
+}
+
+// xdgUserDirCandidates returns the user directories configured in
+// user-dirs.dirs, named after their (possibly localized) folder
+func xdgUserDirCandidates(configHome, home string) []quickAccessCandidate {
+	dirs := map[string]string{}
+	if f, err := os.Open(filepath.Join(configHome, "user-dirs.dirs")); err == nil {
+		dirs = parseXDGUserDirs(f, home)
+		f.Close()
+	}
+
+	var candidates []quickAccessCandidate
+	for _, entry := range xdgUserDirKeys {
+		dir, ok := dirs[entry.key]
+		if !ok {
+			dir = filepath.Join(home, entry.fallback)
+		}
+		// A directory set to $HOME itself is disabled
+		if filepath.Clean(dir) == filepath.Clean(home) {
+			continue
+		}
+		candidates = append(candidates, quickAccessCandidate{dir, filepath.Base(dir), QuickAccessSourceUserDir})
+	}
+	return candidates
+}
+
+// parseXDGUserDirs reads lines of the form XDG_DOWNLOAD_DIR="$HOME/Downloads".
+// Values are either relative to $HOME or absolute; nothing else is expanded.
+func parseXDGUserDirs(r io.Reader, home string) map[string]string {
+	dirs := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || len(value) < 2 || !strings.HasPrefix(value, `"`) || !strings.HasSuffix(value, `"`) {
+			continue
+		}
+		value = unescapeShellQuoted(value[1 : len(value)-1])
+
+		switch {
+		case value == "$HOME":
+			dirs[key] = home
+		case strings.HasPrefix(value, "$HOME/"):
+			dirs[key] = filepath.Join(home, strings.TrimPrefix(value, "$HOME/"))
+		case filepath.IsAbs(value):
+			dirs[key] = filepath.Clean(value)
+		}
+	}
+	return dirs
+}
+
+// unescapeShellQuoted removes the backslashes xdg-user-dirs-update writes
+// before characters that are special inside double quotes
+func unescapeShellQuoted(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// gtkBookmarkCandidates returns the local folders bookmarked in GTK file
+// choosers and Nautilus, in file order. Remote bookmarks are skipped.
+func gtkBookmarkCandidates(configHome, home string) []quickAccessCandidate {
+	f, err := os.Open(filepath.Join(configHome, "gtk-3.0", "bookmarks"))
+	if err != nil {
+		// GTK 2 kept its bookmarks in the home directory
+		if f, err = os.Open(filepath.Join(home, ".gtk-bookmarks")); err != nil {
+			return nil
+		}
+	}
+	defer f.Close()
+	return parseGTKBookmarks(f)
+}
+
+// parseGTKBookmarks reads lines of the form "file:///path/to/dir Optional label"
+func parseGTKBookmarks(r io.Reader) []quickAccessCandidate {
+	var candidates []quickAccessCandidate
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		uri, label, _ := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		u, err := url.Parse(uri)
+		if err != nil || u.Scheme != "file" || u.Path == "" {
+			continue
+		}
+		path := filepath.Clean(u.Path)
+		if label = strings.TrimSpace(label); label == "" {
+			label = filepath.Base(path)
+		}
+		candidates = append(candidates, quickAccessCandidate{path, label, QuickAccessSourceBookmark})
+	}
+	return candidates
+}
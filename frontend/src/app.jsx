@@ -328,6 +328,25 @@ export function App() {
         return out;
     }, [allFiles]);
 
+    // A file to select once the folder it was opened in finishes loading
+    const pendingSelectRef = useRef(null);
+
+    const navigateAndSelect = useCallback(async (request, source) => {
+        pendingSelectRef.current = request.select || null;
+        await navigateToPath(request.path, source);
+    }, [navigateToPath]);
+
+    useEffect(() => {
+        const target = pendingSelectRef.current;
+        if (!target || loading) return;
+        pendingSelectRef.current = null;
+        const index = allFiles.findIndex(f => f.path === target);
+        if (index !== -1) {
+            originalHandleFileSelect(index, false, false);
+            scrollToItem(index);
+        }
+    }, [allFiles, loading, originalHandleFileSelect, scrollToItem]);
+
     // Context menus hook
     const {
         contextMenu,
@@ -471,23 +490,22 @@ export function App() {
             return;
         }
 
-        const newPinnedFolders = [...new Set([...pinnedFolders, ...foldersToPin])];
-        log('🔗 New pinned folders list:', newPinnedFolders);
+        const newFolders = [...new Set(foldersToPin)].filter(p => !pinnedFolders.includes(p));
+        log('🔗 New folders to pin:', newFolders);
 
-        setPinnedFolders(newPinnedFolders);
-        const newSettings = { ...appSettings, pinnedFolders: newPinnedFolders };
-        setAppSettings(newSettings);
-        
+        // The backend owns the pin list and broadcasts it through pinsUpdated
         try {
-            const { SaveSettings } = await import('../wailsjs/go/backend/App');
-            await SaveSettings(newSettings);
-            log('🔗 Settings saved successfully');
+            const { PinFolder } = await import('../wailsjs/go/backend/App');
+            for (const path of newFolders) {
+                await PinFolder(path, {});
+            }
+            log('🔗 Folders pinned successfully');
             showErrorNotification(`Pinned ${foldersToPin.length} folder${foldersToPin.length > 1 ? 's' : ''} to Quick Access`, null, true);
         } catch (error) {
-            log('🔗 Error saving settings:', error);
-            showErrorNotification('Failed to save pinned folders', error.message);
+            log('🔗 Error pinning folders:', error);
+            showErrorNotification('Failed to pin folders', error?.message || String(error));
         }
-    }, [dragState.isDragging, dragState.draggedFiles, pinnedFolders, appSettings, showErrorNotification]);
+    }, [dragState.isDragging, dragState.draggedFiles, pinnedFolders, showErrorNotification]);
 
     const handlePinnedItemContextMenu = useCallback((e, path) => {
         e.preventDefault();
@@ -501,14 +519,32 @@ export function App() {
 
     const handleUnpinFolder = useCallback(async () => {
         const path = pinnedItemContextMenu.path;
-        const newPinnedFolders = pinnedFolders.filter(p => p !== path);
         closePinnedItemContextMenu();
-        setPinnedFolders(newPinnedFolders);
-        const newSettings = { ...appSettings, pinnedFolders: newPinnedFolders };
-        setAppSettings(newSettings);
-        const { SaveSettings } = await import('../wailsjs/go/backend/App');
-        await SaveSettings(newSettings);
-    }, [pinnedFolders, appSettings, closePinnedItemContextMenu, pinnedItemContextMenu.path]);
+        try {
+            const { UnpinFolder } = await import('../wailsjs/go/backend/App');
+            await UnpinFolder(path);
+        } catch (error) {
+            showErrorNotification('Failed to unpin folder', error?.message || String(error));
+        }
+    }, [closePinnedItemContextMenu, pinnedItemContextMenu.path, showErrorNotification]);
+
+    // Moves the pinned folder under the context menu up (-1) or down (+1)
+    const handleMovePinnedFolder = useCallback(async (delta) => {
+        const path = pinnedItemContextMenu.path;
+        closePinnedItemContextMenu();
+        const from = pinnedFolders.indexOf(path);
+        const to = from + delta;
+        if (from < 0 || to < 0 || to >= pinnedFolders.length) return;
+        const ordered = [...pinnedFolders];
+        ordered.splice(from, 1);
+        ordered.splice(to, 0, path);
+        try {
+            const { ReorderPins } = await import('../wailsjs/go/backend/App');
+            await ReorderPins(ordered);
+        } catch (error) {
+            showErrorNotification('Failed to reorder pinned folders', error?.message || String(error));
+        }
+    }, [pinnedFolders, closePinnedItemContextMenu, pinnedItemContextMenu.path, showErrorNotification]);
 
     // Modified file selection handler - click to select, click selected to open
     const handleFileSelect = useCallback((fileIndex, isShiftKey, isCtrlKey) => {
@@ -705,6 +741,20 @@ export function App() {
             setDrives(list);
         });
 
+        // Pins change through their own bindings, possibly in another window
+        const offPins = EventsOn("pinsUpdated", (pins) => {
+            const paths = (pins || []).map(p => p.path);
+            setPinnedFolders(paths);
+            setAppSettings(prev => ({ ...prev, pinnedFolders: paths }));
+        });
+
+        // Later launches and the automation API ask this window to open a path
+        const offNavigate = EventsOn("NavigateRequested", (request) => {
+            if (request && request.path) {
+                navigateAndSelect(request, request.source || 'command-line');
+            }
+        });
+
         // Debug: Add global drop listener to see all drop events
         const globalDropListener = (e) => {
             log('🌍 Global drop event detected at:', e.target.className || e.target.tagName);
@@ -719,6 +769,8 @@ export function App() {
 
         return () => {
             if (off) off();
+            if (offPins) offPins();
+            if (offNavigate) offNavigate();
             document.removeEventListener('drop', globalDropListener, true);
             document.removeEventListener('dragend', globalDragEndListener, true);
         };
@@ -746,16 +798,22 @@ export function App() {
             dismissErrorNotification();
 
             // Dynamically import the backend API only when required during startup
-            const { GetHomeDirectory, GetSettings } = await import('../wailsjs/go/backend/App');
+            const { GetHomeDirectory, GetSettings, GetLaunchRequest } = await import('../wailsjs/go/backend/App');
             const homeDir = await GetHomeDirectory();
             const settings = await GetSettings();
+            // A path given on the command line replaces the home directory
+            const launch = await GetLaunchRequest();
 
             // Apply loaded settings
             setAppSettings(settings);
             setPinnedFolders(settings.pinnedFolders || []);
             setShowHiddenFiles(settings.showHiddenFiles || false);
 
-            if (homeDir) {
+            if (launch && launch.path) {
+                await navigateAndSelect(launch, 'command-line');
+                setIsAppInitialized(true);
+                setHomeDirectory(homeDir);
+            } else if (homeDir) {
                 await navigateToPath(homeDir, 'init');
                 setIsAppInitialized(true);
                 setHomeDirectory(homeDir);
@@ -1006,6 +1064,10 @@ export function App() {
                     item={pinnedItemContextMenu.path}
                     onClose={closePinnedItemContextMenu}
                     onUnpin={handleUnpinFolder}
+                    onMoveUp={() => handleMovePinnedFolder(-1)}
+                    onMoveDown={() => handleMovePinnedFolder(1)}
+                    canMoveUp={pinnedFolders.indexOf(pinnedItemContextMenu.path) > 0}
+                    canMoveDown={pinnedFolders.includes(pinnedItemContextMenu.path) && pinnedFolders.indexOf(pinnedItemContextMenu.path) < pinnedFolders.length - 1}
                     onOpen={() => navigateToPath(pinnedItemContextMenu.path, 'pinned-item-open')}
                 />
                 
@@ -13,9 +13,13 @@ const formatDate = (dateString) => {
 
 const formatFileSize = (size) => {
     // Handle edge cases: undefined, null, or negative sizes
-    if (size === undefined || size === null || size < 0) {
+    if (size === undefined || size === null) {
         return "0 B";
     }
+    // Negative sizes mean unknown, e.g. trashed folders too large to measure
+    if (size < 0) {
+        return "—";
+    }
     
     const units = ['B', 'KB', 'MB', 'GB', 'TB'];
     let unitIndex = 0;
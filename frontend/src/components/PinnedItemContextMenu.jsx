@@ -1,9 +1,9 @@
 import { useRef, useEffect, useState } from "preact/hooks";
 import { memo } from "preact/compat";
-import { PushPinSlashIcon, FolderOpenIcon } from '@phosphor-icons/react';
+import { PushPinSlashIcon, FolderOpenIcon, ArrowUpIcon, ArrowDownIcon } from '@phosphor-icons/react';
 
 // Memoized Pinned Item Context Menu Component
-const PinnedItemContextMenu = memo(({ visible, x, y, item, onClose, onUnpin, onOpen }) => {
+const PinnedItemContextMenu = memo(({ visible, x, y, item, onClose, onUnpin, onOpen, onMoveUp, onMoveDown, canMoveUp, canMoveDown }) => {
     const menuRef = useRef(null);
     const [pos, setPos] = useState({ left: x, top: y });
 
@@ -111,6 +111,19 @@ const PinnedItemContextMenu = memo(({ visible, x, y, item, onClose, onUnpin, onO
                 <span className="context-menu-shortcut">Enter</span>
             </div>
             <div className="context-menu-separator-modern"></div>
+            {canMoveUp && (
+                <div className="context-menu-item-modern" onClick={onMoveUp}>
+                    <ArrowUpIcon size={16} weight="bold" className="context-menu-icon" />
+                    <span className="context-menu-text-modern">Move Up</span>
+                </div>
+            )}
+            {canMoveDown && (
+                <div className="context-menu-item-modern" onClick={onMoveDown}>
+                    <ArrowDownIcon size={16} weight="bold" className="context-menu-icon" />
+                    <span className="context-menu-text-modern">Move Down</span>
+                </div>
+            )}
+            {(canMoveUp || canMoveDown) && <div className="context-menu-separator-modern"></div>}
             <div className="context-menu-item-modern warning" onClick={onUnpin}>
                 <PushPinSlashIcon size={16} weight="bold" className="context-menu-icon" />
                 <span className="context-menu-text-modern">Unpin from Quick Access</span>
@@ -2,11 +2,11 @@ package main
 
 import (
 	"embed"
+	"os"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 
 	"lightning_explorer/backend"
 )
@@ -15,9 +15,18 @@ import (
 var assets embed.FS
 
 func main() {
+	// Headless subcommands ("cli ls", "cli cp", ...) run without starting the GUI
+	if backend.IsCLICommand(os.Args[1:]) {
+		os.Exit(backend.RunCLI(os.Args[1:], os.Stdout, os.Stderr))
+	}
+
 	// Create an instance of the app structure
 	app := backend.NewApp()
 
+	// Remember the path/selection passed on the command line
+	workingDir, _ := os.Getwd()
+	app.SetLaunchArgs(os.Args[1:], workingDir)
+
 	// Get settings to determine background startup behavior
 	settings := app.GetSettings()
 
@@ -36,15 +45,22 @@ func main() {
 		},
 	}
 
+	// Windows spawned with --new-window run standalone.
+	standalone := backend.IsStandaloneLaunch()
+	backend.ClearStandaloneLaunch()
+
 	// Apply background startup settings conditionally
 	if settings.BackgroundStartup {
 		appOptions.HideWindowOnClose = true
-		appOptions.SingleInstanceLock = &options.SingleInstanceLock{
-			UniqueId: "lightning-explorer-single-instance",
-			OnSecondInstanceLaunch: func(data options.SecondInstanceData) {
-				// Bring existing window to foreground
-				runtime.WindowShow(app.GetContext())
-			},
+		// The hidden window stays resident, so later launches
+		// (e.g. "lightning-explorer /some/path") are forwarded to it
+		if !standalone {
+			appOptions.SingleInstanceLock = &options.SingleInstanceLock{
+				UniqueId: "lightning-explorer-single-instance",
+				OnSecondInstanceLaunch: func(data options.SecondInstanceData) {
+					app.HandleSecondInstance(data.Args, data.WorkingDirectory)
+				},
+			}
 		}
 	}
 